@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"testDeployment/pkg/ai"
+)
+
+// Golden is the set of cases every prompt version is run against.
+type Golden struct {
+	Cases []Case `json:"cases"`
+}
+
+// Case is one question, with an optional photo, and what a good answer to
+// it must show. Checks whose expectation is unset are not run, except the
+// disclaimer, which every answer needs unless Disclaimer is false.
+type Case struct {
+	Id string `json:"id"`
+	// Language the answer must be in: uz, uz-Cyrl, ru or en.
+	Language string `json:"language"`
+	Prompt   string `json:"prompt"`
+	// Image is a reference photo, relative to the golden file. The image
+	// prompt of the version is sent with it and Prompt is the patient's
+	// note.
+	Image string `json:"image,omitempty"`
+	// Schema asks for a JSON answer, which must then match it.
+	Schema *ai.Schema `json:"schema,omitempty"`
+	// RedFlag says whether the answer must urge prompt care (true) or must
+	// not raise an alarm (false).
+	RedFlag    *bool `json:"red_flag,omitempty"`
+	Disclaimer *bool `json:"disclaimer,omitempty"`
+	MaxTokens  int32 `json:"max_tokens,omitempty"`
+
+	image *ai.Image
+}
+
+// Prompts is a version of the INSTRUCTION and PROMPT settings.
+type Prompts struct {
+	Name        string `json:"name"`
+	Instruction string `json:"instruction"`
+	Prompt      string `json:"prompt"`
+}
+
+func loadGolden(path string) (*Golden, error) {
+	var golden Golden
+	if err := readJSON(path, &golden); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(golden.Cases))
+	for i := range golden.Cases {
+		c := &golden.Cases[i]
+		if c.Id == "" || seen[c.Id] {
+			return nil, fmt.Errorf("case %d: missing or repeated id %q", i+1, c.Id)
+		}
+		seen[c.Id] = true
+		if c.Image == "" {
+			continue
+		}
+		file := filepath.Join(filepath.Dir(path), c.Image)
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("case %s: %w", c.Id, err)
+		}
+		c.image = &ai.Image{Data: data, MIMEType: mime.TypeByExtension(filepath.Ext(file))}
+	}
+	return &golden, nil
+}
+
+// loadPrompts reads a prompt version; without a file it is the one in the
+// INSTRUCTION and PROMPT environment variables, as the server reads them.
+func loadPrompts(path string) (*Prompts, error) {
+	if path == "" {
+		return &Prompts{
+			Name:        "env",
+			Instruction: os.Getenv("INSTRUCTION"),
+			Prompt:      os.Getenv("PROMPT"),
+		}, nil
+	}
+	var prompts Prompts
+	if err := readJSON(path, &prompts); err != nil {
+		return nil, err
+	}
+	if prompts.Name == "" {
+		prompts.Name = filepath.Base(path)
+	}
+	return &prompts, nil
+}
+
+func readJSON(path string, v interface{}) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
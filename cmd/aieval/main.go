@@ -0,0 +1,196 @@
+// Command aieval scores the answers of the dermatology assistant on a golden
+// set of questions and reference photos, so a change of INSTRUCTION or
+// PROMPT can be judged before it ships.
+//
+//	aieval run [-golden file] [-prompts file] [-mode replay|record|live|fake] [-fixtures dir] [-out file]
+//	aieval diff baseline.json candidate.json
+//
+// record calls the provider configured as for the server (AI_PROVIDER,
+// AI_MODEL, AI_API_KEY) and saves every answer under -fixtures; replay
+// answers from those fixtures offline, so CI scores the same answers every
+// time. A prompt version is a JSON file with name, instruction and prompt;
+// without one the INSTRUCTION and PROMPT environment variables are used.
+//
+// Every answer is checked for a disclaimer and, as the case asks, for red
+// flags caught, the answer language and JSON matching the schema. diff
+// prints what changed between the reports of two prompt versions and exits
+// with status 1 when a check that passed in the baseline fails.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	configs "testDeployment/internal/common/config"
+	"testDeployment/internal/domain"
+	"testDeployment/pkg/ai"
+	"time"
+)
+
+const (
+	modeReplay = "replay"
+	modeRecord = "record"
+	modeLive   = "live"
+	modeFake   = "fake"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+	switch os.Args[1] {
+	case "run":
+		if err := run(os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+	case "diff":
+		if len(os.Args) != 4 {
+			usage()
+		}
+		regressions, err := diff(os.Args[2], os.Args[3])
+		if err != nil {
+			log.Fatal(err)
+		}
+		if regressions > 0 {
+			os.Exit(1)
+		}
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: aieval run [flags] | aieval diff baseline.json candidate.json")
+	os.Exit(2)
+}
+
+func run(args []string) error {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	goldenPath := flags.String("golden", "cmd/aieval/golden/golden.json", "golden set")
+	promptsPath := flags.String("prompts", "", "prompt version file; INSTRUCTION and PROMPT when empty")
+	mode := flags.String("mode", modeReplay, "replay, record, live or fake")
+	fixtures := flags.String("fixtures", "cmd/aieval/fixtures", "directory of the recorded answers")
+	out := flags.String("out", "", "file to write the report to, for diff")
+	timeout := flags.Duration("timeout", time.Minute, "limit of every model call")
+	flags.Parse(args)
+
+	golden, err := loadGolden(*goldenPath)
+	if err != nil {
+		return err
+	}
+	prompts, err := loadPrompts(*promptsPath)
+	if err != nil {
+		return err
+	}
+	model, err := provider(*mode, *fixtures)
+	if err != nil {
+		return err
+	}
+
+	report := evaluate(context.Background(), model, golden, prompts, *timeout)
+	report.print(os.Stdout)
+	if *out != "" {
+		return report.save(*out)
+	}
+	return nil
+}
+
+func diff(baselinePath, candidatePath string) (int, error) {
+	baseline, err := loadReport(baselinePath)
+	if err != nil {
+		return 0, err
+	}
+	candidate, err := loadReport(candidatePath)
+	if err != nil {
+		return 0, err
+	}
+	return printDiff(os.Stdout, baseline, candidate), nil
+}
+
+// provider builds the model for mode; live and record use the settings of
+// the server.
+func provider(mode, fixtures string) (ai.Provider, error) {
+	switch mode {
+	case modeReplay:
+		return ai.NewReplay(fixtures), nil
+	case modeFake:
+		return ai.NewFake(ai.Config{}), nil
+	case modeLive, modeRecord:
+	default:
+		return nil, fmt.Errorf("unknown mode %q", mode)
+	}
+	conf := configs.Configuration().Ai
+	apiKey := conf.APIKey
+	if apiKey == "" {
+		apiKey = conf.GeminiKey
+	}
+	model, err := ai.New(ai.Config{
+		Provider:    conf.Provider,
+		Model:       conf.Model,
+		APIKey:      apiKey,
+		BaseURL:     conf.BaseURL,
+		Temperature: 0.7,
+		TopP:        0.95,
+		TopK:        40,
+		MaxTokens:   300,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if mode == modeRecord {
+		return ai.WithFixtures(model, fixtures)
+	}
+	return model, nil
+}
+
+// evaluate runs every case against model one after the other and scores
+// the answers.
+func evaluate(ctx context.Context, model ai.Provider, golden *Golden, prompts *Prompts, timeout time.Duration) *Report {
+	report := &Report{
+		Prompts:  prompts.Name,
+		Provider: model.Name(),
+		Model:    model.Model(),
+		Cases:    make([]CaseResult, 0, len(golden.Cases)),
+	}
+	for _, c := range golden.Cases {
+		callCtx, cancel := context.WithTimeout(ctx, timeout)
+		res, err := model.Generate(callCtx, request(c, prompts))
+		cancel()
+		result := CaseResult{Id: c.Id}
+		if err != nil {
+			// The checks still count, as failed, so reports stay comparable.
+			result.Error = err.Error()
+			for _, check := range score(c, "") {
+				result.Checks = append(result.Checks, Check{Name: check.Name})
+			}
+		} else {
+			result.Answer = res.Text
+			result.Checks = score(c, result.Answer)
+		}
+		report.Cases = append(report.Cases, result)
+	}
+	return report
+}
+
+// request is what the server sends for the case: the instruction with the
+// language instruction, and for a photo the image prompt followed by the
+// patient's note.
+func request(c Case, prompts *Prompts) ai.Request {
+	req := ai.Request{
+		System:    prompts.Instruction,
+		Prompt:    c.Prompt,
+		Schema:    c.Schema,
+		MaxTokens: c.MaxTokens,
+	}
+	if c.Language != "" {
+		req.System = strings.TrimSpace(req.System + "\n\n" + domain.LanguageInstruction(c.Language))
+	}
+	if c.image != nil {
+		req.Images = []ai.Image{*c.image}
+		req.Prompt = strings.TrimSpace(prompts.Prompt + "\n\n" + c.Prompt)
+	}
+	return req
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// Report is the outcome of one run of the golden set, written by run and
+// compared by diff.
+type Report struct {
+	Prompts  string       `json:"prompts"`
+	Provider string       `json:"provider"`
+	Model    string       `json:"model"`
+	Cases    []CaseResult `json:"cases"`
+}
+
+type CaseResult struct {
+	Id     string  `json:"id"`
+	Answer string  `json:"answer"`
+	Error  string  `json:"error,omitempty"`
+	Checks []Check `json:"checks"`
+}
+
+// Passed counts the passed checks; a case that failed to run passes none.
+func (c CaseResult) Passed() int {
+	passed := 0
+	for _, check := range c.Checks {
+		if check.Pass {
+			passed++
+		}
+	}
+	return passed
+}
+
+func (c CaseResult) check(name string) (Check, bool) {
+	for _, check := range c.Checks {
+		if check.Name == name {
+			return check, true
+		}
+	}
+	return Check{}, false
+}
+
+// tally is the passed and run count of every check.
+func (r *Report) tally() (passed, total map[string]int) {
+	passed, total = map[string]int{}, map[string]int{}
+	for _, c := range r.Cases {
+		for _, check := range c.Checks {
+			total[check.Name]++
+			if check.Pass {
+				passed[check.Name]++
+			}
+		}
+	}
+	return passed, total
+}
+
+func (r *Report) save(path string) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+func loadReport(path string) (*Report, error) {
+	var report Report
+	if err := readJSON(path, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// print writes one line per case with its failed checks, then the pass
+// rate of every check.
+func (r *Report) print(w io.Writer) {
+	fmt.Fprintf(w, "prompts %s on %s/%s\n\n", r.Prompts, r.Provider, r.Model)
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, c := range r.Cases {
+		failed := []string{"error: " + c.Error}
+		if c.Error == "" {
+			failed = failed[:0]
+			for _, check := range c.Checks {
+				if !check.Pass {
+					failed = append(failed, check.Name+": "+check.Detail)
+				}
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%d/%d\t%s\n", c.Id, c.Passed(), len(c.Checks), strings.Join(failed, "; "))
+	}
+	tw.Flush()
+
+	fmt.Fprintln(w)
+	passed, total := r.tally()
+	for _, name := range checkNames {
+		if total[name] > 0 {
+			fmt.Fprintf(w, "%-10s %d/%d\n", name, passed[name], total[name])
+		}
+	}
+}
+
+// printDiff compares the candidate b against the baseline a case by case
+// and returns the number of checks that passed in a and fail in b.
+func printDiff(w io.Writer, a, b *Report) int {
+	fmt.Fprintf(w, "baseline  %s on %s/%s\ncandidate %s on %s/%s\n\n", a.Prompts, a.Provider, a.Model, b.Prompts, b.Provider, b.Model)
+
+	baseline := make(map[string]CaseResult, len(a.Cases))
+	for _, c := range a.Cases {
+		baseline[c.Id] = c
+	}
+	regressions, fixes, changed := 0, 0, 0
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, cb := range b.Cases {
+		ca, ok := baseline[cb.Id]
+		if !ok {
+			fmt.Fprintf(tw, "%s\tnew case\t%d/%d\n", cb.Id, cb.Passed(), len(cb.Checks))
+			continue
+		}
+		delete(baseline, cb.Id)
+		if ca.Answer != cb.Answer {
+			changed++
+		}
+		if cb.Error != "" {
+			fmt.Fprintf(tw, "%s\t- error\t%s\n", cb.Id, cb.Error)
+		}
+		// A case that failed to run is reported by its error alone.
+		for _, name := range checkNames {
+			before, inA := ca.check(name)
+			after, inB := cb.check(name)
+			switch {
+			case !inA || !inB || before.Pass == after.Pass:
+			case before.Pass:
+				regressions++
+				if cb.Error == "" {
+					fmt.Fprintf(tw, "%s\t- %s\t%s\n", cb.Id, name, after.Detail)
+				}
+			default:
+				fixes++
+				fmt.Fprintf(tw, "%s\t+ %s\t\n", cb.Id, name)
+			}
+		}
+	}
+	for _, ca := range a.Cases {
+		if _, dropped := baseline[ca.Id]; dropped {
+			fmt.Fprintf(tw, "%s\tdropped\t\n", ca.Id)
+		}
+	}
+	tw.Flush()
+
+	fmt.Fprintf(w, "\n%d fixed, %d regressed, %d of %d answers changed\n\n", fixes, regressions, changed, len(b.Cases))
+	passedA, totalA := a.tally()
+	passedB, totalB := b.tally()
+	for _, name := range checkNames {
+		if totalA[name] > 0 || totalB[name] > 0 {
+			fmt.Fprintf(w, "%-10s %d/%d -> %d/%d\n", name, passedA[name], totalA[name], passedB[name], totalB[name])
+		}
+	}
+	return regressions
+}
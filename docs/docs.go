@@ -24,46 +24,96 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/auth/status": {
+        "/admin/prompts": {
             "get": {
-                "description": "Returns the current user auth status, role, and remaining quotas for guests",
+                "description": "Returns the stored versions, newest first, optionally filtered by name and locale",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "admin"
+                ],
+                "summary": "List prompt versions",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ADMIN_TOKEN",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "system",
+                            "image",
+                            "analysis",
+                            "comparison"
+                        ],
+                        "type": "string",
+                        "description": "Prompt name",
+                        "name": "name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Locale, e.g. en, uz, ru",
+                        "name": "locale",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Check authentication status",
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.PromptTemplate"
+                            }
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     }
                 }
-            }
-        },
-        "/chat/generate": {
+            },
             "post": {
-                "description": "send message to ai",
+                "description": "Stores the body as the next, unpublished version of the name and locale.\nThe body is a Go text/template over domain.PromptData, e.g. \"{{if .Registered}}The patient is {{.Age}}.{{end}}\".",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "message"
+                    "admin"
                 ],
-                "summary": "send message to ai",
-                "operationId": "message",
+                "summary": "Add a prompt version",
                 "parameters": [
                     {
-                        "description": "List of fact questions to be created",
-                        "name": "ai",
+                        "type": "string",
+                        "description": "ADMIN_TOKEN",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Prompt",
+                        "name": "prompt",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.NewMessage"
+                            "$ref": "#/definitions/domain.NewPromptTemplate"
                         }
                     }
                 ],
@@ -71,60 +121,87 @@ const docTemplate = `{
                     "201": {
                         "description": "Created",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.NewMessage"
-                            }
+                            "$ref": "#/definitions/domain.PromptTemplate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     }
                 }
             }
         },
-        "/chat/upload": {
+        "/admin/prompts/rollback": {
             "post": {
-                "description": "This endpoint allows you to upload an image and optionally provide a prompt for AI image generation.",
+                "description": "Re-activates the version that was published before the active one",
                 "consumes": [
-                    "multipart/form-data"
+                    "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "images"
+                    "admin"
                 ],
-                "summary": "Upload an image and generate a response",
+                "summary": "Roll a prompt back",
                 "parameters": [
                     {
-                        "type": "file",
-                        "description": "Image to upload",
-                        "name": "image",
-                        "in": "formData",
+                        "type": "string",
+                        "description": "ADMIN_TOKEN",
+                        "name": "X-Admin-Token",
+                        "in": "header",
                         "required": true
                     },
                     {
-                        "type": "string",
-                        "description": "Prompt for the image generation",
+                        "description": "Name and locale",
                         "name": "prompt",
-                        "in": "formData"
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.PromptRollback"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "response: generated image response",
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PromptTemplate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "error: Invalid form data or no image uploaded",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "500": {
-                        "description": "error: Could not open or read file / AI generation error",
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -133,53 +210,55 @@ const docTemplate = `{
                 }
             }
         },
-        "/dashboard/fillUserInfo": {
+        "/admin/prompts/{id}/publish": {
             "post": {
-                "description": "User Info with the input attributes",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Makes the version the active one of its name and locale; new chat and upload requests use it right away",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "User info",
+                "summary": "Publish a prompt version",
                 "parameters": [
                     {
-                        "description": "User Info",
-                        "name": "user_info",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.UserInfo"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/dto.UserInfo"
-                        }
+                        "type": "string",
+                        "description": "ADMIN_TOKEN",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Prompt version id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
-                }
-            }
-        },
-        "/dashboard/middle/buy_premium": {
-            "get": {
-                "description": "Upgrade the current user to premium",
-                "produces": [
-                    "application/json"
                 ],
-                "tags": [
-                    "users"
-                ],
-                "summary": "Buy premium",
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PromptTemplate"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -188,22 +267,42 @@ const docTemplate = `{
                 }
             }
         },
-        "/dashboard/middle/deleteAccount": {
-            "get": {
-                "description": "Delete the current user account and clear session",
+        "/admin/quiz-drafts": {
+            "post": {
+                "description": "Asks the model for count multiple-choice questions (4 choices, one correct) about the fact's title and content and stores them as a draft.\nThe questions only reach the quiz once the draft is approved.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
+                ],
+                "summary": "Generate quiz questions for a fact",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ADMIN_TOKEN",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Fact and number of questions, 1 to 10",
+                        "name": "draft",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.NewQuizDraft"
+                        }
+                    }
                 ],
-                "summary": "Delete user account",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/domain.QuizDraft"
                         }
                     },
                     "400": {
@@ -212,122 +311,162 @@ const docTemplate = `{
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AIUnavailable"
+                        }
                     }
                 }
-            }
-        },
-        "/dashboard/middle/get-all-messages": {
+            },
             "get": {
-                "description": "Returns all chat messages for the current user",
+                "description": "Returns the drafts, newest first, optionally only those of one status or fact",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "message"
+                    "admin"
+                ],
+                "summary": "List quiz drafts",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ADMIN_TOKEN",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "enum": [
+                            "draft",
+                            "approved",
+                            "rejected"
+                        ],
+                        "description": "Draft status",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only this fact",
+                        "name": "fact_id",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Get all messages",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/domain.Message"
+                                "$ref": "#/definitions/domain.QuizDraft"
                             }
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             }
         },
-        "/dashboard/middle/get-point": {
+        "/admin/quiz-drafts/{id}": {
             "get": {
-                "description": "Get the current user point balance",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "Get user points",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
+                "summary": "Get a quiz draft",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ADMIN_TOKEN",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Draft id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
-                }
-            }
-        },
-        "/dashboard/middle/get_premium": {
-            "get": {
-                "description": "Check if the current user has premium",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "users"
                 ],
-                "summary": "Get premium status",
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.QuizDraft"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
-                    }
-                }
-            }
-        },
-        "/dashboard/middle/logout": {
-            "get": {
-                "description": "Clear session and logout user",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "auth"
-                ],
-                "summary": "Logout user",
-                "responses": {
-                    "200": {
-                        "description": "OK",
+                    },
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
-                    }
-                }
-            }
-        },
-        "/dashboard/middle/showUserInfo": {
-            "get": {
-                "description": "Get User Info",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "users"
-                ],
-                "summary": "User info",
-                "responses": {
-                    "201": {
-                        "description": "Created",
+                    },
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/dto.UserInfo"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     }
                 }
-            }
-        },
-        "/dashboard/middle/update-email": {
-            "post": {
-                "description": "Update user email",
+            },
+            "put": {
+                "description": "Replaces the questions of a pending draft. Every question needs 2 to 6 distinct choices with exactly one correct.",
                 "consumes": [
                     "application/json"
                 ],
@@ -335,88 +474,135 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "User email",
+                "summary": "Edit a quiz draft",
                 "parameters": [
                     {
-                        "description": "User email",
-                        "name": "UserEmail",
+                        "type": "string",
+                        "description": "ADMIN_TOKEN",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Draft id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Questions",
+                        "name": "draft",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.UserEmail"
+                            "$ref": "#/definitions/domain.QuizDraftEdit"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Success response",
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.QuizDraft"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "Error response",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
                         }
                     },
-                    "406": {
-                        "description": "Invalid request payload",
+                    "404": {
+                        "description": "Not Found",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": {
-                                "type": "string"
-                            }
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     }
                 }
             }
         },
-        "/dashboard/middle/updateuserinfo": {
+        "/admin/quiz-drafts/{id}/approve": {
             "post": {
-                "description": "Update user information fields",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Adds the questions and their choices to the fact; they are served by /fact/get-question right away",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "Update user info",
+                "summary": "Approve a quiz draft",
                 "parameters": [
                     {
-                        "description": "Updated user info",
-                        "name": "user_info",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.UserInfo"
-                        }
+                        "type": "string",
+                        "description": "ADMIN_TOKEN",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Draft id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "id",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/domain.QuizDraft"
                         }
                     },
                     "400": {
-                        "description": "internal error",
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
-                    "406": {
-                        "description": "Not Acceptable",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -425,28 +611,61 @@ const docTemplate = `{
                 }
             }
         },
-        "/doc/getalldoctors": {
-            "get": {
-                "description": "Returns all doctors grouped by type",
+        "/admin/quiz-drafts/{id}/reject": {
+            "post": {
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "doctors"
+                    "admin"
+                ],
+                "summary": "Reject a quiz draft",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "ADMIN_TOKEN",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Draft id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "Get all doctors",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/domain.DoctorByType"
-                            }
+                            "$ref": "#/definitions/domain.QuizDraft"
                         }
                     },
-                    "500": {
-                        "description": "Internal Server Error",
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -455,23 +674,41 @@ const docTemplate = `{
                 }
             }
         },
-        "/doc/getonedoctor": {
+        "/admin/usage/daily": {
             "get": {
-                "description": "Returns doctor details filtered by name",
+                "description": "Calls, tokens, latency and estimated cost per day, newest first, optionally for one user or guest",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "doctors"
+                    "admin"
                 ],
-                "summary": "Get doctor by name",
+                "summary": "AI usage per day",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "Doctor name",
-                        "name": "name",
-                        "in": "query",
+                        "description": "ADMIN_TOKEN",
+                        "name": "X-Admin-Token",
+                        "in": "header",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of days, default 30",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only this user",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only this guest",
+                        "name": "guest_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -480,10 +717,24 @@ const docTemplate = `{
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/domain.DoctorWithType"
+                                "$ref": "#/definitions/domain.UsageDay"
                             }
                         }
                     },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -494,40 +745,61 @@ const docTemplate = `{
                 }
             }
         },
-        "/doctor/create": {
-            "post": {
-                "description": "Register a new doctor user",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/usage/guests/{id}": {
+            "get": {
+                "description": "Totals and a breakdown by endpoint and model",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "doctors"
+                    "admin"
                 ],
-                "summary": "Create a doctor",
+                "summary": "AI usage of a guest",
                 "parameters": [
                     {
-                        "description": "Doctor user info",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.User"
-                        }
+                        "type": "string",
+                        "description": "ADMIN_TOKEN",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Guest id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of days, default 30",
+                        "name": "days",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.UsageSummary"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
                     },
-                    "303": {
-                        "description": "See Other",
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -536,151 +808,214 @@ const docTemplate = `{
                 }
             }
         },
-        "/fact/answer-question": {
-            "post": {
-                "description": "Receives a score and updates the user's points if the score is above a certain threshold",
-                "consumes": [
-                    "application/json"
-                ],
+        "/admin/usage/rejections": {
+            "get": {
+                "description": "Users and guests ordered by how many of their requests the moderation stage rejected, with the count per reason",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "fact"
+                    "admin"
                 ],
-                "summary": "Answer a question and update points",
+                "summary": "Most rejected AI callers",
                 "parameters": [
                     {
-                        "description": "Score details",
-                        "name": "score",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.Score"
-                        }
+                        "type": "string",
+                        "description": "ADMIN_TOKEN",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of days, default 30",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of callers, default 10",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Only this user",
+                        "name": "user_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only this guest",
+                        "name": "guest_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK"
-                    }
-                }
-            }
-        },
-        "/fact/create": {
-            "post": {
-                "description": "create fact",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "fact"
-                ],
-                "summary": "create fact",
-                "operationId": "create-fact",
-                "parameters": [
-                    {
-                        "description": "Fact",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.Fact"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.RejectionConsumer"
+                            }
                         }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
+                    },
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/dto.Fact"
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     }
                 }
             }
         },
-        "/fact/createQuestions": {
-            "post": {
-                "description": "Creates a new fact question and returns the created fact questions.",
+        "/admin/usage/top": {
+            "get": {
+                "description": "Users and guests ordered by tokens used",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "fact"
+                    "admin"
                 ],
-                "summary": "Create a fact question",
-                "operationId": "create-fact-question",
+                "summary": "Top AI consumers",
                 "parameters": [
                     {
-                        "description": "List of fact questions to be created",
-                        "name": "fact",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.FactQuestions"
-                            }
-                        }
+                        "type": "string",
+                        "description": "ADMIN_TOKEN",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of days, default 30",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of consumers, default 10",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
                             "type": "array",
                             "items": {
-                                "$ref": "#/definitions/dto.FactQuestions"
+                                "$ref": "#/definitions/domain.UsageConsumer"
                             }
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             }
         },
-        "/fact/get-image/": {
+        "/admin/usage/users/{id}": {
             "get": {
-                "description": "Retrieves an image by its file path",
+                "description": "Totals and a breakdown by endpoint and model",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "image"
+                    "admin"
                 ],
-                "summary": "Get an image",
+                "summary": "AI usage of a user",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "File path",
-                        "name": "filepath",
-                        "in": "query",
+                        "description": "ADMIN_TOKEN",
+                        "name": "X-Admin-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "User id",
+                        "name": "id",
+                        "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of days, default 30",
+                        "name": "days",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "file"
+                            "$ref": "#/definitions/domain.UsageSummary"
                         }
                     },
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "type": "message"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "403": {
+                        "description": "Forbidden",
                         "schema": {
-                            "type": "message"
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     }
                 }
             }
         },
-        "/fact/get-question": {
-            "get": {
-                "description": "Retrieve the ID and offset from the query parameters.",
+        "/auth/refresh": {
+            "post": {
+                "description": "Exchange a refresh token for a new access token and refresh token. Every refresh token works once: presenting a used one signs out every session it was issued from.",
                 "consumes": [
                     "application/json"
                 ],
@@ -688,153 +1023,187 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "fact"
+                    "auth"
                 ],
-                "summary": "Get ID and Offset",
+                "summary": "Refresh tokens",
                 "parameters": [
                     {
-                        "type": "string",
-                        "default": "\"default_id\"",
-                        "description": "ID",
-                        "name": "id",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "default": "\"0\"",
-                        "description": "Offset",
-                        "name": "offset",
-                        "in": "query"
+                        "description": "Refresh token",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.RefreshRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.FactQuestions"
+                            "$ref": "#/definitions/dto.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     }
                 }
             }
         },
-        "/fact/getFact": {
-            "get": {
-                "description": "Get a 5 facts",
+        "/auth/resend-code": {
+            "post": {
+                "description": "Email a new verification code to the current user; the previous code stops working. Limited to one every minute or so.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "fact"
+                    "auth"
                 ],
-                "summary": "Get a fact",
-                "operationId": "get-fact",
+                "summary": "Resend verification code",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/dto.Fact"
-                            }
+                            "type": "object",
+                            "additionalProperties": true
                         }
-                    }
-                }
-            }
-        },
-        "/fact/upload": {
-            "post": {
-                "description": "Uploads an image with an ID",
-                "consumes": [
-                    "multipart/form-data"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "image"
-                ],
-                "summary": "Upload an image",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Image ID",
-                        "name": "id",
-                        "in": "formData",
-                        "required": true
                     },
-                    {
-                        "type": "file",
-                        "description": "Image file",
-                        "name": "image",
-                        "in": "formData",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "image",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "type": "string"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
-                    "400": {
-                        "description": "Bad Request"
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     },
-                    "500": {
-                        "description": "Internal Server Error"
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
                     }
                 }
             }
         },
-        "/guest": {
-            "post": {
-                "description": "Get a temporary guest token with limited AI access (5 text + 3 image per day). No registration needed.",
+        "/auth/status": {
+            "get": {
+                "description": "Returns the current user auth status, role, and remaining quotas for guests",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "auth"
                 ],
-                "summary": "Continue as guest",
+                "summary": "Check authentication status",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.GuestResponse"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     }
                 }
             }
         },
-        "/health": {
-            "get": {
-                "description": "Returns the health status of all services (Postgres, Telegram Bot, Gemini AI)",
+        "/auth/verify-email": {
+            "post": {
+                "description": "Verify the email of the current user with the code mailed to it. A code expires after a while and after a few wrong guesses.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "health"
+                    "auth"
+                ],
+                "summary": "Verify email",
+                "parameters": [
+                    {
+                        "description": "Verification code",
+                        "name": "body",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.VerifyEmailRequest"
+                        }
+                    }
                 ],
-                "summary": "Health check",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/rest.healthResponse"
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
-                    "503": {
-                        "description": "Service Unavailable",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/rest.healthResponse"
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "410": {
+                        "description": "Gone",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     }
                 }
             }
         },
-        "/login": {
+        "/chat/agent": {
             "post": {
-                "description": "Authenticate with username and password. Returns JWT access token.",
+                "description": "The assistant may look up doctors and the drug catalog and, for registered users, book appointments and list them, on the caller's behalf.\nIt calls these tools as often as it needs before answering; \"trace\" lists every call in order with its arguments, result or error and duration.\nDrugs found by the tools are cited in the text as [drug:ID] and listed in \"drugs\". Each message is answered on its own: earlier messages are not stored or replayed, so a booking has to give every detail at once.",
                 "consumes": [
                     "application/json"
                 ],
@@ -842,25 +1211,38 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "auth"
+                    "message"
                 ],
-                "summary": "Login user",
+                "summary": "Ask the assistant to act",
+                "operationId": "agent",
                 "parameters": [
                     {
-                        "description": "Login credentials",
-                        "name": "user",
+                        "description": "Request to the assistant",
+                        "name": "message",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.LoginRequest"
+                            "$ref": "#/definitions/domain.AgentRequest"
                         }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "false to leave the user's profile out of this request",
+                        "name": "personalize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Answer language: uz, uz-Cyrl, ru or en; defaults to the profile, then Accept-Language",
+                        "name": "lang",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/dto.AuthResponse"
+                            "$ref": "#/definitions/domain.AgentReply"
                         }
                     },
                     "400": {
@@ -877,32 +1259,75 @@ const docTemplate = `{
                             "additionalProperties": true
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "422": {
+                        "description": "Rejected by moderation, reason is too_long, prompt_injection or off_topic",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ModerationRejection"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "503": {
+                        "description": "AI model unavailable, retry after the Retry-After header",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AIUnavailable"
+                        }
                     }
                 }
             }
         },
-        "/news/getall": {
-            "get": {
-                "description": "Get medical news from PubMed \u0026 Europe PMC — topics: dermatology, AI in medicine, skincare, digital health, clinical trials",
+        "/chat/compare": {
+            "post": {
+                "description": "Upload 2 up to AI_MAX_IMAGES (default 4) photos of one lesion, e.g. several angles or the same spot weeks apart.\nEach \"labels\" value names the photo at the same position (\"before\", \"after\", \"close-up\"). All photos go to the model in one request.\nA comparison counts as one upload of the guest quota, which callers without a token share per IP; a cached answer has \"cached\": true and is not counted.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "news"
+                    "images"
                 ],
-                "summary": "Get all medical news",
-                "operationId": "get-all-news",
+                "summary": "Compare several photos of the same area",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Page number (default 1)",
-                        "name": "page",
+                        "type": "file",
+                        "description": "Photos, in order; repeat the field for each photo",
+                        "name": "images",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Label per photo, in the same order",
+                        "name": "labels",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Note from the patient",
+                        "name": "prompt",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "false to leave the user's profile out of this request",
+                        "name": "personalize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Answer language: uz, uz-Cyrl, ru or en; defaults to the profile, then Accept-Language",
+                        "name": "lang",
                         "in": "query"
                     }
                 ],
@@ -910,48 +1335,119 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.NewsList"
+                            "$ref": "#/definitions/domain.ImageComparison"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid form data or wrong number of images",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "413": {
+                        "description": "error: Image exceeds IMAGE_MAX_BYTES or IMAGE_MAX_PIXELS",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "415": {
+                        "description": "error: Not a JPEG, PNG, WebP or GIF image",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "Rejected by moderation, reason is too_long, prompt_injection, off_topic or not_skin_image",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ModerationRejection"
+                        }
+                    },
+                    "429": {
+                        "description": "error: Guest upload limit reached",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "error: Could not read file / AI generation error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
                         }
+                    },
+                    "503": {
+                        "description": "AI model unavailable, retry after the Retry-After header",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AIUnavailable"
+                        }
                     }
                 }
             }
         },
-        "/news/getone": {
+        "/chat/conversations": {
             "get": {
-                "description": "Get a single medical article by its ID (e.g. pubmed-12345678 or epmc-12345678)",
+                "description": "Returns the current user's conversations, most recently active first",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "news"
+                    "message"
                 ],
-                "summary": "Get one medical news article",
-                "operationId": "get-one-news",
+                "summary": "List conversations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Conversation"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Start a new, empty AI conversation for the current user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Create a conversation",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "Article ID",
-                        "name": "id",
-                        "in": "query",
-                        "required": true
+                        "description": "Conversation title",
+                        "name": "conversation",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/domain.NewConversation"
+                        }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/domain.NewWithSinglePhoto"
+                            "$ref": "#/definitions/domain.Conversation"
                         }
                     },
-                    "404": {
-                        "description": "Not Found",
+                    "500": {
+                        "description": "Internal Server Error",
                         "schema": {
                             "type": "object",
                             "additionalProperties": true
@@ -960,181 +1456,2735 @@ const docTemplate = `{
                 }
             }
         },
-        "/signup": {
-            "post": {
-                "description": "Create a new account with email, username and password (min 6 chars). Returns JWT access token.",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "auth"
-                ],
-                "summary": "Register a new user",
-                "parameters": [
-                    {
-                        "description": "Signup credentials",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.SignupRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "201": {
-                        "description": "Created",
-                        "schema": {
-                            "$ref": "#/definitions/dto.AuthResponse"
-                        }
-                    },
-                    "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
-                    },
-                    "409": {
-                        "description": "Conflict",
-                        "schema": {
-                            "type": "object",
-                            "additionalProperties": true
-                        }
+        "/chat/conversations/{id}": {
+            "get": {
+                "description": "Returns one conversation with all of its messages",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Get a conversation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Conversation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Conversation"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes one of the current user's conversations",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Delete a conversation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Conversation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/chat/conversations/{id}/messages": {
+            "post": {
+                "description": "Sends one turn to the AI; the stored conversation history is replayed to the model. Supports Server-Sent Events like /chat/generate.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Send a message in a conversation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Conversation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Message",
+                        "name": "message",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.NewMessage"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Stream the answer as Server-Sent Events",
+                        "name": "stream",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "false to leave the user's profile out of this request",
+                        "name": "personalize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Answer language: uz, uz-Cyrl, ru or en; defaults to the profile, then Accept-Language",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ChatReply"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "Rejected by moderation, reason is too_long, prompt_injection or off_topic",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ModerationRejection"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AIUnavailable"
+                        }
+                    }
+                }
+            }
+        },
+        "/chat/conversations/{id}/messages/{messageId}": {
+            "delete": {
+                "description": "Deletes one message of a conversation together with the other half of its turn, so a request goes with its answer and an answer with its request. When the conversation summary covers them, the summary is rebuilt without them.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Delete a message",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Conversation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Message ID",
+                        "name": "messageId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/chat/conversations/{id}/summary": {
+            "get": {
+                "description": "Long conversations have their older messages condensed into a summary, which is sent to the model with the recent messages instead of the full history",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Summary of a conversation",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Conversation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ConversationSummary"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/chat/generate": {
+            "post": {
+                "description": "send message to ai. Registered users may pass conversation_id to continue a conversation; without it a new conversation is started and its id returned.\nWith \"Accept: text/event-stream\" (or ?stream=true) the answer is streamed as Server-Sent Events: \"chunk\" events carry partial text, a final \"done\" event carries the full reply with finish reason and token usage.\nWhen the message or the answer shows red flags, the reply ends with an urgent disclaimer and carries an urgent_referral with dermatologists.\nProducts are only recommended from our drug catalog, cited in the text as [drug:ID] and listed in \"drugs\"; GET /drugs/{id} has the details.",
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "send message to ai",
+                "operationId": "message",
+                "parameters": [
+                    {
+                        "description": "Message and optional conversation id",
+                        "name": "ai",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.NewMessage"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Stream the answer as Server-Sent Events",
+                        "name": "stream",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "false to leave the user's profile out of this request",
+                        "name": "personalize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Answer language: uz, uz-Cyrl, ru or en; defaults to the profile, then Accept-Language",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ChatReply"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "Rejected by moderation, reason is too_long, prompt_injection or off_topic",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ModerationRejection"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "503": {
+                        "description": "AI model unavailable, retry after the Retry-After header",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AIUnavailable"
+                        }
+                    }
+                }
+            }
+        },
+        "/chat/upload": {
+            "post": {
+                "description": "This endpoint allows you to upload an image and optionally provide a prompt for AI image generation.\nWith \"Accept: text/event-stream\" (or ?stream=true) the answer is streamed as Server-Sent Events, see /chat/generate.\nWith mode=analysis the answer is a structured domain.SkinAnalysis instead of free text (not streamed).\nAnswers are cached by photo, prompt version and model; a cached answer has \"cached\": true and does not count against the guest upload quota. Callers without a token share the guest quota per IP.\nProducts are only recommended from our drug catalog, cited as [drug:ID] and listed in \"drugs\".\nEvery answer carries a disclaimer; when red flags (ABCDE melanoma signs, rapid growth, bleeding, infection) are found it also carries an urgent_referral with dermatologists.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "images"
+                ],
+                "summary": "Upload an image and generate a response",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Image to upload",
+                        "name": "image",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Prompt for the image generation",
+                        "name": "prompt",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Stream the answer as Server-Sent Events",
+                        "name": "stream",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "false to leave the user's profile out of this request",
+                        "name": "personalize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Answer language: uz, uz-Cyrl, ru or en; defaults to the profile, then Accept-Language",
+                        "name": "lang",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "analysis"
+                        ],
+                        "type": "string",
+                        "description": "analysis for a structured result",
+                        "name": "mode",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "no-cache to skip the analysis cache",
+                        "name": "Cache-Control",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "with mode=analysis",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SkinAnalysis"
+                        }
+                    },
+                    "400": {
+                        "description": "error: Invalid form data or no image uploaded",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "413": {
+                        "description": "error: Image exceeds IMAGE_MAX_BYTES or IMAGE_MAX_PIXELS",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "415": {
+                        "description": "error: Not a JPEG, PNG, WebP or GIF image",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "Rejected by moderation, reason is too_long, prompt_injection, off_topic or not_skin_image",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ModerationRejection"
+                        }
+                    },
+                    "500": {
+                        "description": "error: Could not open or read file / AI generation error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "503": {
+                        "description": "AI model unavailable, retry after the Retry-After header",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AIUnavailable"
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/fillUserInfo": {
+            "post": {
+                "description": "User Info with the input attributes",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "User info",
+                "parameters": [
+                    {
+                        "description": "User Info",
+                        "name": "user_info",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserInfo"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserInfo"
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/middle/buy_premium": {
+            "get": {
+                "description": "Upgrade the current user to premium. Requires a verified email.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Buy premium",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/middle/deleteAccount": {
+            "get": {
+                "description": "Delete the current user account, revoke all of its tokens and clear session",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete user account",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/middle/get-all-messages": {
+            "get": {
+                "description": "Returns all chat messages for the current user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "message"
+                ],
+                "summary": "Get all messages",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Message"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/middle/get-point": {
+            "get": {
+                "description": "Get the current user point balance",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user points",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/middle/get_premium": {
+            "get": {
+                "description": "Check if the current user has premium",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get premium status",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/middle/logout": {
+            "get": {
+                "description": "Log out this device: clear the session and revoke the access token and its refresh token",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Logout user",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/middle/logout-all": {
+            "get": {
+                "description": "Log out every device: revoke all access tokens, refresh tokens and sessions of the user",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Logout user everywhere",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/middle/showUserInfo": {
+            "get": {
+                "description": "Get User Info",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "User info",
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserInfo"
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/middle/update-email": {
+            "post": {
+                "description": "Update user email. A new address has to be verified again; a code is emailed to it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "User email",
+                "parameters": [
+                    {
+                        "description": "User email",
+                        "name": "UserEmail",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserEmail"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "400": {
+                        "description": "Error response",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "406": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/dashboard/middle/updateuserinfo": {
+            "post": {
+                "description": "Update user information fields",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update user info",
+                "parameters": [
+                    {
+                        "description": "Updated user info",
+                        "name": "user_info",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserInfo"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "internal error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "406": {
+                        "description": "Not Acceptable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/doc/getalldoctors": {
+            "get": {
+                "description": "Returns all doctors grouped by type",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doctors"
+                ],
+                "summary": "Get all doctors",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.DoctorByType"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/doc/getonedoctor": {
+            "get": {
+                "description": "Returns doctor details filtered by name",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doctors"
+                ],
+                "summary": "Get doctor by name",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Doctor name",
+                        "name": "name",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.DoctorWithType"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/doctor/create": {
+            "post": {
+                "description": "Register a new doctor user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "doctors"
+                ],
+                "summary": "Create a doctor",
+                "parameters": [
+                    {
+                        "description": "Doctor user info",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.User"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "303": {
+                        "description": "See Other",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/drugs/{id}": {
+            "get": {
+                "description": "Catalog entry with its types and photos; the AI answers cite drugs as [drug:ID] and list them in \"drugs\"",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "drugs"
+                ],
+                "summary": "Get a drug",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Drug id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Drug"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/fact/answer-question": {
+            "post": {
+                "description": "Receives a score and updates the user's points if the score is above a certain threshold",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fact"
+                ],
+                "summary": "Answer a question and update points",
+                "parameters": [
+                    {
+                        "description": "Score details",
+                        "name": "score",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.Score"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/fact/create": {
+            "post": {
+                "description": "create fact",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fact"
+                ],
+                "summary": "create fact",
+                "operationId": "create-fact",
+                "parameters": [
+                    {
+                        "description": "Fact",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.Fact"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.Fact"
+                        }
+                    }
+                }
+            }
+        },
+        "/fact/createQuestions": {
+            "post": {
+                "description": "Creates a new fact question and returns the created fact questions.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fact"
+                ],
+                "summary": "Create a fact question",
+                "operationId": "create-fact-question",
+                "parameters": [
+                    {
+                        "description": "List of fact questions to be created",
+                        "name": "fact",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.FactQuestions"
+                            }
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.FactQuestions"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/fact/get-image/": {
+            "get": {
+                "description": "Retrieves an image by its file path",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "image"
+                ],
+                "summary": "Get an image",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "File path",
+                        "name": "filepath",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "message"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "message"
+                        }
+                    }
+                }
+            }
+        },
+        "/fact/get-question": {
+            "get": {
+                "description": "Retrieve the ID and offset from the query parameters.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fact"
+                ],
+                "summary": "Get ID and Offset",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "default": "\"default_id\"",
+                        "description": "ID",
+                        "name": "id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "default": "\"0\"",
+                        "description": "Offset",
+                        "name": "offset",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.FactQuestions"
+                        }
+                    }
+                }
+            }
+        },
+        "/fact/getFact": {
+            "get": {
+                "description": "Get a 5 facts",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "fact"
+                ],
+                "summary": "Get a fact",
+                "operationId": "get-fact",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/dto.Fact"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/fact/upload": {
+            "post": {
+                "description": "Uploads an image with an ID",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "image"
+                ],
+                "summary": "Upload an image",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Image ID",
+                        "name": "id",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "Image file",
+                        "name": "image",
+                        "in": "formData",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "image",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request"
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large"
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type"
+                    },
+                    "500": {
+                        "description": "Internal Server Error"
+                    }
+                }
+            }
+        },
+        "/guest": {
+            "post": {
+                "description": "Get a temporary guest token with limited AI access (5 text + 3 image per day). No registration needed.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Continue as guest",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.GuestResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "description": "Returns the health status of all services (Postgres, Telegram Bot, AI provider)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Health check",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/rest.healthResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/rest.healthResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/login": {
+            "post": {
+                "description": "Authenticate with username and password. Returns a short-lived JWT access token and a refresh token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Login user",
+                "parameters": [
+                    {
+                        "description": "Login credentials",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.LoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/news/getall": {
+            "get": {
+                "description": "Get medical news from PubMed \u0026 Europe PMC — topics: dermatology, AI in medicine, skincare, digital health, clinical trials\nThe body is cut at a sentence end. Articles come with a plain-language summary in the reader's language once it has been written in the background; until then summary is missing.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "news"
+                ],
+                "summary": "Get all medical news",
+                "operationId": "get-all-news",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number (default 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Summary language: uz, uz-Cyrl, ru or en; defaults to the profile, then Accept-Language",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.NewsList"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/news/getone": {
+            "get": {
+                "description": "Get a single medical article by its ID (e.g. pubmed-12345678 or epmc-12345678) with its full abstract\nand, once written, its plain-language summary in the reader's language",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "news"
+                ],
+                "summary": "Get one medical news article",
+                "operationId": "get-one-news",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Article ID",
+                        "name": "id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Summary language: uz, uz-Cyrl, ru or en; defaults to the profile, then Accept-Language",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.NewWithSinglePhoto"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/records": {
+            "get": {
+                "description": "Returns every record of one body location, oldest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "records"
+                ],
+                "summary": "Timeline of a location",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Body location name",
+                        "name": "location",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.SkinRecord"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Analyses the photo and stores it with the verdict under a named body location (\"left forearm mole\").\nWhen the location already has records, the latest one is sent to the model as context and the analysis says what changed.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "records"
+                ],
+                "summary": "Save a skin record",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "Photo of the spot",
+                        "name": "image",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Body location name",
+                        "name": "location",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Note from the patient",
+                        "name": "note",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "false to leave the user's profile out of the analysis",
+                        "name": "personalize",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Answer language: uz, uz-Cyrl, ru or en; defaults to the profile, then Accept-Language",
+                        "name": "lang",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SkinRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "413": {
+                        "description": "Request Entity Too Large",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "415": {
+                        "description": "Unsupported Media Type",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "422": {
+                        "description": "Rejected by moderation, reason is too_long, prompt_injection, off_topic or not_skin_image",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ModerationRejection"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AIUnavailable"
+                        }
+                    }
+                }
+            }
+        },
+        "/records/locations": {
+            "get": {
+                "description": "Returns the current user's body locations with their number of records, most recently updated first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "records"
+                ],
+                "summary": "List tracked locations",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.SkinLocation"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/records/{id}": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "records"
+                ],
+                "summary": "Get a skin record",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Record id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SkinRecord"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes the record and its photo",
+                "tags": [
+                    "records"
+                ],
+                "summary": "Delete a skin record",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Record id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/records/{id}/image": {
+            "get": {
+                "description": "Only the owner of the record can fetch it",
+                "produces": [
+                    "image/jpeg",
+                    "image/png"
+                ],
+                "tags": [
+                    "records"
+                ],
+                "summary": "Photo of a skin record",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Record id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/conversations/{id}": {
+            "get": {
+                "description": "A PDF of one of the current user's AI conversations to bring to a doctor: the patient profile, every message with its time, the model and prompt version of each answer, and the medical disclaimer",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Conversation as PDF",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Conversation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/reports/records/{id}": {
+            "get": {
+                "description": "A PDF of one saved analysis to bring to a doctor: the patient profile, the photo, the verdict, the model and prompt versions behind it, and the medical disclaimer",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "reports"
+                ],
+                "summary": "Skin record as PDF",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Record id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/signup": {
+            "post": {
+                "description": "Create a new account with email, username and password (min 6 chars). Returns a short-lived JWT access token and a refresh token, and emails a code to verify the address with.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Register a new user",
+                "parameters": [
+                    {
+                        "description": "Signup credentials",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.SignupRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/dto.AuthResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "409": {
+                        "description": "Conflict",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.AIUnavailable": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "retry_after": {
+                    "description": "RetryAfter is in seconds.",
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.AgentReply": {
+            "type": "object",
+            "properties": {
+                "cached": {
+                    "description": "Cached is set when the answer was served from the analysis cache.",
+                    "type": "boolean"
+                },
+                "conversation_id": {
+                    "type": "integer"
+                },
+                "disclaimer": {
+                    "type": "string"
+                },
+                "drugs": {
+                    "description": "Drugs are the catalog drugs the answer cites as [drug:ID].",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DrugCitation"
+                    }
+                },
+                "finish_reason": {
+                    "type": "string"
+                },
+                "language": {
+                    "description": "Language the answer was asked for in: en, ru, uz or uz-Cyrl.",
+                    "type": "string"
+                },
+                "prompt_template_id": {
+                    "description": "PromptTemplateId is the system prompt version used, 0 for the\nbuilt-in default.",
+                    "type": "integer"
+                },
+                "response": {
+                    "type": "string"
+                },
+                "trace": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.AgentStep"
+                    }
+                },
+                "urgent_referral": {
+                    "$ref": "#/definitions/domain.UrgentReferral"
+                },
+                "usage": {
+                    "$ref": "#/definitions/domain.TokenUsage"
+                }
+            }
+        },
+        "domain.AgentRequest": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.AgentStep": {
+            "type": "object",
+            "properties": {
+                "args": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "result": {
+                    "description": "Result is what the tool returned to the model, Error why it failed."
+                },
+                "tool": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ChatReply": {
+            "type": "object",
+            "properties": {
+                "cached": {
+                    "description": "Cached is set when the answer was served from the analysis cache.",
+                    "type": "boolean"
+                },
+                "conversation_id": {
+                    "type": "integer"
+                },
+                "disclaimer": {
+                    "type": "string"
+                },
+                "drugs": {
+                    "description": "Drugs are the catalog drugs the answer cites as [drug:ID].",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DrugCitation"
+                    }
+                },
+                "finish_reason": {
+                    "type": "string"
+                },
+                "language": {
+                    "description": "Language the answer was asked for in: en, ru, uz or uz-Cyrl.",
+                    "type": "string"
+                },
+                "prompt_template_id": {
+                    "description": "PromptTemplateId is the system prompt version used, 0 for the\nbuilt-in default.",
+                    "type": "integer"
+                },
+                "response": {
+                    "type": "string"
+                },
+                "urgent_referral": {
+                    "$ref": "#/definitions/domain.UrgentReferral"
+                },
+                "usage": {
+                    "$ref": "#/definitions/domain.TokenUsage"
+                }
+            }
+        },
+        "domain.ConditionCandidate": {
+            "type": "object",
+            "properties": {
+                "confidence": {
+                    "type": "number"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Conversation": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "messages": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.Message"
+                    }
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.ConversationSummary": {
+            "type": "object",
+            "properties": {
+                "conversation_id": {
+                    "type": "integer"
+                },
+                "messages": {
+                    "description": "Messages is the number of messages it condenses.",
+                    "type": "integer"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "summary": {
+                    "type": "string"
+                },
+                "up_to_message_id": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DoctorByType": {
+            "type": "object",
+            "properties": {
+                "doctor": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DoctorWithType"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DoctorWithType": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "phone_number": {
+                    "type": "string"
+                },
+                "photo": {
+                    "type": "string"
+                },
+                "rating": {
+                    "type": "number"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "work_hours": {
+                    "type": "string"
+                },
+                "workplace": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Drug": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "manufacturer": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "photo": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "receipt": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.DrugCitation": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.ImageComparison": {
+            "type": "object",
+            "properties": {
+                "cached": {
+                    "type": "boolean"
+                },
+                "changes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "disclaimer": {
+                    "type": "string"
+                },
+                "fallback": {
+                    "type": "boolean"
+                },
+                "images": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ImageObservation"
+                    }
+                },
+                "language": {
+                    "type": "string"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "prompt_template_ids": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "summary": {
+                    "type": "string"
+                },
+                "trend": {
+                    "type": "string"
+                },
+                "urgency": {
+                    "type": "string"
+                },
+                "urgent_referral": {
+                    "$ref": "#/definitions/domain.UrgentReferral"
+                }
+            }
+        },
+        "domain.ImageObservation": {
+            "type": "object",
+            "properties": {
+                "findings": {
+                    "type": "string"
+                },
+                "label": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Message": {
+            "type": "object",
+            "properties": {
+                "conversation_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_AI": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "model": {
+                    "description": "Model is the model that wrote an AI message.",
+                    "type": "string"
+                },
+                "prompt_template_id": {
+                    "description": "PromptTemplateId is the system prompt version that produced an AI\nmessage, 0 for the built-in default.",
+                    "type": "integer"
+                },
+                "sent_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ModerationRejection": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string",
+                    "example": "off_topic"
+                }
+            }
+        },
+        "domain.NewConversation": {
+            "type": "object",
+            "properties": {
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NewMessage": {
+            "type": "object",
+            "properties": {
+                "conversation_id": {
+                    "type": "integer"
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NewPromptTemplate": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "example": "You are a dermatology assistant. {{if .Firstname}}The patient is {{.Firstname}}.{{end}}"
+                },
+                "locale": {
+                    "type": "string",
+                    "example": "en"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "system"
+                }
+            }
+        },
+        "domain.NewQuizDraft": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "Count is the number of questions, 5 when left out.",
+                    "type": "integer",
+                    "example": 5
+                },
+                "fact_id": {
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "domain.NewWithSinglePhoto": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "category": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "owner": {
+                    "type": "string"
+                },
+                "photo": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "summary": {
+                    "description": "Summary is missing until the article has been summarised in the\ncaller's language, which happens in the background.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.NewsSummary"
+                        }
+                    ]
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.NewsList": {
+            "type": "object",
+            "properties": {
+                "has_more": {
+                    "type": "boolean"
+                },
+                "news": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.NewWithSinglePhoto"
+                    }
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "size": {
+                    "type": "integer"
+                },
+                "total_count": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.NewsSummary": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "language": {
+                    "type": "string",
+                    "example": "uz"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "relevance": {
+                    "description": "Relevance is how much the article matters for skin health, from 0\n(not at all) to 10.",
+                    "type": "integer",
+                    "example": 7
+                },
+                "summary": {
+                    "type": "string"
+                },
+                "takeaways": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.PromptRollback": {
+            "type": "object",
+            "properties": {
+                "locale": {
+                    "type": "string",
+                    "example": "en"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "system"
+                }
+            }
+        },
+        "domain.PromptTemplate": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "is_active": {
+                    "type": "boolean"
+                },
+                "locale": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "published_at": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.QuizChoice": {
+            "type": "object",
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "is_true": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.QuizDraft": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "fact_id": {
+                    "type": "integer"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "model": {
+                    "type": "string"
+                },
+                "questions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.QuizQuestion"
+                    }
+                },
+                "reviewed_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "draft"
+                }
+            }
+        },
+        "domain.QuizDraftEdit": {
+            "type": "object",
+            "properties": {
+                "questions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.QuizQuestion"
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "domain.DoctorByType": {
+        },
+        "domain.QuizQuestion": {
             "type": "object",
             "properties": {
-                "doctor": {
+                "choices": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/domain.DoctorWithType"
+                        "$ref": "#/definitions/domain.QuizChoice"
                     }
                 },
-                "id": {
+                "question": {
                     "type": "string"
+                }
+            }
+        },
+        "domain.RejectionConsumer": {
+            "type": "object",
+            "properties": {
+                "by_reason": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
                 },
-                "type": {
+                "guest_id": {
                     "type": "string"
+                },
+                "rejections": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "domain.DoctorWithType": {
+        "domain.SkinAnalysis": {
             "type": "object",
             "properties": {
-                "id": {
-                    "type": "integer"
+                "cached": {
+                    "type": "boolean"
                 },
-                "name": {
+                "change_since_previous": {
+                    "description": "ChangeSincePrevious is only set when a previous record of the same\nlocation was given as context.",
                     "type": "string"
                 },
-                "phone_number": {
+                "conditions": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ConditionCandidate"
+                    }
+                },
+                "disclaimer": {
                     "type": "string"
                 },
-                "photo": {
+                "fallback": {
+                    "type": "boolean"
+                },
+                "language": {
                     "type": "string"
                 },
-                "rating": {
-                    "type": "number"
+                "model": {
+                    "type": "string"
                 },
-                "type": {
+                "prompt_template_ids": {
+                    "description": "PromptTemplateIds are the stored prompt versions that produced it.",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "recommended_specialty": {
                     "type": "string"
                 },
-                "work_hours": {
+                "self_care": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "summary": {
                     "type": "string"
                 },
-                "workplace": {
+                "urgency": {
                     "type": "string"
+                },
+                "urgent_referral": {
+                    "$ref": "#/definitions/domain.UrgentReferral"
+                },
+                "visible_features": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "domain.Message": {
+        "domain.SkinLocation": {
+            "type": "object",
+            "properties": {
+                "last_record_at": {
+                    "type": "string"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "records": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.SkinRecord": {
             "type": "object",
             "properties": {
+                "analysis": {
+                    "$ref": "#/definitions/domain.SkinAnalysis"
+                },
+                "created_at": {
+                    "type": "string"
+                },
                 "id": {
                     "type": "integer"
                 },
-                "is_AI": {
-                    "type": "boolean"
+                "image_url": {
+                    "type": "string"
                 },
-                "message": {
+                "location": {
                     "type": "string"
                 },
-                "sent_at": {
+                "note": {
                     "type": "string"
                 },
                 "user_id": {
-                    "type": "string"
+                    "type": "integer"
                 }
             }
         },
-        "domain.NewMessage": {
+        "domain.TokenUsage": {
             "type": "object",
             "properties": {
-                "message": {
-                    "type": "string"
+                "candidate_tokens": {
+                    "type": "integer"
+                },
+                "prompt_tokens": {
+                    "type": "integer"
+                },
+                "total_tokens": {
+                    "type": "integer"
                 }
             }
         },
-        "domain.NewWithSinglePhoto": {
+        "domain.UrgentReferral": {
             "type": "object",
             "properties": {
-                "body": {
-                    "type": "string"
+                "doctors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.DoctorWithType"
+                    }
                 },
-                "category": {
+                "reason": {
                     "type": "string"
                 },
-                "created_at": {
-                    "type": "string"
+                "red_flags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "domain.UsageBreakdown": {
+            "type": "object",
+            "properties": {
+                "avg_latency_ms": {
+                    "type": "number"
                 },
-                "id": {
-                    "type": "string"
+                "calls": {
+                    "type": "integer"
                 },
-                "owner": {
-                    "type": "string"
+                "candidate_tokens": {
+                    "type": "integer"
                 },
-                "photo": {
+                "cost_usd": {
+                    "type": "number"
+                },
+                "endpoint": {
                     "type": "string"
                 },
-                "source": {
+                "failed": {
+                    "type": "integer"
+                },
+                "model": {
                     "type": "string"
                 },
-                "title": {
+                "prompt_tokens": {
+                    "type": "integer"
+                },
+                "total_tokens": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.UsageConsumer": {
+            "type": "object",
+            "properties": {
+                "avg_latency_ms": {
+                    "type": "number"
+                },
+                "calls": {
+                    "type": "integer"
+                },
+                "candidate_tokens": {
+                    "type": "integer"
+                },
+                "cost_usd": {
+                    "type": "number"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "guest_id": {
                     "type": "string"
+                },
+                "prompt_tokens": {
+                    "type": "integer"
+                },
+                "total_tokens": {
+                    "type": "integer"
+                },
+                "user_id": {
+                    "type": "integer"
                 }
             }
         },
-        "domain.NewsList": {
+        "domain.UsageDay": {
             "type": "object",
             "properties": {
-                "has_more": {
-                    "type": "boolean"
+                "avg_latency_ms": {
+                    "type": "number"
                 },
-                "news": {
+                "calls": {
+                    "type": "integer"
+                },
+                "candidate_tokens": {
+                    "type": "integer"
+                },
+                "cost_usd": {
+                    "type": "number"
+                },
+                "day": {
+                    "type": "string",
+                    "example": "2024-05-01"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "prompt_tokens": {
+                    "type": "integer"
+                },
+                "total_tokens": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.UsageSummary": {
+            "type": "object",
+            "properties": {
+                "avg_latency_ms": {
+                    "type": "number"
+                },
+                "by_endpoint": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/domain.NewWithSinglePhoto"
+                        "$ref": "#/definitions/domain.UsageBreakdown"
                     }
                 },
-                "page": {
+                "calls": {
                     "type": "integer"
                 },
-                "size": {
+                "candidate_tokens": {
                     "type": "integer"
                 },
-                "total_count": {
+                "cost_usd": {
+                    "type": "number"
+                },
+                "failed": {
                     "type": "integer"
                 },
-                "total_pages": {
+                "guest_id": {
+                    "type": "string"
+                },
+                "prompt_tokens": {
+                    "type": "integer"
+                },
+                "total_tokens": {
+                    "type": "integer"
+                },
+                "user_id": {
                     "type": "integer"
                 }
             }
@@ -1149,6 +4199,14 @@ const docTemplate = `{
                     "description": "seconds",
                     "type": "integer"
                 },
+                "refresh_expires_in": {
+                    "description": "seconds",
+                    "type": "integer"
+                },
+                "refresh_token": {
+                    "description": "RefreshToken is spent by /auth/refresh for the next pair of tokens.",
+                    "type": "string"
+                },
                 "role": {
                     "type": "string"
                 },
@@ -1246,6 +4304,17 @@ const docTemplate = `{
                 }
             }
         },
+        "dto.RefreshRequest": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
         "dto.Score": {
             "type": "object",
             "properties": {
@@ -1308,6 +4377,14 @@ const docTemplate = `{
         "dto.UserInfo": {
             "type": "object",
             "properties": {
+                "allergies": {
+                    "type": "string",
+                    "example": "penicillin"
+                },
+                "conditions": {
+                    "type": "string",
+                    "example": "eczema"
+                },
                 "date": {
                     "type": "string",
                     "example": "2005-05-22"
@@ -1323,20 +4400,38 @@ const docTemplate = `{
                 "id": {
                     "type": "integer"
                 },
+                "language": {
+                    "description": "Language of the AI answers: uz (Latin), uz-Cyrl, ru or en; empty\nfollows the app or browser.",
+                    "type": "string",
+                    "example": "uz"
+                },
                 "lastname": {
                     "type": "string",
                     "example": "Tursunov"
                 },
                 "skin_color": {
+                    "description": "SkinColor is the Fitzpatrick phototype 1 to 6, 0 when unknown.",
                     "type": "integer",
                     "example": 0
                 },
                 "skin_type": {
+                    "description": "SkinType is 1 normal, 2 dry, 3 oily, 4 combination, 5 sensitive, 0\nwhen unknown.",
                     "type": "integer",
                     "example": 0
                 }
             }
         },
+        "dto.VerifyEmailRequest": {
+            "type": "object",
+            "required": [
+                "code"
+            ],
+            "properties": {
+                "code": {
+                    "type": "string"
+                }
+            }
+        },
         "rest.healthResponse": {
             "type": "object",
             "properties": {
@@ -1,6 +1,8 @@
 package configs
 
 import (
+	"time"
+
 	"github.com/caarlos0/env/v6"
 	_ "github.com/joho/godotenv/autoload"
 )
@@ -14,6 +16,12 @@ type Config struct {
 	BotConfig
 	Cookie
 	Ai
+	Image
+	Admin
+	Cache
+	Moderation
+	News
+	Mail
 }
 type Postgres struct {
 	Port     string `env:"POSTGRES_PORT"`
@@ -26,7 +34,13 @@ type Postgres struct {
 type JWT struct {
 	SigningKey string `env:"SIGNING"`
 	Salt       string `env:"SALT"`
-	TokenTTL   string `env:"TOKEN_TTL"`
+	// TokenTTL is the lifetime of an access token. RefreshTokenTTL is that
+	// of a refresh token; every refresh issues a new one.
+	TokenTTL        time.Duration `env:"TOKEN_TTL" envDefault:"15m"`
+	RefreshTokenTTL time.Duration `env:"REFRESH_TOKEN_TTL" envDefault:"720h"`
+	// RevocationSync is how often the tokens signed out on other instances
+	// are loaded; on the instance that signs them out they apply at once.
+	RevocationSync time.Duration `env:"TOKEN_REVOCATION_SYNC" envDefault:"30s"`
 }
 type Server struct {
 	Port string
@@ -46,6 +60,97 @@ type Cookie struct {
 type Ai struct {
 	Instruction string `env:"INSTRUCTION"`
 	Prompt      string `env:"PROMPT"`
+	// Provider is gemini, openai (any OpenAI-compatible server) or fake.
+	Provider  string `env:"AI_PROVIDER" envDefault:"gemini"`
+	Model     string `env:"AI_MODEL"`
+	APIKey    string `env:"AI_API_KEY"`
+	BaseURL   string `env:"AI_BASE_URL"`
+	GeminiKey string `env:"GEMINI_API_KEY"`
+	// MaxImages caps the photos accepted by /chat/compare.
+	MaxImages int `env:"AI_MAX_IMAGES" envDefault:"4"`
+	// ReferralSpecialty picks the doctor types offered in urgent
+	// referrals, matched case-insensitively as a substring.
+	ReferralSpecialty string `env:"AI_REFERRAL_SPECIALTY" envDefault:"dermatolog"`
+	// Prices in USD per million tokens, used to estimate the cost in the
+	// usage reports. The defaults are those of gemini-2.5-flash-lite.
+	PricePromptPerMTok    float64 `env:"AI_PRICE_PROMPT_PER_MTOK" envDefault:"0.10"`
+	PriceCandidatePerMTok float64 `env:"AI_PRICE_CANDIDATE_PER_MTOK" envDefault:"0.40"`
+	// FallbackModel answers when Model keeps failing; unset disables the
+	// fallback. FallbackProvider defaults to Provider with the same key.
+	FallbackProvider string `env:"AI_FALLBACK_PROVIDER"`
+	FallbackModel    string `env:"AI_FALLBACK_MODEL"`
+	// Timeout bounds every model call; Retries is the number of attempts
+	// per model on 429, 5xx and timeouts.
+	Timeout time.Duration `env:"AI_TIMEOUT" envDefault:"60s"`
+	Retries int           `env:"AI_RETRIES" envDefault:"3"`
+	// BreakerThreshold consecutive failures stop calls to a model for
+	// BreakerCooldown.
+	BreakerThreshold int           `env:"AI_BREAKER_THRESHOLD" envDefault:"5"`
+	BreakerCooldown  time.Duration `env:"AI_BREAKER_COOLDOWN" envDefault:"30s"`
+	// GroundingDrugs catalog drugs at most are given to the model to
+	// recommend from; 0 turns the grounding off.
+	GroundingDrugs int `env:"AI_GROUNDING_DRUGS" envDefault:"5"`
+	// Older chat history is condensed into a stored summary once
+	// SummaryAfterTurns turns have passed since the last one or a request
+	// costs SummaryAfterTokens prompt tokens; 0 turns a trigger off. The
+	// last SummaryKeepTurns turns are always replayed in full.
+	SummaryAfterTurns  int `env:"AI_SUMMARY_AFTER_TURNS" envDefault:"20"`
+	SummaryAfterTokens int `env:"AI_SUMMARY_AFTER_TOKENS" envDefault:"8000"`
+	SummaryKeepTurns   int `env:"AI_SUMMARY_KEEP_TURNS" envDefault:"6"`
+}
+
+// Image holds the limits of the upload preprocessing (pkg/imaging).
+type Image struct {
+	MaxBytes     int64 `env:"IMAGE_MAX_BYTES" envDefault:"15728640"`
+	MaxPixels    int   `env:"IMAGE_MAX_PIXELS" envDefault:"50000000"`
+	MaxDimension int   `env:"IMAGE_MAX_DIMENSION" envDefault:"1600"`
+	JPEGQuality  int   `env:"IMAGE_JPEG_QUALITY" envDefault:"85"`
+}
+
+// Cache configures the AI image analysis cache. Size 0 disables the
+// in-process tier; Postgres adds the shared analysis_cache table behind it.
+type Cache struct {
+	Size     int           `env:"AI_CACHE_SIZE" envDefault:"512"`
+	TTL      time.Duration `env:"AI_CACHE_TTL" envDefault:"24h"`
+	Postgres bool          `env:"AI_CACHE_POSTGRES" envDefault:"false"`
+}
+
+// Moderation configures the checks run on what reaches the AI endpoints.
+// MaxChars 0 lifts the length limit; Classify and Images turn on the
+// off-topic and non-skin photo checks, which each cost a model call.
+type Moderation struct {
+	MaxChars int  `env:"AI_MODERATION_MAX_CHARS" envDefault:"4000"`
+	Classify bool `env:"AI_MODERATION_CLASSIFY" envDefault:"true"`
+	Images   bool `env:"AI_MODERATION_IMAGES" envDefault:"true"`
+}
+
+// News configures the background summaries of the fetched articles.
+// SummaryWorkers 0 turns them off. At most SummaryQueue articles wait for a
+// worker; the rest are queued again the next time they are fetched.
+type News struct {
+	SummaryWorkers int `env:"AI_NEWS_SUMMARY_WORKERS" envDefault:"2"`
+	SummaryQueue   int `env:"AI_NEWS_SUMMARY_QUEUE" envDefault:"100"`
+}
+
+// Mail configures the email verification codes and the mailer that sends
+// them: smtp, where empty settings fall back to the Curify account, or log
+// for development. A code expires after CodeTTL or CodeAttempts wrong
+// guesses; a new one can be asked for every CodeResend.
+type Mail struct {
+	MailProvider string        `env:"MAIL_PROVIDER" envDefault:"smtp"`
+	SMTPHost     string        `env:"SMTP_HOST"`
+	SMTPPort     int           `env:"SMTP_PORT"`
+	SMTPUsername string        `env:"SMTP_USERNAME"`
+	SMTPPassword string        `env:"SMTP_PASSWORD"`
+	MailFrom     string        `env:"MAIL_FROM"`
+	CodeTTL      time.Duration `env:"EMAIL_CODE_TTL" envDefault:"15m"`
+	CodeAttempts int           `env:"EMAIL_CODE_ATTEMPTS" envDefault:"5"`
+	CodeResend   time.Duration `env:"EMAIL_CODE_RESEND" envDefault:"1m"`
+}
+
+// Admin guards the /admin endpoints; they are disabled while AdminToken is empty.
+type Admin struct {
+	AdminToken string `env:"ADMIN_TOKEN"`
 }
 
 var instance Config
@@ -9,10 +9,18 @@ type UserInfo struct {
 	Id        int
 	Firstname string `json:"firstname" example:"Uyg'un'"`
 	Lastname  string `json:"lastname" example:"Tursunov"`
-	SkinColor int    `json:"skin_color" example:"0"`
-	SkinType  int    `json:"skin_type" example:"0"`
-	Gender    string `json:"gender" example:"male"`
-	Date      string `json:"date" example:"2005-05-22"`
+	// SkinColor is the Fitzpatrick phototype 1 to 6, 0 when unknown.
+	SkinColor int `json:"skin_color" example:"0"`
+	// SkinType is 1 normal, 2 dry, 3 oily, 4 combination, 5 sensitive, 0
+	// when unknown.
+	SkinType   int    `json:"skin_type" example:"0"`
+	Gender     string `json:"gender" example:"male"`
+	Date       string `json:"date" example:"2005-05-22"`
+	Conditions string `json:"conditions" example:"eczema"`
+	Allergies  string `json:"allergies" example:"penicillin"`
+	// Language of the AI answers: uz (Latin), uz-Cyrl, ru or en; empty
+	// follows the app or browser.
+	Language string `json:"language" example:"uz"`
 }
 
 type UserEmail struct {
@@ -27,6 +35,17 @@ type AuthResponse struct {
 	UserID      int    `json:"user_id,omitempty"`
 	Role        string `json:"role"`
 	ExpiresIn   int    `json:"expires_in"` // seconds
+	// RefreshToken is spent by /auth/refresh for the next pair of tokens.
+	RefreshToken     string `json:"refresh_token"`
+	RefreshExpiresIn int    `json:"refresh_expires_in"` // seconds
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type VerifyEmailRequest struct {
+	Code string `json:"code" binding:"required"`
 }
 
 type GuestResponse struct {
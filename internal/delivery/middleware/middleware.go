@@ -1,13 +1,18 @@
 package middleware
 
 import (
+	"crypto/subtle"
 	"net/http"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
+	"testDeployment/internal/domain"
+	"testDeployment/pkg/ai"
 	"testDeployment/pkg/jwt"
 )
 
@@ -43,17 +48,110 @@ func GetRole(c *gin.Context) string {
 	return "anonymous"
 }
 
+// GetCaller returns who is making the request: the user ID for registered
+// users, or the guest ID for guests, the languages they asked for and
+// whether they opted out of personalised answers with ?personalize=false.
+func GetCaller(c *gin.Context) domain.Caller {
+	caller := domain.Caller{
+		UserID:      GetUserID(c),
+		Locale:      GetLocale(c),
+		Languages:   GetAcceptedLanguages(c),
+		SkipProfile: c.Query("personalize") == "false",
+	}
+	if guestID, exists := c.Get("guest_id"); exists {
+		if gid, ok := guestID.(string); ok {
+			caller.GuestID = gid
+		}
+	}
+	return caller
+}
+
+// GetLocale returns the language asked for with the lang query parameter
+// (locale is the older name), normalised to one of domain.Languages; empty
+// when none is set or it is not one we answer in.
+func GetLocale(c *gin.Context) string {
+	lang := c.Query("lang")
+	if lang == "" {
+		lang = c.Query("locale")
+	}
+	return domain.NormalizeLanguage(lang)
+}
+
+// GetAcceptedLanguages returns the languages of Accept-Language we answer
+// in, by descending quality: "ru;q=0.8, uz-Cyrl-UZ" gives uz-Cyrl, ru.
+func GetAcceptedLanguages(c *gin.Context) []string {
+	type accepted struct {
+		lang    string
+		quality float64
+	}
+	var langs []accepted
+	for _, part := range strings.Split(c.GetHeader("Accept-Language"), ",") {
+		fields := strings.Split(part, ";")
+		lang := domain.NormalizeLanguage(fields[0])
+		if lang == "" {
+			continue
+		}
+		quality := 1.0
+		for _, param := range fields[1:] {
+			if q, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if v, err := strconv.ParseFloat(q, 64); err == nil {
+					quality = v
+				}
+			}
+		}
+		if quality > 0 {
+			langs = append(langs, accepted{lang, quality})
+		}
+	}
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].quality > langs[j].quality })
+
+	var out []string
+	seen := make(map[string]bool)
+	for _, l := range langs {
+		if !seen[l.lang] {
+			seen[l.lang] = true
+			out = append(out, l.lang)
+		}
+	}
+	return out
+}
+
+// GetClaims returns the claims the request was authenticated with, nil
+// for anonymous requests. Session logins have no token ID.
+func GetClaims(c *gin.Context) *jwt.Claims {
+	if claims, exists := c.Get("claims"); exists {
+		if cl, ok := claims.(*jwt.Claims); ok {
+			return cl
+		}
+	}
+	return nil
+}
+
 // ══════════════════════════════════════════════
 // Auth extraction (JWT → Session fallback)
 // ══════════════════════════════════════════════
 
+// RevocationList tells whether a token or session was signed out before
+// it expired.
+type RevocationList interface {
+	IsRevoked(claims *jwt.Claims) bool
+}
+
+// Revocations is checked for every token and session; set by the router.
+// While nil nothing is revoked.
+var Revocations RevocationList
+
+func revoked(claims *jwt.Claims) bool {
+	return Revocations != nil && Revocations.IsRevoked(claims)
+}
+
 func extractAuth(c *gin.Context) *jwt.Claims {
 	// 1. Check Authorization: Bearer <token>
 	authHeader := c.GetHeader("Authorization")
 	if strings.HasPrefix(authHeader, "Bearer ") {
 		tokenStr := strings.TrimPrefix(authHeader, "Bearer ")
 		claims, err := jwt.VerifyToken(tokenStr)
-		if err == nil {
+		if err == nil && !revoked(claims) {
 			return claims
 		}
 	}
@@ -62,10 +160,21 @@ func extractAuth(c *gin.Context) *jwt.Claims {
 	session := sessions.Default(c)
 	if userID := session.Get("userId"); userID != nil {
 		if id, ok := userID.(int); ok && id > 0 {
-			return &jwt.Claims{
+			claims := &jwt.Claims{
 				UserID: id,
 				Role:   "user",
 			}
+			// Sessions from before issuedAt was stored count as issued at
+			// the epoch, so signing out everywhere ends them too.
+			if issuedAt, ok := session.Get("issuedAt").(int64); ok {
+				claims.IssuedAt = time.Unix(issuedAt, 0)
+			}
+			if !revoked(claims) {
+				return claims
+			}
+			// Cleared so GetUserID does not fall back to it either.
+			session.Clear()
+			session.Save()
 		}
 	}
 
@@ -85,6 +194,7 @@ func OptionalAuth() gin.HandlerFunc {
 			c.Set("user_id", claims.UserID)
 			c.Set("role", claims.Role)
 			c.Set("guest_id", claims.GuestID)
+			c.Set("claims", claims)
 		} else {
 			c.Set("role", "anonymous")
 		}
@@ -119,6 +229,7 @@ func AuthMiddleware() gin.HandlerFunc {
 		}
 		c.Set("user_id", claims.UserID)
 		c.Set("role", claims.Role)
+		c.Set("claims", claims)
 		c.Next()
 	}
 }
@@ -203,6 +314,16 @@ func (rl *RateLimiter) allowUpload(key string) bool {
 	return true
 }
 
+// refundUpload gives back an upload that turned out to cost nothing.
+func (rl *RateLimiter) refundUpload(key string) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	if u, ok := rl.usage[key]; ok && u.uploadCount > 0 {
+		u.uploadCount--
+	}
+}
+
 func (rl *RateLimiter) Remaining(key string) (ai int, upload int) {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
@@ -239,9 +360,14 @@ func GuestKey(c *gin.Context) string {
 func AIRateLimit() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		role := GetRole(c)
-
-		// Anonymous: must get at least a guest token
-		if role == "anonymous" || role == "" {
+		anonymous := role == "anonymous" || role == ""
+		// A comparison sends several photos, so it counts as an upload.
+		isUpload := strings.Contains(c.Request.URL.Path, "/upload") ||
+			strings.Contains(c.Request.URL.Path, "/compare")
+
+		// Anonymous: must get at least a guest token, except for uploads,
+		// which stay open to them on the guest quota of their IP.
+		if anonymous && !isUpload {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":   "unauthorized",
 				"message": "Please login, signup, or continue as guest to use AI",
@@ -257,11 +383,9 @@ func AIRateLimit() gin.HandlerFunc {
 		}
 
 		// Guest: rate limited
-		if role == "guest" {
+		if role == "guest" || anonymous {
 			key := GuestKey(c)
 
-			isUpload := strings.Contains(c.Request.URL.Path, "/upload")
-
 			if isUpload {
 				if !GuestLimiter.allowUpload(key) {
 					aiLeft, uploadLeft := GuestLimiter.Remaining(key)
@@ -295,6 +419,52 @@ func AIRateLimit() gin.HandlerFunc {
 	}
 }
 
+// ══════════════════════════════════════════════
+// Middleware: AIUsageTag
+// Attributes the model calls made while serving
+// the request to its caller and route, for the
+// ai_usage accounting. Use after the auth middleware.
+// ══════════════════════════════════════════════
+
+func AIUsageTag() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		caller := GetCaller(c)
+		tag := ai.Tag{
+			UserID:   caller.UserID,
+			GuestID:  caller.GuestID,
+			Endpoint: c.FullPath(),
+		}
+		c.Request = c.Request.WithContext(ai.WithTag(c.Request.Context(), tag))
+		c.Next()
+	}
+}
+
+// ══════════════════════════════════════════════
+// Middleware: AdminToken
+// Guards back-office endpoints with the ADMIN_TOKEN
+// sent as X-Admin-Token. Disabled when unset.
+// ══════════════════════════════════════════════
+
+func AdminToken(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		given := c.GetHeader("X-Admin-Token")
+		if token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			c.JSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RefundGuestUpload undoes the AIRateLimit upload count of a guest or
+// anonymous request that was answered from the analysis cache.
+func RefundGuestUpload(c *gin.Context) {
+	if role := GetRole(c); role == "guest" || role == "anonymous" || role == "" {
+		GuestLimiter.refundUpload(GuestKey(c))
+	}
+}
+
 // ══════════════════════════════════════════════
 // Middleware: GuestInfo
 // Returns remaining AI/upload quota for guest users.
@@ -1,45 +1,81 @@
 package rest
 
 import (
-	"io"
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	config "testDeployment/internal/common/config"
 	"testDeployment/internal/delivery/middleware"
 	"testDeployment/internal/domain"
-	"testDeployment/pkg/ai"
+	"testDeployment/internal/usecase"
 
 	"github.com/gin-gonic/gin"
 )
 
 type chat struct {
-	gin    *gin.RouterGroup
-	model  *ai.Dermato
-	config config.Config
+	gin      *gin.RouterGroup
+	uc       usecase.IChatUseCase
+	analysis usecase.IAnalysisUseCase
+	agent    usecase.IAgentUseCase
+	config   config.Config
 }
 
 func NewChat(
 	gin *gin.RouterGroup,
-	model *ai.Dermato,
+	uc usecase.IChatUseCase,
+	analysis usecase.IAnalysisUseCase,
+	agent usecase.IAgentUseCase,
 	config config.Config,
 ) {
 	h := &chat{
-		gin:    gin,
-		model:  model,
-		config: config,
+		gin:      gin,
+		uc:       uc,
+		analysis: analysis,
+		agent:    agent,
+		config:   config,
 	}
 	r := gin.Group("/chat")
+	r.Use(middleware.OptionalAuth(), middleware.AIUsageTag())
 	r.POST("/generate", h.SendMessage)
-	r.POST("/upload", h.Upload)
+	r.POST("/upload", middleware.AIRateLimit(), h.Upload)
+	r.POST("/compare", middleware.AIRateLimit(), h.Compare)
+	r.POST("/agent", middleware.AIRateLimit(), h.Agent)
+
+	conversations := r.Group("/conversations")
+	conversations.Use(middleware.AuthMiddleware())
+	{
+		conversations.POST("", h.CreateConversation)
+		conversations.GET("", h.GetConversations)
+		conversations.GET("/:id", h.GetConversation)
+		conversations.DELETE("/:id", h.DeleteConversation)
+		conversations.GET("/:id/summary", h.GetConversationSummary)
+		conversations.POST("/:id/messages", h.PostConversationMessage)
+		conversations.DELETE("/:id/messages/:messageId", h.DeleteConversationMessage)
+	}
 }
 
 // AiHandler godoc
 // @Summary send message to ai
-// @Description send message to ai
+// @Description send message to ai. Registered users may pass conversation_id to continue a conversation; without it a new conversation is started and its id returned.
+// @Description With "Accept: text/event-stream" (or ?stream=true) the answer is streamed as Server-Sent Events: "chunk" events carry partial text, a final "done" event carries the full reply with finish reason and token usage.
+// @Description When the message or the answer shows red flags, the reply ends with an urgent disclaimer and carries an urgent_referral with dermatologists.
+// @Description Products are only recommended from our drug catalog, cited in the text as [drug:ID] and listed in "drugs"; GET /drugs/{id} has the details.
 // @ID message
 // @tags message
 // @Produce json
-// @Param ai body domain.NewMessage true "List of fact questions to be created"
-// @Success 201 {array} domain.NewMessage
+// @Produce text/event-stream
+// @Param ai body domain.NewMessage true "Message and optional conversation id"
+// @Param stream query bool false "Stream the answer as Server-Sent Events"
+// @Param personalize query bool false "false to leave the user's profile out of this request"
+// @Param lang query string false "Answer language: uz, uz-Cyrl, ru or en; defaults to the profile, then Accept-Language"
+// @Success 200 {object} domain.ChatReply
+// @Failure 404 {object} map[string]interface{}
+// @Failure 422 {object} domain.ModerationRejection "Rejected by moderation, reason is too_long, prompt_injection or off_topic"
+// @Failure 500 {object} map[string]interface{}
+// @Failure 503 {object} domain.AIUnavailable "AI model unavailable, retry after the Retry-After header"
 // @Router /chat/generate  [post]
 func (c *chat) SendMessage(ctx *gin.Context) {
 	var newMessage domain.NewMessage
@@ -47,16 +83,266 @@ func (c *chat) SendMessage(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	res, err := c.model.GenerateResponse(ctx.Request.Context(), newMessage.Request)
+	if wantsEventStream(ctx) {
+		c.streamMessage(ctx, newMessage)
+		return
+	}
+	reply, err := c.uc.SendMessage(ctx.Request.Context(), middleware.GetCaller(ctx), newMessage)
+	if err != nil {
+		conversationError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, reply)
+}
+
+// Agent godoc
+// @Summary Ask the assistant to act
+// @Description The assistant may look up doctors and the drug catalog and, for registered users, book appointments and list them, on the caller's behalf.
+// @Description It calls these tools as often as it needs before answering; "trace" lists every call in order with its arguments, result or error and duration.
+// @Description Drugs found by the tools are cited in the text as [drug:ID] and listed in "drugs". Each message is answered on its own: earlier messages are not stored or replayed, so a booking has to give every detail at once.
+// @ID agent
+// @tags message
+// @Accept json
+// @Produce json
+// @Param message body domain.AgentRequest true "Request to the assistant"
+// @Param personalize query bool false "false to leave the user's profile out of this request"
+// @Param lang query string false "Answer language: uz, uz-Cyrl, ru or en; defaults to the profile, then Accept-Language"
+// @Success 200 {object} domain.AgentReply
+// @Failure 400 {object} map[string]interface{}
+// @Failure 401 {object} map[string]interface{}
+// @Failure 422 {object} domain.ModerationRejection "Rejected by moderation, reason is too_long, prompt_injection or off_topic"
+// @Failure 500 {object} map[string]interface{}
+// @Failure 503 {object} domain.AIUnavailable "AI model unavailable, retry after the Retry-After header"
+// @Router /chat/agent [post]
+func (c *chat) Agent(ctx *gin.Context) {
+	var req domain.AgentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	reply, err := c.agent.Run(ctx.Request.Context(), middleware.GetCaller(ctx), req.Request)
+	if err != nil {
+		if errors.Is(err, domain.ErrEmptyField) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "message is required"})
+			return
+		}
+		if modelUnavailable(ctx, err) || moderationRejected(ctx, err) {
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, reply)
+}
+
+// CreateConversation godoc
+// @Summary      Create a conversation
+// @Description  Start a new, empty AI conversation for the current user
+// @Tags         message
+// @Accept       json
+// @Produce      json
+// @Param        conversation  body  domain.NewConversation  false  "Conversation title"
+// @Success      201  {object}  domain.Conversation
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /chat/conversations [post]
+func (c *chat) CreateConversation(ctx *gin.Context) {
+	var req domain.NewConversation
+	// The body is optional; an empty title gets a default.
+	_ = ctx.ShouldBindJSON(&req)
+
+	conversation, err := c.uc.CreateConversation(ctx.Request.Context(), middleware.GetUserID(ctx), req.Title)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not create conversation"})
+		return
+	}
+	ctx.JSON(http.StatusCreated, conversation)
+}
+
+// GetConversations godoc
+// @Summary      List conversations
+// @Description  Returns the current user's conversations, most recently active first
+// @Tags         message
+// @Produce      json
+// @Success      200  {array}   domain.Conversation
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /chat/conversations [get]
+func (c *chat) GetConversations(ctx *gin.Context) {
+	conversations, err := c.uc.GetConversations(ctx.Request.Context(), middleware.GetUserID(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not retrieve conversations"})
+		return
+	}
+	ctx.JSON(http.StatusOK, conversations)
+}
+
+// GetConversation godoc
+// @Summary      Get a conversation
+// @Description  Returns one conversation with all of its messages
+// @Tags         message
+// @Produce      json
+// @Param        id   path      int  true  "Conversation ID"
+// @Success      200  {object}  domain.Conversation
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /chat/conversations/{id} [get]
+func (c *chat) GetConversation(ctx *gin.Context) {
+	id, ok := conversationID(ctx)
+	if !ok {
+		return
+	}
+	conversation, err := c.uc.GetConversation(ctx.Request.Context(), middleware.GetUserID(ctx), id)
+	if err != nil {
+		conversationError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, conversation)
+}
+
+// DeleteConversation godoc
+// @Summary      Delete a conversation
+// @Description  Deletes one of the current user's conversations
+// @Tags         message
+// @Produce      json
+// @Param        id   path      int  true  "Conversation ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /chat/conversations/{id} [delete]
+func (c *chat) DeleteConversation(ctx *gin.Context) {
+	id, ok := conversationID(ctx)
+	if !ok {
+		return
+	}
+	if err := c.uc.DeleteConversation(ctx.Request.Context(), middleware.GetUserID(ctx), id); err != nil {
+		conversationError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "conversation deleted"})
+}
+
+// PostConversationMessage godoc
+// @Summary      Send a message in a conversation
+// @Description  Sends one turn to the AI; the stored conversation history is replayed to the model. Supports Server-Sent Events like /chat/generate.
+// @Tags         message
+// @Accept       json
+// @Produce      json
+// @Produce      text/event-stream
+// @Param        id           path   int                true   "Conversation ID"
+// @Param        message      body   domain.NewMessage  true   "Message"
+// @Param        stream       query  bool               false  "Stream the answer as Server-Sent Events"
+// @Param        personalize  query  bool               false  "false to leave the user's profile out of this request"
+// @Param        lang         query  string             false  "Answer language: uz, uz-Cyrl, ru or en; defaults to the profile, then Accept-Language"
+// @Success      200  {object}  domain.ChatReply
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      422  {object}  domain.ModerationRejection  "Rejected by moderation, reason is too_long, prompt_injection or off_topic"
+// @Failure      500  {object}  map[string]interface{}
+// @Failure      503  {object}  domain.AIUnavailable
+// @Router       /chat/conversations/{id}/messages [post]
+func (c *chat) PostConversationMessage(ctx *gin.Context) {
+	id, ok := conversationID(ctx)
+	if !ok {
+		return
+	}
+	var newMessage domain.NewMessage
+	if err := ctx.ShouldBindJSON(&newMessage); err != nil || newMessage.Request == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "message is required"})
+		return
+	}
+	newMessage.ConversationId = id
+
+	if wantsEventStream(ctx) {
+		c.streamMessage(ctx, newMessage)
+		return
+	}
+	reply, err := c.uc.SendMessage(ctx.Request.Context(), middleware.GetCaller(ctx), newMessage)
+	if err != nil {
+		conversationError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, reply)
+}
+
+func (c *chat) streamMessage(ctx *gin.Context, message domain.NewMessage) {
+	startEventStream(ctx)
+	reply, err := c.uc.StreamMessage(ctx.Request.Context(), middleware.GetCaller(ctx), message, chunkWriter(ctx))
+	endEventStream(ctx, reply, err)
+}
+
+// analysisContext is the request context, without the analysis cache when
+// the client sent "Cache-Control: no-cache".
+func analysisContext(ctx *gin.Context) context.Context {
+	if strings.Contains(strings.ToLower(ctx.GetHeader("Cache-Control")), "no-cache") {
+		return usecase.WithoutCache(ctx.Request.Context())
+	}
+	return ctx.Request.Context()
+}
+
+// GetConversationSummary godoc
+// @Summary      Summary of a conversation
+// @Description  Long conversations have their older messages condensed into a summary, which is sent to the model with the recent messages instead of the full history
+// @Tags         message
+// @Produce      json
+// @Param        id   path      int  true  "Conversation ID"
+// @Success      200  {object}  domain.ConversationSummary
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /chat/conversations/{id}/summary [get]
+func (c *chat) GetConversationSummary(ctx *gin.Context) {
+	id, ok := conversationID(ctx)
+	if !ok {
+		return
+	}
+	summary, err := c.uc.GetSummary(ctx.Request.Context(), middleware.GetUserID(ctx), id)
 	if err != nil {
-		ctx.JSON(200, gin.H{
-			"error": err.Error(),
-		})
+		conversationError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, summary)
+}
+
+// DeleteConversationMessage godoc
+// @Summary      Delete a message
+// @Description  Deletes one message of a conversation together with the other half of its turn, so a request goes with its answer and an answer with its request. When the conversation summary covers them, the summary is rebuilt without them.
+// @Tags         message
+// @Produce      json
+// @Param        id         path      int  true  "Conversation ID"
+// @Param        messageId  path      int  true  "Message ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /chat/conversations/{id}/messages/{messageId} [delete]
+func (c *chat) DeleteConversationMessage(ctx *gin.Context) {
+	id, ok := conversationID(ctx)
+	if !ok {
 		return
 	}
-	ctx.JSON(http.StatusOK, gin.H{
-		"response": res,
-	})
+	messageId, err := strconv.Atoi(ctx.Param("messageId"))
+	if err != nil || messageId <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid message id"})
+		return
+	}
+	if err := c.uc.DeleteMessage(ctx.Request.Context(), middleware.GetUserID(ctx), id, messageId); err != nil {
+		conversationError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "message deleted"})
+}
+
+func conversationID(ctx *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid conversation id"})
+		return 0, false
+	}
+	return id, true
+}
+
+func conversationError(ctx *gin.Context, err error) {
+	if modelUnavailable(ctx, err) || moderationRejected(ctx, err) {
+		return
+	}
+	if errors.Is(err, domain.ErrConversationNotFound) || errors.Is(err, domain.ErrMessageNotFound) || errors.Is(err, domain.ErrSummaryNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 }
 
 // GetAllMessages godoc
@@ -79,62 +365,158 @@ func (c controller) GetAllMessages(ctx *gin.Context) {
 	}
 	ctx.JSON(http.StatusOK, messages)
 }
+
 // Upload godoc
 // @Summary Upload an image and generate a response
 // @Description This endpoint allows you to upload an image and optionally provide a prompt for AI image generation.
+// @Description With "Accept: text/event-stream" (or ?stream=true) the answer is streamed as Server-Sent Events, see /chat/generate.
+// @Description With mode=analysis the answer is a structured domain.SkinAnalysis instead of free text (not streamed).
+// @Description Answers are cached by photo, prompt version and model; a cached answer has "cached": true and does not count against the guest upload quota. Callers without a token share the guest quota per IP.
+// @Description Products are only recommended from our drug catalog, cited as [drug:ID] and listed in "drugs".
+// @Description Every answer carries a disclaimer; when red flags (ABCDE melanoma signs, rapid growth, bleeding, infection) are found it also carries an urgent_referral with dermatologists.
 // @Tags images
 // @Accept multipart/form-data
 // @Produce json
+// @Produce text/event-stream
 // @Param image formData file true "Image to upload"
 // @Param prompt formData string false "Prompt for the image generation"
-// @Success 200 {object} map[string]interface{} "response: generated image response"
+// @Param stream query bool false "Stream the answer as Server-Sent Events"
+// @Param personalize query bool false "false to leave the user's profile out of this request"
+// @Param lang query string false "Answer language: uz, uz-Cyrl, ru or en; defaults to the profile, then Accept-Language"
+// @Param mode query string false "analysis for a structured result" Enums(analysis)
+// @Param Cache-Control header string false "no-cache to skip the analysis cache"
+// @Success 200 {object} domain.ChatReply
+// @Success 200 {object} domain.SkinAnalysis "with mode=analysis"
 // @Failure 400 {object} map[string]interface{} "error: Invalid form data or no image uploaded"
+// @Failure 413 {object} map[string]interface{} "error: Image exceeds IMAGE_MAX_BYTES or IMAGE_MAX_PIXELS"
+// @Failure 415 {object} map[string]interface{} "error: Not a JPEG, PNG, WebP or GIF image"
+// @Failure 422 {object} domain.ModerationRejection "Rejected by moderation, reason is too_long, prompt_injection, off_topic or not_skin_image"
 // @Failure 500 {object} map[string]interface{} "error: Could not open or read file / AI generation error"
+// @Failure 503 {object} domain.AIUnavailable "AI model unavailable, retry after the Retry-After header"
 // @Router /chat/upload [post]
 func (c *chat) Upload(ctx *gin.Context) {
-	
+
 	form, err := ctx.MultipartForm()
 	if err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid form data"})
 		return
 	}
 
-	
 	files := form.File["image"]
 	if len(files) == 0 {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": "No image uploaded"})
 		return
 	}
 
-	
-	file, err := files[0].Open()
+	// Decoded, downscaled and re-encoded without EXIF/GPS before the model sees it
+	img, ok := readImage(ctx, files[0], c.config.Image)
+	if !ok {
+		return
+	}
+	fileBytes, mimeType := img.Data, img.MIMEType
+
+	if ctx.Query("mode") == "analysis" {
+		analysis, err := c.analysis.Analyze(analysisContext(ctx), middleware.GetCaller(ctx), fileBytes, mimeType, ctx.PostForm("prompt"), nil)
+		if err != nil {
+			if modelUnavailable(ctx, err) || moderationRejected(ctx, err) {
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if analysis.Cached {
+			middleware.RefundGuestUpload(ctx)
+		}
+		ctx.JSON(http.StatusOK, analysis)
+		return
+	}
+
+	if wantsEventStream(ctx) {
+		startEventStream(ctx)
+		reply, err := c.analysis.Describe(analysisContext(ctx), middleware.GetCaller(ctx), fileBytes, mimeType, ctx.PostForm("prompt"), chunkWriter(ctx))
+		if err == nil && reply.Cached {
+			middleware.RefundGuestUpload(ctx)
+		}
+		endEventStream(ctx, reply, err)
+		return
+	}
+
+	reply, err := c.analysis.Describe(analysisContext(ctx), middleware.GetCaller(ctx), fileBytes, mimeType, ctx.PostForm("prompt"), nil)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Could not open file"})
+		if modelUnavailable(ctx, err) || moderationRejected(ctx, err) {
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	defer file.Close()
+	if reply.Cached {
+		middleware.RefundGuestUpload(ctx)
+	}
+	ctx.JSON(http.StatusOK, reply)
+}
 
-	
-	fileBytes, err := io.ReadAll(file)
+// Compare godoc
+// @Summary Compare several photos of the same area
+// @Description Upload 2 up to AI_MAX_IMAGES (default 4) photos of one lesion, e.g. several angles or the same spot weeks apart.
+// @Description Each "labels" value names the photo at the same position ("before", "after", "close-up"). All photos go to the model in one request.
+// @Description A comparison counts as one upload of the guest quota, which callers without a token share per IP; a cached answer has "cached": true and is not counted.
+// @Tags images
+// @Accept multipart/form-data
+// @Produce json
+// @Param images formData file true "Photos, in order; repeat the field for each photo"
+// @Param labels formData []string false "Label per photo, in the same order" collectionFormat(multi)
+// @Param prompt formData string false "Note from the patient"
+// @Param personalize query bool false "false to leave the user's profile out of this request"
+// @Param lang query string false "Answer language: uz, uz-Cyrl, ru or en; defaults to the profile, then Accept-Language"
+// @Success 200 {object} domain.ImageComparison
+// @Failure 400 {object} map[string]interface{} "error: Invalid form data or wrong number of images"
+// @Failure 413 {object} map[string]interface{} "error: Image exceeds IMAGE_MAX_BYTES or IMAGE_MAX_PIXELS"
+// @Failure 415 {object} map[string]interface{} "error: Not a JPEG, PNG, WebP or GIF image"
+// @Failure 422 {object} domain.ModerationRejection "Rejected by moderation, reason is too_long, prompt_injection, off_topic or not_skin_image"
+// @Failure 429 {object} map[string]interface{} "error: Guest upload limit reached"
+// @Failure 500 {object} map[string]interface{} "error: Could not read file / AI generation error"
+// @Failure 503 {object} domain.AIUnavailable "AI model unavailable, retry after the Retry-After header"
+// @Router /chat/compare [post]
+func (c *chat) Compare(ctx *gin.Context) {
+	form, err := ctx.MultipartForm()
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Could not read file"})
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid form data"})
 		return
 	}
 
-	// Get MIME type from multipart header (more reliable than Go's DetectContentType)
-	mimeType := files[0].Header.Get("Content-Type")
+	files := form.File["images"]
+	if len(files) < 2 || len(files) > c.config.Ai.MaxImages {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Upload between 2 and %d images", c.config.Ai.MaxImages)})
+		return
+	}
+	labels := form.Value["labels"]
 
-	prompt := ctx.PostForm("prompt")
-	if prompt == "" {
-		prompt = c.config.Ai.Prompt
+	images := make([]domain.LabeledImage, 0, len(files))
+	for i, fh := range files {
+		img, ok := readImage(ctx, fh, c.config.Image)
+		if !ok {
+			return
+		}
+		image := domain.LabeledImage{
+			Data:     img.Data,
+			MIMEType: img.MIMEType,
+		}
+		if i < len(labels) {
+			image.Label = labels[i]
+		}
+		images = append(images, image)
 	}
 
-	res, err := c.model.GenerateImageResponse(ctx.Request.Context(), fileBytes, mimeType, prompt)
+	comparison, err := c.analysis.Compare(analysisContext(ctx), middleware.GetCaller(ctx), images, ctx.PostForm("prompt"))
 	if err != nil {
+		if modelUnavailable(ctx, err) || moderationRejected(ctx, err) {
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	ctx.JSON(http.StatusOK, gin.H{
-		"response": res,
-	})
-}
\ No newline at end of file
+	if comparison.Cached {
+		middleware.RefundGuestUpload(ctx)
+	}
+	ctx.JSON(http.StatusOK, comparison)
+}
@@ -10,16 +10,20 @@ import (
 )
 
 type controller struct {
-	usecase usecase.Usecase
-	bot     Bot.Bot
-	http    request.CustomJSONRequester
+	usecase      usecase.Usecase
+	auth         usecase.IAuthUseCase
+	verification usecase.IVerificationUseCase
+	bot          Bot.Bot
+	http         request.CustomJSONRequester
 }
 
-func NewController(g *gin.RouterGroup, usecase usecase.Usecase, bot Bot.Bot, request request.CustomJSONRequester) {
+func NewController(g *gin.RouterGroup, usecase usecase.Usecase, auth usecase.IAuthUseCase, verification usecase.IVerificationUseCase, bot Bot.Bot, request request.CustomJSONRequester) {
 	controller := controller{
-		usecase: usecase,
-		bot:     bot,
-		http:    request,
+		usecase:      usecase,
+		auth:         auth,
+		verification: verification,
+		bot:          bot,
+		http:         request,
 	}
 
 	// Apply OptionalAuth to all routes so user info is available everywhere
@@ -33,6 +37,9 @@ func NewController(g *gin.RouterGroup, usecase usecase.Usecase, bot Bot.Bot, req
 	// ── Public auth routes ──
 	r.POST("/signup", controller.SignUp)
 	r.POST("/login", controller.Login)
+	r.POST("/auth/refresh", controller.Refresh)
+	r.POST("/auth/verify-email", middleware.AuthMiddleware(), controller.VerifyEmail)
+	r.POST("/auth/resend-code", middleware.AuthMiddleware(), controller.ResendCode)
 	r.POST("/guest", controller.GuestLogin)
 	r.GET("/auth/status", controller.AuthStatus)
 	r.GET("/auth/guest/remaining", middleware.GuestRemainingHandler())
@@ -54,6 +61,7 @@ func NewController(g *gin.RouterGroup, usecase usecase.Usecase, bot Bot.Bot, req
 			middle.GET("/showUserInfo", controller.ShowUserInfo)
 			middle.GET("/get-point", controller.GetPoint)
 			middle.GET("/logout", controller.Logout)
+			middle.GET("/logout-all", controller.LogoutEverywhere)
 			middle.GET("/deleteAccount", controller.DeleteAccount)
 			middle.POST("/update-email", controller.UpdateEmail)
 		}
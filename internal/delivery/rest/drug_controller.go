@@ -0,0 +1,57 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type drugs struct {
+	uc usecase.IDrugUseCase
+}
+
+func NewDrugController(
+	group *gin.RouterGroup,
+	uc usecase.IDrugUseCase,
+) {
+	h := &drugs{
+		uc: uc,
+	}
+	r := group.Group("/drugs")
+	{
+		r.GET("/:id", h.Get)
+	}
+}
+
+// Get godoc
+// @Summary      Get a drug
+// @Description  Catalog entry with its types and photos; the AI answers cite drugs as [drug:ID] and list them in "drugs"
+// @Tags         drugs
+// @Produce      json
+// @Param        id  path  int  true  "Drug id"
+// @Success      200  {object}  domain.Drug
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /drugs/{id} [get]
+func (h *drugs) Get(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid drug id"})
+		return
+	}
+	drug, err := h.uc.Get(ctx.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrDrugNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not retrieve drug"})
+		return
+	}
+	ctx.JSON(http.StatusOK, drug)
+}
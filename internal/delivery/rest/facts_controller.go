@@ -1,14 +1,15 @@
 package rest
 
 import (
-	"io"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
+	config "testDeployment/internal/common/config"
 	"testDeployment/internal/delivery/dto"
 	"testDeployment/internal/delivery/middleware"
 	"testDeployment/internal/usecase"
+	"testDeployment/pkg/imaging"
 
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
@@ -16,13 +17,15 @@ import (
 
 type facts struct {
 	usecase usecase.IFactUseCase
+	image   config.Image
 }
 
 func NewFactsController(
 	r *gin.RouterGroup,
 	uc usecase.IFactUseCase,
+	image config.Image,
 ) {
-	handler := &facts{uc}
+	handler := &facts{uc, image}
 	router := r.Group("/fact")
 	router.POST("/create", handler.NewFact)
 	router.POST("/createQuestions", handler.CreateQuestions)
@@ -194,6 +197,8 @@ func (c facts) AnswerQuestion(ctx *gin.Context) {
 // @Param image formData file true "Image file"
 // @Success 200 {string} string "image"
 // @Failure 400
+// @Failure 413
+// @Failure 415
 // @Failure 500
 // @Router /fact/upload [post]
 func (f facts) upload(c *gin.Context) {
@@ -205,14 +210,17 @@ func (f facts) upload(c *gin.Context) {
 	}
 
 	// Parse the multipart form to get the image
-	file, header, err := c.Request.FormFile("image")
+	header, err := c.FormFile("image")
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to get image from request"})
 		return
 	}
-	defer file.Close()
+	img, ok := readImage(c, header, f.image)
+	if !ok {
+		return
+	}
 
-	filename := id + filepath.Ext(header.Filename)
+	filename := id + imaging.Extension(img.MIMEType)
 	filePath := filepath.Join("uploads", filename)
 
 	// Ensure the uploads directory exists
@@ -221,18 +229,10 @@ func (f facts) upload(c *gin.Context) {
 	}
 
 	// Write the file to the local filesystem
-	out, err := os.Create(filePath)
-	if err != nil {
+	if err := os.WriteFile(filePath, img.Data, 0o644); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to save file"})
 		return
 	}
-	defer out.Close()
-
-	_, err = io.Copy(out, file)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to write file"})
-		return
-	}
 	ID, err := strconv.Atoi(id)
 	err = f.usecase.UpdateImage(c.Request.Context(), ID, "https://web.binaryhood.uz/api/v1/fact/get-image/?filepath="+filePath)
 	// Return the ID, filename, and URL as JSON
@@ -7,14 +7,13 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/google/generative-ai-go/genai"
-	"google.golang.org/api/option"
+	"testDeployment/pkg/ai"
 )
 
 type HealthController struct {
 	db       *sql.DB
 	botToken string
-	geminiKey string
+	model    ai.Provider
 }
 
 type serviceStatus struct {
@@ -31,11 +30,11 @@ type healthResponse struct {
 
 var startTime = time.Now()
 
-func NewHealthController(group *gin.RouterGroup, db *sql.DB, botToken string, geminiKey string) {
+func NewHealthController(group *gin.RouterGroup, db *sql.DB, botToken string, model ai.Provider) {
 	h := &HealthController{
-		db:        db,
-		botToken:  botToken,
-		geminiKey: geminiKey,
+		db:       db,
+		botToken: botToken,
+		model:    model,
 	}
 
 	group.GET("/health", h.HealthCheck)
@@ -43,7 +42,7 @@ func NewHealthController(group *gin.RouterGroup, db *sql.DB, botToken string, ge
 
 // HealthCheck godoc
 // @Summary      Health check
-// @Description  Returns the health status of all services (Postgres, Telegram Bot, Gemini AI)
+// @Description  Returns the health status of all services (Postgres, Telegram Bot, AI provider)
 // @Tags         health
 // @Produce      json
 // @Success      200  {object}  healthResponse
@@ -65,9 +64,10 @@ func (h *HealthController) HealthCheck(c *gin.Context) {
 		allHealthy = false
 	}
 
-	// Check Gemini AI
-	services["gemini_ai"] = h.checkGeminiAI()
-	if services["gemini_ai"].Status != "up" {
+	// Check the AI provider, reported as e.g. "gemini_ai"
+	aiService := h.model.Name() + "_ai"
+	services[aiService] = h.checkAI()
+	if services[aiService].Status != "up" {
 		allHealthy = false
 	}
 
@@ -129,24 +129,12 @@ func (h *HealthController) checkTelegramBot() serviceStatus {
 	}
 }
 
-func (h *HealthController) checkGeminiAI() serviceStatus {
+func (h *HealthController) checkAI() serviceStatus {
 	start := time.Now()
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	client, err := genai.NewClient(ctx, option.WithAPIKey(h.geminiKey))
-	if err != nil {
-		return serviceStatus{
-			Status:  "down",
-			Latency: time.Since(start).Round(time.Millisecond).String(),
-			Error:   err.Error(),
-		}
-	}
-	defer client.Close()
-
-	model := client.GenerativeModel("gemini-2.5-flash-lite")
-	_, err = model.CountTokens(ctx, genai.Text("health check"))
-	if err != nil {
+	if err := h.model.Ping(ctx); err != nil {
 		return serviceStatus{
 			Status:  "down",
 			Latency: time.Since(start).Round(time.Millisecond).String(),
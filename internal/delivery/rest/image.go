@@ -0,0 +1,56 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	config "testDeployment/internal/common/config"
+	"testDeployment/pkg/imaging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readImage reads an uploaded photo and runs it through the preprocessing
+// pipeline. On failure the error response has already been written.
+func readImage(ctx *gin.Context, fh *multipart.FileHeader, limits config.Image) (*imaging.Image, bool) {
+	if limits.MaxBytes > 0 && fh.Size > limits.MaxBytes {
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Image is larger than %d bytes", limits.MaxBytes)})
+		return nil, false
+	}
+
+	data, err := readFormFile(fh)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Could not read file"})
+		return nil, false
+	}
+
+	img, err := imaging.Process(data, imaging.Options{
+		MaxBytes:     limits.MaxBytes,
+		MaxPixels:    limits.MaxPixels,
+		MaxDimension: limits.MaxDimension,
+		JPEGQuality:  limits.JPEGQuality,
+	})
+	switch {
+	case errors.Is(err, imaging.ErrTooLarge):
+		ctx.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return nil, false
+	case errors.Is(err, imaging.ErrNotImage), errors.Is(err, imaging.ErrUnsupported):
+		ctx.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return nil, false
+	case err != nil:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return img, true
+}
+
+func readFormFile(fh *multipart.FileHeader) ([]byte, error) {
+	file, err := fh.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return io.ReadAll(file)
+}
@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"testDeployment/internal/delivery/middleware"
 	"testDeployment/internal/usecase"
 	"testDeployment/pkg/Bot"
 	"testDeployment/pkg/utils"
@@ -19,6 +20,7 @@ func NewNewsController(g *gin.RouterGroup, bot Bot.Bot, uc usecase.INewsUseCase)
 		bot: bot,
 	}
 	r := g.Group("/news")
+	r.Use(middleware.OptionalAuth(), middleware.AIUsageTag())
 	r.GET("/getall", controller.GetAll)
 	r.GET("/getone", controller.GetOneById)
 }
@@ -26,10 +28,12 @@ func NewNewsController(g *gin.RouterGroup, bot Bot.Bot, uc usecase.INewsUseCase)
 // GetAll godoc
 // @Summary      Get all medical news
 // @Description  Get medical news from PubMed & Europe PMC — topics: dermatology, AI in medicine, skincare, digital health, clinical trials
+// @Description  The body is cut at a sentence end. Articles come with a plain-language summary in the reader's language once it has been written in the background; until then summary is missing.
 // @ID           get-all-news
 // @Tags         news
 // @Produce      json
-// @Param        page  query  int  false  "Page number (default 1)"
+// @Param        page  query  int     false  "Page number (default 1)"
+// @Param        lang  query  string  false  "Summary language: uz, uz-Cyrl, ru or en; defaults to the profile, then Accept-Language"
 // @Success      200  {object}  domain.NewsList
 // @Failure      500  {object}  map[string]interface{}
 // @Router       /news/getall [get]
@@ -41,7 +45,7 @@ func (cr news) GetAll(c *gin.Context) {
 		return
 	}
 
-	newsList, err := cr.uc.GetAll(c, *pq)
+	newsList, err := cr.uc.GetAll(c, middleware.GetCaller(c), *pq)
 	if err != nil {
 		cr.bot.SendErrorNotification(err)
 		c.JSON(500, gin.H{"message": "Failed to fetch news"})
@@ -53,11 +57,13 @@ func (cr news) GetAll(c *gin.Context) {
 
 // GetOneById godoc
 // @Summary      Get one medical news article
-// @Description  Get a single medical article by its ID (e.g. pubmed-12345678 or epmc-12345678)
+// @Description  Get a single medical article by its ID (e.g. pubmed-12345678 or epmc-12345678) with its full abstract
+// @Description  and, once written, its plain-language summary in the reader's language
 // @ID           get-one-news
 // @Tags         news
 // @Produce      json
-// @Param        id  query  string  true  "Article ID"
+// @Param        id    query  string  true   "Article ID"
+// @Param        lang  query  string  false  "Summary language: uz, uz-Cyrl, ru or en; defaults to the profile, then Accept-Language"
 // @Success      200  {object}  domain.NewWithSinglePhoto
 // @Failure      404  {object}  map[string]interface{}
 // @Router       /news/getone [get]
@@ -68,7 +74,7 @@ func (cr news) GetOneById(c *gin.Context) {
 		return
 	}
 
-	article, err := cr.uc.GetOneById(c, id)
+	article, err := cr.uc.GetOneById(c, middleware.GetCaller(c), id)
 	if err != nil {
 		cr.bot.SendErrorNotification(err)
 		c.JSON(500, gin.H{"message": "Failed to fetch article"})
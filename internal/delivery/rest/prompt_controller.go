@@ -0,0 +1,151 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	config "testDeployment/internal/common/config"
+	"testDeployment/internal/delivery/middleware"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type prompts struct {
+	uc     usecase.IPromptUseCase
+	config config.Config
+}
+
+func NewPromptController(
+	group *gin.RouterGroup,
+	uc usecase.IPromptUseCase,
+	config config.Config,
+) {
+	h := &prompts{
+		uc:     uc,
+		config: config,
+	}
+	r := group.Group("/admin/prompts")
+	r.Use(middleware.AdminToken(config.AdminToken))
+	{
+		r.GET("", h.List)
+		r.POST("", h.Create)
+		r.POST("/:id/publish", h.Publish)
+		r.POST("/rollback", h.Rollback)
+	}
+}
+
+// List godoc
+// @Summary      List prompt versions
+// @Description  Returns the stored versions, newest first, optionally filtered by name and locale
+// @Tags         admin
+// @Produce      json
+// @Param        X-Admin-Token  header  string  true   "ADMIN_TOKEN"
+// @Param        name           query   string  false  "Prompt name" Enums(system, image, analysis, comparison)
+// @Param        locale         query   string  false  "Locale, e.g. en, uz, ru"
+// @Success      200  {array}   domain.PromptTemplate
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /admin/prompts [get]
+func (h *prompts) List(ctx *gin.Context) {
+	list, err := h.uc.List(ctx.Request.Context(), ctx.Query("name"), ctx.Query("locale"))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not retrieve prompts"})
+		return
+	}
+	ctx.JSON(http.StatusOK, list)
+}
+
+// Create godoc
+// @Summary      Add a prompt version
+// @Description  Stores the body as the next, unpublished version of the name and locale.
+// @Description  The body is a Go text/template over domain.PromptData, e.g. "{{if .Registered}}The patient is {{.Age}}.{{end}}".
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        X-Admin-Token  header  string                    true  "ADMIN_TOKEN"
+// @Param        prompt         body    domain.NewPromptTemplate  true  "Prompt"
+// @Success      201  {object}  domain.PromptTemplate
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /admin/prompts [post]
+func (h *prompts) Create(ctx *gin.Context) {
+	var req domain.NewPromptTemplate
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tmpl, err := h.uc.Create(ctx.Request.Context(), req)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidPrompt) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, tmpl)
+}
+
+// Publish godoc
+// @Summary      Publish a prompt version
+// @Description  Makes the version the active one of its name and locale; new chat and upload requests use it right away
+// @Tags         admin
+// @Produce      json
+// @Param        X-Admin-Token  header  string  true  "ADMIN_TOKEN"
+// @Param        id             path    int     true  "Prompt version id"
+// @Success      200  {object}  domain.PromptTemplate
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /admin/prompts/{id}/publish [post]
+func (h *prompts) Publish(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid prompt id"})
+		return
+	}
+	tmpl, err := h.uc.Publish(ctx.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, domain.ErrPromptNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, tmpl)
+}
+
+// Rollback godoc
+// @Summary      Roll a prompt back
+// @Description  Re-activates the version that was published before the active one
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        X-Admin-Token  header  string                 true  "ADMIN_TOKEN"
+// @Param        prompt         body    domain.PromptRollback  true  "Name and locale"
+// @Success      200  {object}  domain.PromptTemplate
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /admin/prompts/rollback [post]
+func (h *prompts) Rollback(ctx *gin.Context) {
+	var req domain.PromptRollback
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	tmpl, err := h.uc.Rollback(ctx.Request.Context(), req.Name, req.Locale)
+	if err != nil {
+		if errors.Is(err, domain.ErrNoPreviousPrompt) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, tmpl)
+}
@@ -0,0 +1,234 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	config "testDeployment/internal/common/config"
+	"testDeployment/internal/delivery/middleware"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type quizDrafts struct {
+	uc     usecase.IFactUseCase
+	config config.Config
+}
+
+func NewQuizDraftController(
+	group *gin.RouterGroup,
+	uc usecase.IFactUseCase,
+	config config.Config,
+) {
+	h := &quizDrafts{
+		uc:     uc,
+		config: config,
+	}
+	r := group.Group("/admin/quiz-drafts")
+	r.Use(middleware.AdminToken(config.AdminToken), middleware.AIUsageTag())
+	{
+		r.POST("", h.Generate)
+		r.GET("", h.List)
+		r.GET("/:id", h.Get)
+		r.PUT("/:id", h.Edit)
+		r.POST("/:id/approve", h.Approve)
+		r.POST("/:id/reject", h.Reject)
+	}
+}
+
+// Generate godoc
+// @Summary      Generate quiz questions for a fact
+// @Description  Asks the model for count multiple-choice questions (4 choices, one correct) about the fact's title and content and stores them as a draft.
+// @Description  The questions only reach the quiz once the draft is approved.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        X-Admin-Token  header  string               true  "ADMIN_TOKEN"
+// @Param        draft          body    domain.NewQuizDraft  true  "Fact and number of questions, 1 to 10"
+// @Success      201  {object}  domain.QuizDraft
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Failure      503  {object}  domain.AIUnavailable
+// @Router       /admin/quiz-drafts [post]
+func (h *quizDrafts) Generate(ctx *gin.Context) {
+	var req domain.NewQuizDraft
+	if err := ctx.ShouldBindJSON(&req); err != nil || req.FactId <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "fact_id is required"})
+		return
+	}
+	draft, err := h.uc.GenerateQuizDraft(ctx.Request.Context(), req.FactId, req.Count)
+	if err != nil {
+		quizDraftError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, draft)
+}
+
+// List godoc
+// @Summary      List quiz drafts
+// @Description  Returns the drafts, newest first, optionally only those of one status or fact
+// @Tags         admin
+// @Produce      json
+// @Param        X-Admin-Token  header  string  true   "ADMIN_TOKEN"
+// @Param        status         query   string  false  "Draft status" Enums(draft, approved, rejected)
+// @Param        fact_id        query   int     false  "Only this fact"
+// @Success      200  {array}   domain.QuizDraft
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /admin/quiz-drafts [get]
+func (h *quizDrafts) List(ctx *gin.Context) {
+	factId := 0
+	if value := ctx.Query("fact_id"); value != "" {
+		id, err := strconv.Atoi(value)
+		if err != nil || id <= 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid fact_id"})
+			return
+		}
+		factId = id
+	}
+	drafts, err := h.uc.GetQuizDrafts(ctx.Request.Context(), ctx.Query("status"), factId)
+	if err != nil {
+		quizDraftError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, drafts)
+}
+
+// Get godoc
+// @Summary      Get a quiz draft
+// @Tags         admin
+// @Produce      json
+// @Param        X-Admin-Token  header  string  true  "ADMIN_TOKEN"
+// @Param        id             path    int     true  "Draft id"
+// @Success      200  {object}  domain.QuizDraft
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /admin/quiz-drafts/{id} [get]
+func (h *quizDrafts) Get(ctx *gin.Context) {
+	id, ok := quizDraftID(ctx)
+	if !ok {
+		return
+	}
+	draft, err := h.uc.GetQuizDraft(ctx.Request.Context(), id)
+	if err != nil {
+		quizDraftError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, draft)
+}
+
+// Edit godoc
+// @Summary      Edit a quiz draft
+// @Description  Replaces the questions of a pending draft. Every question needs 2 to 6 distinct choices with exactly one correct.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        X-Admin-Token  header  string                true  "ADMIN_TOKEN"
+// @Param        id             path    int                   true  "Draft id"
+// @Param        draft          body    domain.QuizDraftEdit  true  "Questions"
+// @Success      200  {object}  domain.QuizDraft
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /admin/quiz-drafts/{id} [put]
+func (h *quizDrafts) Edit(ctx *gin.Context) {
+	id, ok := quizDraftID(ctx)
+	if !ok {
+		return
+	}
+	var req domain.QuizDraftEdit
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	draft, err := h.uc.EditQuizDraft(ctx.Request.Context(), id, req.Questions)
+	if err != nil {
+		quizDraftError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, draft)
+}
+
+// Approve godoc
+// @Summary      Approve a quiz draft
+// @Description  Adds the questions and their choices to the fact; they are served by /fact/get-question right away
+// @Tags         admin
+// @Produce      json
+// @Param        X-Admin-Token  header  string  true  "ADMIN_TOKEN"
+// @Param        id             path    int     true  "Draft id"
+// @Success      200  {object}  domain.QuizDraft
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /admin/quiz-drafts/{id}/approve [post]
+func (h *quizDrafts) Approve(ctx *gin.Context) {
+	id, ok := quizDraftID(ctx)
+	if !ok {
+		return
+	}
+	draft, err := h.uc.ApproveQuizDraft(ctx.Request.Context(), id)
+	if err != nil {
+		quizDraftError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, draft)
+}
+
+// Reject godoc
+// @Summary      Reject a quiz draft
+// @Tags         admin
+// @Produce      json
+// @Param        X-Admin-Token  header  string  true  "ADMIN_TOKEN"
+// @Param        id             path    int     true  "Draft id"
+// @Success      200  {object}  domain.QuizDraft
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /admin/quiz-drafts/{id}/reject [post]
+func (h *quizDrafts) Reject(ctx *gin.Context) {
+	id, ok := quizDraftID(ctx)
+	if !ok {
+		return
+	}
+	draft, err := h.uc.RejectQuizDraft(ctx.Request.Context(), id)
+	if err != nil {
+		quizDraftError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, draft)
+}
+
+func quizDraftID(ctx *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid draft id"})
+		return 0, false
+	}
+	return id, true
+}
+
+func quizDraftError(ctx *gin.Context, err error) {
+	if modelUnavailable(ctx, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, domain.ErrInvalidQuiz):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrFactNotFound), errors.Is(err, domain.ErrQuizDraftNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, domain.ErrQuizDraftReviewed):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
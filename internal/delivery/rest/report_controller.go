@@ -0,0 +1,83 @@
+package rest
+
+import (
+	"net/http"
+	"testDeployment/internal/delivery/middleware"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type reports struct {
+	uc usecase.IReportUseCase
+}
+
+func NewReportController(
+	group *gin.RouterGroup,
+	uc usecase.IReportUseCase,
+) {
+	h := &reports{
+		uc: uc,
+	}
+	r := group.Group("/reports")
+	r.Use(middleware.AuthMiddleware())
+	{
+		r.GET("/conversations/:id", h.Conversation)
+		r.GET("/records/:id", h.SkinRecord)
+	}
+}
+
+// Conversation godoc
+// @Summary      Conversation as PDF
+// @Description  A PDF of one of the current user's AI conversations to bring to a doctor: the patient profile, every message with its time, the model and prompt version of each answer, and the medical disclaimer
+// @Tags         reports
+// @Produce      application/pdf
+// @Param        id   path  int  true  "Conversation ID"
+// @Success      200  {file}    file
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /reports/conversations/{id} [get]
+func (h *reports) Conversation(ctx *gin.Context) {
+	id, ok := conversationID(ctx)
+	if !ok {
+		return
+	}
+	report, err := h.uc.ConversationReport(ctx.Request.Context(), middleware.GetUserID(ctx), id)
+	if err != nil {
+		conversationError(ctx, err)
+		return
+	}
+	sendReport(ctx, report)
+}
+
+// SkinRecord godoc
+// @Summary      Skin record as PDF
+// @Description  A PDF of one saved analysis to bring to a doctor: the patient profile, the photo, the verdict, the model and prompt versions behind it, and the medical disclaimer
+// @Tags         reports
+// @Produce      application/pdf
+// @Param        id   path  int  true  "Record id"
+// @Success      200  {file}    file
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /reports/records/{id} [get]
+func (h *reports) SkinRecord(ctx *gin.Context) {
+	id, ok := skinRecordID(ctx)
+	if !ok {
+		return
+	}
+	report, err := h.uc.SkinRecordReport(ctx.Request.Context(), middleware.GetUserID(ctx), id)
+	if err != nil {
+		skinRecordError(ctx, err)
+		return
+	}
+	sendReport(ctx, report)
+}
+
+func sendReport(ctx *gin.Context, report *domain.Report) {
+	ctx.Header("Content-Disposition", `attachment; filename="`+report.Filename+`"`)
+	ctx.Header("Cache-Control", "private, no-store")
+	ctx.Data(http.StatusOK, report.MIMEType, report.Data)
+}
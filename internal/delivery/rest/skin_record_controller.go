@@ -0,0 +1,213 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	config "testDeployment/internal/common/config"
+	"testDeployment/internal/delivery/middleware"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+type skinRecords struct {
+	uc     usecase.ISkinRecordUseCase
+	config config.Config
+}
+
+func NewSkinRecordController(
+	group *gin.RouterGroup,
+	uc usecase.ISkinRecordUseCase,
+	config config.Config,
+) {
+	h := &skinRecords{
+		uc:     uc,
+		config: config,
+	}
+	r := group.Group("/records")
+	r.Use(middleware.AuthMiddleware(), middleware.AIUsageTag())
+	{
+		r.POST("", h.Create)
+		r.GET("", h.GetTimeline)
+		r.GET("/locations", h.GetLocations)
+		r.GET("/:id", h.Get)
+		r.GET("/:id/image", h.GetImage)
+		r.DELETE("/:id", h.Delete)
+	}
+}
+
+// Create godoc
+// @Summary      Save a skin record
+// @Description  Analyses the photo and stores it with the verdict under a named body location ("left forearm mole").
+// @Description  When the location already has records, the latest one is sent to the model as context and the analysis says what changed.
+// @Tags         records
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        image        formData  file    true   "Photo of the spot"
+// @Param        location     formData  string  true   "Body location name"
+// @Param        note         formData  string  false  "Note from the patient"
+// @Param        personalize  query     bool    false  "false to leave the user's profile out of the analysis"
+// @Param        lang         query     string  false  "Answer language: uz, uz-Cyrl, ru or en; defaults to the profile, then Accept-Language"
+// @Success      201  {object}  domain.SkinRecord
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      413  {object}  map[string]interface{}
+// @Failure      415  {object}  map[string]interface{}
+// @Failure      422  {object}  domain.ModerationRejection  "Rejected by moderation, reason is too_long, prompt_injection, off_topic or not_skin_image"
+// @Failure      500  {object}  map[string]interface{}
+// @Failure      503  {object}  domain.AIUnavailable
+// @Router       /records [post]
+func (h *skinRecords) Create(ctx *gin.Context) {
+	fh, err := ctx.FormFile("image")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "No image uploaded"})
+		return
+	}
+	img, ok := readImage(ctx, fh, h.config.Image)
+	if !ok {
+		return
+	}
+
+	record := &domain.SkinRecord{
+		Location: ctx.PostForm("location"),
+		Note:     ctx.PostForm("note"),
+		MIMEType: img.MIMEType,
+	}
+	if err := h.uc.Create(analysisContext(ctx), middleware.GetCaller(ctx), record, img.Data); err != nil {
+		if errors.Is(err, domain.ErrEmptyField) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "location is required"})
+			return
+		}
+		if modelUnavailable(ctx, err) || moderationRejected(ctx, err) {
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusCreated, record)
+}
+
+// GetLocations godoc
+// @Summary      List tracked locations
+// @Description  Returns the current user's body locations with their number of records, most recently updated first
+// @Tags         records
+// @Produce      json
+// @Success      200  {array}   domain.SkinLocation
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /records/locations [get]
+func (h *skinRecords) GetLocations(ctx *gin.Context) {
+	locations, err := h.uc.GetLocations(ctx.Request.Context(), middleware.GetUserID(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not retrieve locations"})
+		return
+	}
+	ctx.JSON(http.StatusOK, locations)
+}
+
+// GetTimeline godoc
+// @Summary      Timeline of a location
+// @Description  Returns every record of one body location, oldest first
+// @Tags         records
+// @Produce      json
+// @Param        location  query  string  true  "Body location name"
+// @Success      200  {array}   domain.SkinRecord
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /records [get]
+func (h *skinRecords) GetTimeline(ctx *gin.Context) {
+	location := ctx.Query("location")
+	if location == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "location is required"})
+		return
+	}
+	records, err := h.uc.GetTimeline(ctx.Request.Context(), middleware.GetUserID(ctx), location)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not retrieve records"})
+		return
+	}
+	ctx.JSON(http.StatusOK, records)
+}
+
+// Get godoc
+// @Summary      Get a skin record
+// @Tags         records
+// @Produce      json
+// @Param        id  path  int  true  "Record id"
+// @Success      200  {object}  domain.SkinRecord
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /records/{id} [get]
+func (h *skinRecords) Get(ctx *gin.Context) {
+	id, ok := skinRecordID(ctx)
+	if !ok {
+		return
+	}
+	record, err := h.uc.Get(ctx.Request.Context(), middleware.GetUserID(ctx), id)
+	if err != nil {
+		skinRecordError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, record)
+}
+
+// GetImage godoc
+// @Summary      Photo of a skin record
+// @Description  Only the owner of the record can fetch it
+// @Tags         records
+// @Produce      image/jpeg
+// @Produce      image/png
+// @Param        id  path  int  true  "Record id"
+// @Success      200  {file}  file
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /records/{id}/image [get]
+func (h *skinRecords) GetImage(ctx *gin.Context) {
+	id, ok := skinRecordID(ctx)
+	if !ok {
+		return
+	}
+	data, mimeType, err := h.uc.GetImage(ctx.Request.Context(), middleware.GetUserID(ctx), id)
+	if err != nil {
+		skinRecordError(ctx, err)
+		return
+	}
+	ctx.Header("Cache-Control", "private, max-age=86400")
+	ctx.Data(http.StatusOK, mimeType, data)
+}
+
+// Delete godoc
+// @Summary      Delete a skin record
+// @Description  Deletes the record and its photo
+// @Tags         records
+// @Param        id  path  int  true  "Record id"
+// @Success      204
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /records/{id} [delete]
+func (h *skinRecords) Delete(ctx *gin.Context) {
+	id, ok := skinRecordID(ctx)
+	if !ok {
+		return
+	}
+	if err := h.uc.Delete(ctx.Request.Context(), middleware.GetUserID(ctx), id); err != nil {
+		skinRecordError(ctx, err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func skinRecordID(ctx *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid record id"})
+		return 0, false
+	}
+	return id, true
+}
+
+func skinRecordError(ctx *gin.Context, err error) {
+	if errors.Is(err, domain.ErrSkinRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
@@ -0,0 +1,122 @@
+package rest
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"testDeployment/internal/domain"
+	"testDeployment/pkg/ai"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Server-Sent Events used by the AI endpoints when the client asks for a
+// stream (Accept: text/event-stream or ?stream=true):
+//
+//	event: chunk  data: {"text": "..."}                       partial answer
+//	event: done   data: {"finish_reason": "...", "usage": {}}  final event
+//	event: error  data: {"error": "...", "retry_after": 30}   generation failed;
+//	                                                           retry_after when no model could answer,
+//	                                                           reason when moderation rejected the request
+const (
+	eventChunk = "chunk"
+	eventDone  = "done"
+	eventError = "error"
+)
+
+func wantsEventStream(ctx *gin.Context) bool {
+	if ctx.Query("stream") == "true" {
+		return true
+	}
+	return strings.Contains(ctx.GetHeader("Accept"), "text/event-stream")
+}
+
+func startEventStream(ctx *gin.Context) {
+	h := ctx.Writer.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no") // stop reverse proxies from buffering the stream
+	ctx.Status(http.StatusOK)
+	ctx.Writer.Flush()
+}
+
+// chunkWriter returns the onChunk callback for the AI streaming calls. It
+// fails as soon as the client has gone away so the upstream request is
+// cancelled instead of generating into the void.
+func chunkWriter(ctx *gin.Context) func(string) error {
+	return func(text string) error {
+		if err := ctx.Request.Context().Err(); err != nil {
+			return err
+		}
+		sendEvent(ctx, eventChunk, gin.H{"text": text})
+		return nil
+	}
+}
+
+func sendEvent(ctx *gin.Context, event string, data interface{}) {
+	ctx.SSEvent(event, data)
+	ctx.Writer.Flush()
+}
+
+// endEventStream sends the final event, or an error event when err is set.
+// Nothing is written once the client has disconnected.
+func endEventStream(ctx *gin.Context, done interface{}, err error) {
+	if ctx.Request.Context().Err() != nil {
+		return
+	}
+	if err != nil {
+		if unavailable, ok := unavailableBody(err); ok {
+			sendEvent(ctx, eventError, unavailable)
+			return
+		}
+		var rejected *domain.RejectionError
+		if errors.As(err, &rejected) {
+			sendEvent(ctx, eventError, rejected.Rejection)
+			return
+		}
+		sendEvent(ctx, eventError, gin.H{"error": err.Error()})
+		return
+	}
+	sendEvent(ctx, eventDone, done)
+}
+
+// modelUnavailable answers 503 with Retry-After when err says no model
+// could answer, and reports whether it did.
+func modelUnavailable(ctx *gin.Context, err error) bool {
+	unavailable, ok := unavailableBody(err)
+	if !ok {
+		return false
+	}
+	ctx.Header("Retry-After", strconv.Itoa(unavailable.RetryAfter))
+	ctx.JSON(http.StatusServiceUnavailable, unavailable)
+	return true
+}
+
+func unavailableBody(err error) (domain.AIUnavailable, bool) {
+	var unavailable *ai.UnavailableError
+	if !errors.As(err, &unavailable) {
+		return domain.AIUnavailable{}, false
+	}
+	seconds := int(math.Ceil(unavailable.RetryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return domain.AIUnavailable{
+		Error:      "The AI assistant is temporarily unavailable, please try again later",
+		RetryAfter: seconds,
+	}, true
+}
+
+// moderationRejected answers 422 with the reason when the moderation stage
+// turned the request away, and reports whether it did.
+func moderationRejected(ctx *gin.Context, err error) bool {
+	var rejected *domain.RejectionError
+	if !errors.As(err, &rejected) {
+		return false
+	}
+	ctx.JSON(http.StatusUnprocessableEntity, rejected.Rejection)
+	return true
+}
@@ -0,0 +1,235 @@
+package rest
+
+import (
+	"net/http"
+	"strconv"
+	config "testDeployment/internal/common/config"
+	"testDeployment/internal/delivery/middleware"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultUsageDays  = 30
+	maxUsageDays      = 366
+	defaultUsageLimit = 10
+	maxUsageLimit     = 100
+)
+
+type usage struct {
+	uc         usecase.IUsageUseCase
+	moderation usecase.IModerationUseCase
+	config     config.Config
+}
+
+func NewUsageController(
+	group *gin.RouterGroup,
+	uc usecase.IUsageUseCase,
+	moderation usecase.IModerationUseCase,
+	config config.Config,
+) {
+	h := &usage{
+		uc:         uc,
+		moderation: moderation,
+		config:     config,
+	}
+	r := group.Group("/admin/usage")
+	r.Use(middleware.AdminToken(config.AdminToken))
+	{
+		r.GET("/daily", h.GetDaily)
+		r.GET("/top", h.GetTopConsumers)
+		r.GET("/users/:id", h.GetUser)
+		r.GET("/guests/:id", h.GetGuest)
+		r.GET("/rejections", h.GetRejections)
+	}
+}
+
+// GetDaily godoc
+// @Summary      AI usage per day
+// @Description  Calls, tokens, latency and estimated cost per day, newest first, optionally for one user or guest
+// @Tags         admin
+// @Produce      json
+// @Param        X-Admin-Token  header  string  true   "ADMIN_TOKEN"
+// @Param        days           query   int     false  "Number of days, default 30"
+// @Param        user_id        query   int     false  "Only this user"
+// @Param        guest_id       query   string  false  "Only this guest"
+// @Success      200  {array}   domain.UsageDay
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /admin/usage/daily [get]
+func (h *usage) GetDaily(ctx *gin.Context) {
+	filter, ok := usageFilter(ctx)
+	if !ok {
+		return
+	}
+	if !consumerFilter(ctx, &filter) {
+		return
+	}
+
+	days, err := h.uc.GetDaily(ctx.Request.Context(), filter)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not retrieve usage"})
+		return
+	}
+	ctx.JSON(http.StatusOK, days)
+}
+
+// GetTopConsumers godoc
+// @Summary      Top AI consumers
+// @Description  Users and guests ordered by tokens used
+// @Tags         admin
+// @Produce      json
+// @Param        X-Admin-Token  header  string  true   "ADMIN_TOKEN"
+// @Param        days           query   int     false  "Number of days, default 30"
+// @Param        limit          query   int     false  "Number of consumers, default 10"
+// @Success      200  {array}   domain.UsageConsumer
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /admin/usage/top [get]
+func (h *usage) GetTopConsumers(ctx *gin.Context) {
+	filter, ok := usageFilter(ctx)
+	if !ok {
+		return
+	}
+	limit, ok := queryInt(ctx, "limit", defaultUsageLimit, maxUsageLimit)
+	if !ok {
+		return
+	}
+	consumers, err := h.uc.GetTopConsumers(ctx.Request.Context(), filter, limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not retrieve usage"})
+		return
+	}
+	ctx.JSON(http.StatusOK, consumers)
+}
+
+// GetUser godoc
+// @Summary      AI usage of a user
+// @Description  Totals and a breakdown by endpoint and model
+// @Tags         admin
+// @Produce      json
+// @Param        X-Admin-Token  header  string  true   "ADMIN_TOKEN"
+// @Param        id             path    int     true   "User id"
+// @Param        days           query   int     false  "Number of days, default 30"
+// @Success      200  {object}  domain.UsageSummary
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /admin/usage/users/{id} [get]
+func (h *usage) GetUser(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil || id <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+		return
+	}
+	filter, ok := usageFilter(ctx)
+	if !ok {
+		return
+	}
+	filter.UserId = id
+	h.summary(ctx, filter)
+}
+
+// GetGuest godoc
+// @Summary      AI usage of a guest
+// @Description  Totals and a breakdown by endpoint and model
+// @Tags         admin
+// @Produce      json
+// @Param        X-Admin-Token  header  string  true   "ADMIN_TOKEN"
+// @Param        id             path    string  true   "Guest id"
+// @Param        days           query   int     false  "Number of days, default 30"
+// @Success      200  {object}  domain.UsageSummary
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /admin/usage/guests/{id} [get]
+func (h *usage) GetGuest(ctx *gin.Context) {
+	filter, ok := usageFilter(ctx)
+	if !ok {
+		return
+	}
+	filter.GuestId = ctx.Param("id")
+	h.summary(ctx, filter)
+}
+
+// GetRejections godoc
+// @Summary      Most rejected AI callers
+// @Description  Users and guests ordered by how many of their requests the moderation stage rejected, with the count per reason
+// @Tags         admin
+// @Produce      json
+// @Param        X-Admin-Token  header  string  true   "ADMIN_TOKEN"
+// @Param        days           query   int     false  "Number of days, default 30"
+// @Param        limit          query   int     false  "Number of callers, default 10"
+// @Param        user_id        query   int     false  "Only this user"
+// @Param        guest_id       query   string  false  "Only this guest"
+// @Success      200  {array}   domain.RejectionConsumer
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /admin/usage/rejections [get]
+func (h *usage) GetRejections(ctx *gin.Context) {
+	filter, ok := usageFilter(ctx)
+	if !ok {
+		return
+	}
+	limit, ok := queryInt(ctx, "limit", defaultUsageLimit, maxUsageLimit)
+	if !ok {
+		return
+	}
+	if !consumerFilter(ctx, &filter) {
+		return
+	}
+
+	consumers, err := h.moderation.GetTopRejected(ctx.Request.Context(), filter, limit)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not retrieve rejections"})
+		return
+	}
+	ctx.JSON(http.StatusOK, consumers)
+}
+
+func (h *usage) summary(ctx *gin.Context, filter domain.UsageFilter) {
+	summary, err := h.uc.GetSummary(ctx.Request.Context(), filter)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "could not retrieve usage"})
+		return
+	}
+	ctx.JSON(http.StatusOK, summary)
+}
+
+func usageFilter(ctx *gin.Context) (domain.UsageFilter, bool) {
+	days, ok := queryInt(ctx, "days", defaultUsageDays, maxUsageDays)
+	return domain.UsageFilter{Days: days}, ok
+}
+
+// consumerFilter narrows filter to the user_id or guest_id query parameter.
+func consumerFilter(ctx *gin.Context, filter *domain.UsageFilter) bool {
+	if userId := ctx.Query("user_id"); userId != "" {
+		id, err := strconv.Atoi(userId)
+		if err != nil || id <= 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+			return false
+		}
+		filter.UserId = id
+	}
+	filter.GuestId = ctx.Query("guest_id")
+	return true
+}
+
+// queryInt reads a positive integer query parameter, def when absent.
+func queryInt(ctx *gin.Context, name string, def, upper int) (int, bool) {
+	value := ctx.Query(name)
+	if value == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 || n > upper {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name + ", expected 1 to " + strconv.Itoa(upper)})
+		return 0, false
+	}
+	return n, true
+}
@@ -1,10 +1,13 @@
 package rest
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"testDeployment/internal/delivery/dto"
 	"testDeployment/internal/delivery/middleware"
+	"testDeployment/internal/domain"
 	"testDeployment/pkg/jwt"
 	"time"
 
@@ -14,7 +17,7 @@ import (
 
 // SignUp godoc
 // @Summary      Register a new user
-// @Description  Create a new account with email, username and password (min 6 chars). Returns JWT access token.
+// @Description  Create a new account with email, username and password (min 6 chars). Returns a short-lived JWT access token and a refresh token, and emails a code to verify the address with.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -66,7 +69,7 @@ func (c controller) SignUp(ctx *gin.Context) {
 		return
 	}
 
-	token, err := jwt.CreateToken(id, "user")
+	auth, err := c.auth.Issue(ctx.Request.Context(), id, "user")
 	if err != nil {
 		c.bot.SendErrorNotification(err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{
@@ -77,21 +80,19 @@ func (c controller) SignUp(ctx *gin.Context) {
 	}
 
 	s := sessions.Default(ctx)
-	s.Set("Token", token)
+	s.Set("Token", auth.AccessToken)
 	s.Set("userId", id)
+	s.Set("issuedAt", time.Now().Unix())
 	s.Save()
 
-	ctx.JSON(http.StatusCreated, dto.AuthResponse{
-		AccessToken: token,
-		UserID:      id,
-		Role:        "user",
-		ExpiresIn:   86400,
-	})
+	c.sendVerificationCode(id)
+
+	ctx.JSON(http.StatusCreated, auth)
 }
 
 // Login godoc
 // @Summary      Login user
-// @Description  Authenticate with username and password. Returns JWT access token.
+// @Description  Authenticate with username and password. Returns a short-lived JWT access token and a refresh token.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -151,7 +152,7 @@ func (c controller) Login(ctx *gin.Context) {
 		return
 	}
 
-	token, err := jwt.CreateToken(id, "user")
+	auth, err := c.auth.Issue(ctx.Request.Context(), id, "user")
 	if err != nil {
 		c.bot.SendErrorNotification(err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{
@@ -162,15 +163,152 @@ func (c controller) Login(ctx *gin.Context) {
 	}
 
 	s := sessions.Default(ctx)
-	s.Set("Token", token)
+	s.Set("Token", auth.AccessToken)
 	s.Set("userId", id)
+	s.Set("issuedAt", time.Now().Unix())
 	s.Save()
 
-	ctx.JSON(http.StatusOK, dto.AuthResponse{
-		AccessToken: token,
-		UserID:      id,
-		Role:        "user",
-		ExpiresIn:   86400,
+	ctx.JSON(http.StatusOK, auth)
+}
+
+// Refresh godoc
+// @Summary      Refresh tokens
+// @Description  Exchange a refresh token for a new access token and refresh token. Every refresh token works once: presenting a used one signs out every session it was issued from.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.RefreshRequest  true  "Refresh token"
+// @Success      200   {object}  dto.AuthResponse
+// @Failure      400   {object}  map[string]interface{}
+// @Failure      401   {object}  map[string]interface{}
+// @Router       /auth/refresh [post]
+func (c controller) Refresh(ctx *gin.Context) {
+	var req dto.RefreshRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": "refresh_token is required",
+		})
+		return
+	}
+
+	auth, err := c.auth.Refresh(ctx.Request.Context(), req.RefreshToken)
+	switch {
+	case errors.Is(err, domain.ErrInvalidRefreshToken):
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "invalid_refresh_token",
+			"message": err.Error(),
+		})
+		return
+	case errors.Is(err, domain.ErrRefreshTokenReused):
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error":   "refresh_token_reused",
+			"message": err.Error(),
+		})
+		return
+	case err != nil:
+		c.bot.SendErrorNotification(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "token_error",
+			"message": "Could not refresh token",
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, auth)
+}
+
+// VerifyEmail godoc
+// @Summary      Verify email
+// @Description  Verify the email of the current user with the code mailed to it. A code expires after a while and after a few wrong guesses.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        body  body      dto.VerifyEmailRequest  true  "Verification code"
+// @Success      200   {object}  map[string]interface{}
+// @Failure      400   {object}  map[string]interface{}
+// @Failure      401   {object}  map[string]interface{}
+// @Failure      409   {object}  map[string]interface{}
+// @Failure      410   {object}  map[string]interface{}
+// @Failure      429   {object}  map[string]interface{}
+// @Router       /auth/verify-email [post]
+func (c controller) VerifyEmail(ctx *gin.Context) {
+	var req dto.VerifyEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid_request",
+			"message": "code is required",
+		})
+		return
+	}
+
+	err := c.verification.Verify(ctx.Request.Context(), middleware.GetUserID(ctx), req.Code)
+	if err != nil {
+		c.verificationError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "email verified"})
+}
+
+// ResendCode godoc
+// @Summary      Resend verification code
+// @Description  Email a new verification code to the current user; the previous code stops working. Limited to one every minute or so.
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Failure      429  {object}  map[string]interface{}
+// @Router       /auth/resend-code [post]
+func (c controller) ResendCode(ctx *gin.Context) {
+	if err := c.verification.SendCode(ctx.Request.Context(), middleware.GetUserID(ctx)); err != nil {
+		c.verificationError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "verification code sent"})
+}
+
+// sendVerificationCode mails a code in the background, so a slow or failing
+// mail server does not hold up signup; the user can ask again with
+// /auth/resend-code.
+func (c controller) sendVerificationCode(userId int) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		// Failures are reported by the use case.
+		_ = c.verification.SendCode(ctx, userId)
+	}()
+}
+
+func (c controller) verificationError(ctx *gin.Context, err error) {
+	status, code := http.StatusInternalServerError, "verification_failed"
+	switch {
+	case errors.Is(err, domain.ErrNoEmail):
+		status, code = http.StatusBadRequest, "no_email"
+	case errors.Is(err, domain.ErrNoVerificationCode):
+		status, code = http.StatusBadRequest, "no_verification_code"
+	case errors.Is(err, domain.ErrInvalidVerificationCode):
+		status, code = http.StatusBadRequest, "invalid_code"
+	case errors.Is(err, domain.ErrEmailAlreadyVerified):
+		status, code = http.StatusConflict, "email_already_verified"
+	case errors.Is(err, domain.ErrVerificationCodeExpired):
+		status, code = http.StatusGone, "code_expired"
+	case errors.Is(err, domain.ErrVerificationAttempts):
+		status, code = http.StatusTooManyRequests, "too_many_attempts"
+	case errors.Is(err, domain.ErrResendTooSoon):
+		status, code = http.StatusTooManyRequests, "resend_too_soon"
+	default:
+		c.bot.SendErrorNotification(err)
+		ctx.JSON(status, gin.H{
+			"error":   code,
+			"message": "Could not verify email",
+		})
+		return
+	}
+	ctx.JSON(status, gin.H{
+		"error":   code,
+		"message": err.Error(),
 	})
 }
 
@@ -221,6 +359,9 @@ func (c controller) AuthStatus(ctx *gin.Context) {
 
 	if userID > 0 {
 		response["user_id"] = userID
+		if verified, err := c.verification.IsVerified(ctx.Request.Context(), userID); err == nil {
+			response["email_verified"] = verified
+		}
 	}
 
 	if role == "guest" {
@@ -237,12 +378,23 @@ func (c controller) AuthStatus(ctx *gin.Context) {
 
 // Logout godoc
 // @Summary      Logout user
-// @Description  Clear session and logout user
+// @Description  Log out this device: clear the session and revoke the access token and its refresh token
 // @Tags         auth
 // @Produce      json
 // @Success      200  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
 // @Router       /dashboard/middle/logout [get]
 func (c controller) Logout(ctx *gin.Context) {
+	if claims := middleware.GetClaims(ctx); claims != nil {
+		if err := c.auth.Logout(ctx.Request.Context(), claims); err != nil {
+			c.bot.SendErrorNotification(err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "logout_failed",
+				"message": "Could not revoke the token",
+			})
+			return
+		}
+	}
 	s := sessions.Default(ctx)
 	s.Clear()
 	s.Save()
@@ -251,13 +403,40 @@ func (c controller) Logout(ctx *gin.Context) {
 	})
 }
 
+// LogoutEverywhere godoc
+// @Summary      Logout user everywhere
+// @Description  Log out every device: revoke all access tokens, refresh tokens and sessions of the user
+// @Tags         auth
+// @Produce      json
+// @Success      200  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /dashboard/middle/logout-all [get]
+func (c controller) LogoutEverywhere(ctx *gin.Context) {
+	id := middleware.GetUserID(ctx)
+	if err := c.auth.LogoutEverywhere(ctx.Request.Context(), id); err != nil {
+		c.bot.SendErrorNotification(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "logout_failed",
+			"message": "Could not revoke the tokens",
+		})
+		return
+	}
+	s := sessions.Default(ctx)
+	s.Clear()
+	s.Save()
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "successfully logged out everywhere",
+	})
+}
+
 // DeleteAccount godoc
 // @Summary      Delete user account
-// @Description  Delete the current user account and clear session
+// @Description  Delete the current user account, revoke all of its tokens and clear session
 // @Tags         users
 // @Produce      json
 // @Success      200  {object}  map[string]interface{}
 // @Failure      400  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
 // @Router       /dashboard/middle/deleteAccount [get]
 func (c controller) DeleteAccount(ctx *gin.Context) {
 	id := middleware.GetUserID(ctx)
@@ -271,6 +450,11 @@ func (c controller) DeleteAccount(ctx *gin.Context) {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if err := c.auth.LogoutEverywhere(ctx.Request.Context(), id); err != nil {
+		c.bot.SendErrorNotification(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 	s := sessions.Default(ctx)
 	s.Clear()
 	s.Save()
@@ -301,10 +485,11 @@ func (c controller) GetPremium(ctx *gin.Context) {
 
 // BuyPremium godoc
 // @Summary      Buy premium
-// @Description  Upgrade the current user to premium
+// @Description  Upgrade the current user to premium. Requires a verified email.
 // @Tags         users
 // @Produce      json
 // @Success      200  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
 // @Router       /dashboard/middle/buy_premium [get]
 func (c controller) BuyPremium(ctx *gin.Context) {
 	id := middleware.GetUserID(ctx)
@@ -312,7 +497,19 @@ func (c controller) BuyPremium(ctx *gin.Context) {
 		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "not authenticated"})
 		return
 	}
-	err := c.usecase.UpdatePremium(id)
+	verified, err := c.verification.IsVerified(ctx.Request.Context(), id)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !verified {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error":   "email_not_verified",
+			"message": domain.ErrEmailNotVerified.Error(),
+		})
+		return
+	}
+	err = c.usecase.UpdatePremium(id)
 	if err != nil {
 		c.bot.SendErrorNotification(err)
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -45,7 +45,7 @@ func (c controller) FillUserInfo(ctx *gin.Context) {
 
 // CreateUserEmailHandler godoc
 // @Summary User email
-// @Description Update user email
+// @Description Update user email. A new address has to be verified again; a code is emailed to it.
 // @Tags users
 // @Accept  json
 // @Produce  json
@@ -56,7 +56,6 @@ func (c controller) FillUserInfo(ctx *gin.Context) {
 // @Router /dashboard/middle/update-email [post]
 func (c controller) UpdateEmail(ctx *gin.Context) {
 	var user dto.UserEmail
-	user.ID = middleware.GetUserID(ctx)
 
 	// Bind JSON input to the struct
 	if err := ctx.ShouldBindJSON(&user); err != nil {
@@ -66,6 +65,8 @@ func (c controller) UpdateEmail(ctx *gin.Context) {
 		})
 		return
 	}
+	// Set after binding so the body cannot name another user.
+	user.ID = middleware.GetUserID(ctx)
 
 	// Update email
 	id, err := c.usecase.UpdateEmail(user)
@@ -77,6 +78,9 @@ func (c controller) UpdateEmail(ctx *gin.Context) {
 		return
 	}
 
+	// A new address is unverified until its code comes back.
+	c.sendVerificationCode(user.ID)
+
 	// Return the ID of the updated email
 	ctx.JSON(200, gin.H{
 		"id": id,
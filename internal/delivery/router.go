@@ -3,6 +3,7 @@ package delivery
 import (
 	"database/sql"
 	request "testDeployment/internal/delivery/http"
+	"testDeployment/internal/delivery/middleware"
 	"testDeployment/internal/delivery/rest"
 	"testDeployment/internal/usecase"
 	"testDeployment/pkg/Bot"
@@ -17,11 +18,11 @@ func SetUp(
 	uc usecase.IUseCase,
 	bot Bot.Bot,
 	request request.CustomJSONRequester,
-	model *ai2.Dermato,
+	model ai2.Provider,
 	config config.Config,
 	db *sql.DB,
-	geminiKey string,
 ) {
+	middleware.Revocations = uc.IAuthUseCase()
 	SetUpHandlerV1(
 		g.Group("/api/v1"),
 		uc,
@@ -30,7 +31,6 @@ func SetUp(
 		model,
 		config,
 		db,
-		geminiKey,
 	)
 
 }
@@ -39,10 +39,9 @@ func SetUpHandlerV1(
 	uc usecase.IUseCase,
 	bot Bot.Bot,
 	request request.CustomJSONRequester,
-	model *ai2.Dermato,
+	model ai2.Provider,
 	config config.Config,
 	db *sql.DB,
-	geminiKey string,
 ) {
 	rest.NewFrontend(
 		group,
@@ -50,6 +49,8 @@ func SetUpHandlerV1(
 	rest.NewController(
 		group,
 		uc.IOtherUseCase(),
+		uc.IAuthUseCase(),
+		uc.IVerificationUseCase(),
 		bot,
 		request,
 	)
@@ -67,17 +68,49 @@ func SetUpHandlerV1(
 	rest.NewFactsController(
 		group,
 		uc.IFactUseCase(),
+		config.Image,
 	)
 	rest.NewChat(
 		group,
-		model,
+		uc.IChatUseCase(),
+		uc.IAnalysisUseCase(),
+		uc.IAgentUseCase(),
+		config,
+	)
+	rest.NewDrugController(
+		group,
+		uc.IDrugUseCase(),
+	)
+	rest.NewSkinRecordController(
+		group,
+		uc.ISkinRecordUseCase(),
+		config,
+	)
+	rest.NewReportController(
+		group,
+		uc.IReportUseCase(),
+	)
+	rest.NewPromptController(
+		group,
+		uc.IPromptUseCase(),
+		config,
+	)
+	rest.NewQuizDraftController(
+		group,
+		uc.IFactUseCase(),
+		config,
+	)
+	rest.NewUsageController(
+		group,
+		uc.IUsageUseCase(),
+		uc.IModerationUseCase(),
 		config,
 	)
 	rest.NewHealthController(
 		group,
 		db,
 		config.BotToken,
-		geminiKey,
+		model,
 	)
 
 }
@@ -0,0 +1,25 @@
+package domain
+
+// AgentRequest is a request to the assistant that may act on our data:
+// look up doctors and drugs and book appointments. Each request stands
+// alone: the agent keeps no history between them.
+type AgentRequest struct {
+	Request string `json:"message"`
+}
+
+// AgentStep is one tool the agent called on the way to its answer.
+type AgentStep struct {
+	Tool string                 `json:"tool"`
+	Args map[string]interface{} `json:"args"`
+	// Result is what the tool returned to the model, Error why it failed.
+	Result     interface{} `json:"result,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	DurationMs int64       `json:"duration_ms"`
+}
+
+// AgentReply is the final answer of the agent with the actions it took, in
+// order.
+type AgentReply struct {
+	ChatReply
+	Trace []AgentStep `json:"trace"`
+}
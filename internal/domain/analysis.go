@@ -0,0 +1,82 @@
+package domain
+
+// Urgency levels of a skin analysis, from "can wait" to "go now".
+const (
+	UrgencyLow       = "low"
+	UrgencyModerate  = "moderate"
+	UrgencyHigh      = "high"
+	UrgencyEmergency = "emergency"
+)
+
+var UrgencyLevels = []string{UrgencyLow, UrgencyModerate, UrgencyHigh, UrgencyEmergency}
+
+// SkinAnalysis is the typed result of analysing a photo, returned by
+// /chat/upload?mode=analysis. Fallback is set when the model could not
+// produce valid structured output and only Summary holds its free-text
+// answer.
+type SkinAnalysis struct {
+	Conditions           []ConditionCandidate `json:"conditions"`
+	VisibleFeatures      []string             `json:"visible_features"`
+	Urgency              string               `json:"urgency,omitempty"`
+	SelfCare             []string             `json:"self_care"`
+	RecommendedSpecialty string               `json:"recommended_specialty,omitempty"`
+	Summary              string               `json:"summary"`
+	// ChangeSincePrevious is only set when a previous record of the same
+	// location was given as context.
+	ChangeSincePrevious string `json:"change_since_previous,omitempty"`
+	Fallback            bool   `json:"fallback,omitempty"`
+	// PromptTemplateIds are the stored prompt versions that produced it.
+	PromptTemplateIds []int           `json:"prompt_template_ids,omitempty"`
+	Model             string          `json:"model,omitempty"`
+	Disclaimer        string          `json:"disclaimer"`
+	UrgentReferral    *UrgentReferral `json:"urgent_referral,omitempty"`
+	Cached            bool            `json:"cached,omitempty"`
+	Language          string          `json:"language"`
+}
+
+type ConditionCandidate struct {
+	Name       string  `json:"name"`
+	Confidence float64 `json:"confidence"`
+}
+
+// Trends of a before/after comparison.
+const (
+	TrendImproved  = "improved"
+	TrendUnchanged = "unchanged"
+	TrendWorsened  = "worsened"
+	TrendUnclear   = "unclear"
+)
+
+var Trends = []string{TrendImproved, TrendUnchanged, TrendWorsened, TrendUnclear}
+
+// LabeledImage is one photo of a comparison, labelled e.g. "before",
+// "after" or "close-up".
+type LabeledImage struct {
+	Label    string
+	Data     []byte
+	MIMEType string
+}
+
+// ImageComparison is the result of /chat/compare: what each photo shows and
+// how the area changed across them. Fallback has the same meaning as in
+// SkinAnalysis.
+type ImageComparison struct {
+	Images   []ImageObservation `json:"images"`
+	Changes  []string           `json:"changes"`
+	Trend    string             `json:"trend,omitempty"`
+	Urgency  string             `json:"urgency,omitempty"`
+	Summary  string             `json:"summary"`
+	Fallback bool               `json:"fallback,omitempty"`
+
+	PromptTemplateIds []int           `json:"prompt_template_ids,omitempty"`
+	Model             string          `json:"model,omitempty"`
+	Disclaimer        string          `json:"disclaimer"`
+	UrgentReferral    *UrgentReferral `json:"urgent_referral,omitempty"`
+	Cached            bool            `json:"cached,omitempty"`
+	Language          string          `json:"language"`
+}
+
+type ImageObservation struct {
+	Label    string `json:"label"`
+	Findings string `json:"findings"`
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "time"
+
+// RefreshToken is one link of a rotation chain. Only the hash of the
+// opaque token is stored. Every refresh spends the token and issues the
+// next one of the same family, so a spent token coming back means it was
+// stolen.
+type RefreshToken struct {
+	Id        int
+	UserId    int
+	Role      string
+	FamilyId  string
+	TokenHash string
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
+
+// RevokedToken is an access token signed out before it expires. It is kept
+// until then.
+type RevokedToken struct {
+	Jti       string
+	UserId    int
+	ExpiresAt time.Time
+}
+
+// UserRevocation signs a user out everywhere: every token issued to them
+// before RevokedBefore, a whole second, is refused.
+type UserRevocation struct {
+	UserId        int
+	RevokedBefore time.Time
+}
+
+// EmailVerification is the code last sent to a user to verify Email. Only
+// its bcrypt hash is stored; it is deleted once the email is verified.
+type EmailVerification struct {
+	UserId    int
+	Email     string
+	CodeHash  string
+	Attempts  int
+	ExpiresAt time.Time
+	SentAt    time.Time
+}
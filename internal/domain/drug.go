@@ -1,4 +1,11 @@
 package domain
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
 type Drug struct{
 	Id string `json:"id"`
 	Name string `json:"name"`
@@ -24,4 +31,48 @@ type DrugWithoutType struct {
 	Description string `json:"description"`
 	Receipt string `json:"receipt"`
 	Photo []string `json:"photo"`
-}
\ No newline at end of file
+}
+
+// DrugCitation is a catalog drug cited in an AI answer as [drug:ID]; the
+// client links it to GET /drugs/{id}.
+type DrugCitation struct {
+	Id   int      `json:"id"`
+	Name string   `json:"name"`
+	Type []string `json:"type,omitempty"`
+}
+
+// drugMarker is how answers cite a catalog drug: [drug:12].
+var drugMarker = regexp.MustCompile(`(?i)\s?\[drug:\s*(\d+)\]`)
+
+// DrugGrounding is the system prompt with the catalog drugs relevant to a
+// request, the only ones the answer may recommend.
+type DrugGrounding struct {
+	System string
+	Drugs  []*Drug
+}
+
+// Apply lists the drugs the answer cites and removes citations of drugs
+// that were not offered, so every citation the client sees links to a drug
+// we carry.
+func (g *DrugGrounding) Apply(reply *ChatReply) *ChatReply {
+	offered := make(map[string]*Drug, len(g.Drugs))
+	for _, d := range g.Drugs {
+		offered[d.Id] = d
+	}
+	cited := make(map[string]bool)
+	reply.Response = drugMarker.ReplaceAllStringFunc(reply.Response, func(marker string) string {
+		id := drugMarker.FindStringSubmatch(marker)[1]
+		id = strings.TrimLeft(id, "0")
+		d, ok := offered[id]
+		if !ok {
+			return ""
+		}
+		if !cited[id] {
+			cited[id] = true
+			n, _ := strconv.Atoi(id)
+			reply.Drugs = append(reply.Drugs, DrugCitation{Id: n, Name: d.Name, Type: d.Type})
+		}
+		return marker
+	})
+	return reply
+}
@@ -7,6 +7,33 @@ const (
 	ErrCouldNotCreateProgram        = Err("Cannot create program type")
 	ErrCouldNotRetrieveFromDataBase = Err("Cannot read from database")
 	ErrEmptyField=Err("empty space")
+	ErrConversationNotFound         = Err("conversation not found")
+	ErrMessageNotFound              = Err("message not found")
+	ErrSummaryNotFound              = Err("conversation has no summary yet")
+	ErrMalformedAnalysis            = Err("model returned a malformed analysis")
+	ErrSkinRecordNotFound           = Err("skin record not found")
+	ErrPromptNotFound               = Err("prompt template not found")
+	ErrNoPreviousPrompt             = Err("no earlier published version to roll back to")
+	ErrInvalidPrompt                = Err("invalid prompt template")
+	ErrProfileNotFound              = Err("profile not found")
+	ErrCacheMiss                    = Err("not in cache")
+	ErrDrugNotFound                 = Err("drug not found")
+	ErrUnsupportedLanguage          = Err("unsupported language, expected uz, uz-Cyrl, ru or en")
+	ErrFactNotFound                 = Err("fact not found")
+	ErrQuizDraftNotFound            = Err("quiz draft not found")
+	ErrQuizDraftReviewed            = Err("quiz draft was already reviewed")
+	ErrInvalidQuiz                  = Err("invalid quiz")
+	ErrMalformedQuiz                = Err("model returned a malformed quiz")
+	ErrInvalidRefreshToken          = Err("invalid or expired refresh token")
+	ErrRefreshTokenReused           = Err("refresh token was already used, the session has been signed out")
+	ErrNoEmail                      = Err("the account has no email address")
+	ErrEmailAlreadyVerified         = Err("email is already verified")
+	ErrEmailNotVerified             = Err("verify your email first")
+	ErrNoVerificationCode           = Err("no verification code was sent to this email, ask for a new one")
+	ErrVerificationCodeExpired      = Err("verification code expired, ask for a new one")
+	ErrVerificationAttempts         = Err("too many wrong codes, ask for a new one")
+	ErrInvalidVerificationCode      = Err("wrong verification code")
+	ErrResendTooSoon                = Err("a code was sent a moment ago, wait before asking again")
 )
 
 type Err string
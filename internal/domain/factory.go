@@ -39,24 +39,30 @@ func (f Factory) ParseDomainToModel(u User) {
 }
 func (f Factory) ParseModelToUserInfo(u dto.UserInfo) *UserInfo {
 	return &UserInfo{
-		Id:        u.Id,
-		Firstname: u.Firstname,
-		Lastname:  u.Lastname,
-		Gender:    u.Gender,
-		SkinColor: u.SkinColor,
-		SkinType:  u.SkinType,
-		UpdatedAt: time.Now(),
-		Date:      utils.ParseDateString(u.Date),
+		Id:         u.Id,
+		Firstname:  u.Firstname,
+		Lastname:   u.Lastname,
+		Gender:     u.Gender,
+		SkinColor:  u.SkinColor,
+		SkinType:   u.SkinType,
+		UpdatedAt:  time.Now(),
+		Date:       utils.ParseDateString(u.Date),
+		Conditions: u.Conditions,
+		Allergies:  u.Allergies,
+		Language:   NormalizeLanguage(u.Language),
 	}
 }
 func (f Factory) ParseUserInfoToModel(u UserInfo) *dto.UserInfo {
 	return &dto.UserInfo{
-		Firstname: u.Firstname,
-		Lastname:  u.Lastname,
-		Gender:    u.Gender,
-		SkinColor: u.SkinColor,
-		SkinType:  u.SkinType,
-		Date:      utils.FormatTimeToRFC3339(u.Date),
+		Firstname:  u.Firstname,
+		Lastname:   u.Lastname,
+		Gender:     u.Gender,
+		SkinColor:  u.SkinColor,
+		SkinType:   u.SkinType,
+		Date:       utils.FormatTimeToRFC3339(u.Date),
+		Conditions: u.Conditions,
+		Allergies:  u.Allergies,
+		Language:   u.Language,
 	}
 
 }
@@ -0,0 +1,63 @@
+package domain
+
+import "strings"
+
+// Languages the assistant answers in. Uzbek is written in both scripts:
+// Latin is the official one, Cyrillic is still common among older users.
+const (
+	LanguageEnglish       = "en"
+	LanguageRussian       = "ru"
+	LanguageUzbek         = "uz"
+	LanguageUzbekCyrillic = "uz-Cyrl"
+)
+
+const languageScriptCyrillic = "cyrl"
+
+var Languages = []string{LanguageUzbek, LanguageUzbekCyrillic, LanguageRussian, LanguageEnglish}
+
+var languageInstructions = map[string]string{
+	LanguageEnglish: "Write your answer in English.",
+	LanguageRussian: "Write your answer in Russian.",
+	LanguageUzbek: "Write your answer in Uzbek using the Latin alphabet (oʻ, gʻ, sh, ch), never Cyrillic, " +
+		"and do not switch to Russian.",
+	LanguageUzbekCyrillic: "Write your answer in Uzbek using the Cyrillic alphabet (ў, қ, ғ, ҳ), never Latin, " +
+		"and do not switch to Russian.",
+}
+
+// NormalizeLanguage maps a language tag ("uz-UZ", "uz_Cyrl", "ru-RU", "EN")
+// to one of Languages, or returns "" when we do not answer in it. Uzbek
+// without a script is Latin.
+func NormalizeLanguage(tag string) string {
+	parts := strings.Split(strings.ToLower(strings.TrimSpace(strings.ReplaceAll(tag, "_", "-"))), "-")
+	switch parts[0] {
+	case LanguageEnglish:
+		return LanguageEnglish
+	case LanguageRussian:
+		return LanguageRussian
+	case LanguageUzbek:
+		for _, p := range parts[1:] {
+			if p == languageScriptCyrillic {
+				return LanguageUzbekCyrillic
+			}
+		}
+		return LanguageUzbek
+	}
+	return ""
+}
+
+// BaseLanguage drops the script: "uz-Cyrl" is "uz".
+func BaseLanguage(lang string) string {
+	return strings.SplitN(lang, "-", 2)[0]
+}
+
+// LanguageInstruction tells the model which language and script to answer
+// in. Catalog names and JSON values stay as they are so citations and
+// structured answers keep working.
+func LanguageInstruction(lang string) string {
+	instruction, ok := languageInstructions[lang]
+	if !ok {
+		instruction = languageInstructions[DefaultLocale]
+	}
+	return instruction + " Keep medicine and product names exactly as they are written in the catalog, " +
+		"and keep JSON keys and fixed values such as enums in English."
+}
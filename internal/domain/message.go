@@ -1,15 +1,110 @@
 package domain
 
 type Message struct {
-	Id        int    `json:"id"`
-	User_id   string `json:"user_id"`
-	IsAi      bool   `json:"is_AI"`
-	Text      string `json:"message"`
-	CreatedAt string `json:"sent_at"`
+	Id             int    `json:"id"`
+	User_id        string `json:"user_id"`
+	ConversationId int    `json:"conversation_id,omitempty"`
+	IsAi           bool   `json:"is_AI"`
+	Text           string `json:"message"`
+	CreatedAt      string `json:"sent_at"`
+	// PromptTemplateId is the system prompt version that produced an AI
+	// message, 0 for the built-in default.
+	PromptTemplateId int `json:"prompt_template_id,omitempty"`
+	// Model is the model that wrote an AI message.
+	Model string `json:"model,omitempty"`
 }
 type NewMessage struct {
-	Request string `json:"message"`
+	Request        string `json:"message"`
+	ConversationId int    `json:"conversation_id,omitempty"`
 }
 type Response struct {
 	Response string `json:"request"`
 }
+
+type Conversation struct {
+	Id        int       `json:"id"`
+	UserId    int       `json:"user_id"`
+	Title     string    `json:"title"`
+	CreatedAt string    `json:"created_at"`
+	UpdatedAt string    `json:"updated_at"`
+	Messages  []Message `json:"messages,omitempty"`
+}
+type NewConversation struct {
+	Title string `json:"title"`
+}
+
+// ConversationSummary condenses the messages of a conversation up to and
+// including UpToMessageId. Requests replay it with only the messages after
+// it.
+type ConversationSummary struct {
+	ConversationId int    `json:"conversation_id"`
+	Summary        string `json:"summary"`
+	UpToMessageId  int    `json:"up_to_message_id"`
+	// Messages is the number of messages it condenses.
+	Messages  int    `json:"messages"`
+	Model     string `json:"model,omitempty"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// SummaryPolicy says when older chat history is condensed: once the turns
+// since the last summary reach AfterTurns or a request costs AfterTokens
+// prompt tokens. The last KeepTurns turns are always replayed as they are.
+// A zero trigger is off.
+type SummaryPolicy struct {
+	AfterTurns  int
+	AfterTokens int
+	KeepTurns   int
+}
+
+// ChatReply is what the chat endpoints return for a single turn.
+type ChatReply struct {
+	ConversationId int         `json:"conversation_id,omitempty"`
+	Response       string      `json:"response"`
+	FinishReason   string      `json:"finish_reason,omitempty"`
+	Usage          *TokenUsage `json:"usage,omitempty"`
+	// PromptTemplateId is the system prompt version used, 0 for the
+	// built-in default.
+	PromptTemplateId int             `json:"prompt_template_id,omitempty"`
+	Disclaimer       string          `json:"disclaimer"`
+	UrgentReferral   *UrgentReferral `json:"urgent_referral,omitempty"`
+	// Cached is set when the answer was served from the analysis cache.
+	Cached bool `json:"cached,omitempty"`
+	// Drugs are the catalog drugs the answer cites as [drug:ID].
+	Drugs []DrugCitation `json:"drugs,omitempty"`
+	// Language the answer was asked for in: en, ru, uz or uz-Cyrl.
+	Language string `json:"language"`
+}
+
+// AIUnavailable is the 503 body of the AI endpoints when no model could
+// answer; the Retry-After header carries the same RetryAfter.
+type AIUnavailable struct {
+	Error string `json:"error"`
+	// RetryAfter is in seconds.
+	RetryAfter int `json:"retry_after"`
+}
+
+type TokenUsage struct {
+	PromptTokens    int32 `json:"prompt_tokens"`
+	CandidateTokens int32 `json:"candidate_tokens"`
+	TotalTokens     int32 `json:"total_tokens"`
+}
+
+// Caller identifies who is talking to the assistant: a registered user
+// (UserID > 0) or a guest (GuestID set, nothing persisted), and the
+// languages they may be answered in. Locale is the language asked for
+// explicitly; without it the user's profile decides, then Languages.
+type Caller struct {
+	UserID  int
+	GuestID string
+	Locale  string
+	// Languages are the supported ones of Accept-Language, most preferred
+	// first.
+	Languages []string
+	// SkipProfile keeps the user's profile out of the request to the
+	// model for this call only.
+	SkipProfile bool
+}
+
+func (c Caller) IsRegistered() bool {
+	return c.UserID > 0
+}
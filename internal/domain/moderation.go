@@ -0,0 +1,51 @@
+package domain
+
+// Reasons the moderation stage rejects a request for, returned to the client
+// as ModerationRejection.Reason.
+const (
+	RejectTooLong   = "too_long"
+	RejectInjection = "prompt_injection"
+	RejectOffTopic  = "off_topic"
+	RejectNotSkin   = "not_skin_image"
+)
+
+// ModerationRejection is the 422 body of the AI endpoints when the
+// moderation stage turned the request away before it reached the model. It
+// is also the error the use cases return.
+type ModerationRejection struct {
+	Error  string `json:"error"`
+	Reason string `json:"reason" example:"off_topic"`
+}
+
+// RejectionError is returned by the moderation stage; the controllers answer
+// it with its Rejection.
+type RejectionError struct {
+	Rejection ModerationRejection
+}
+
+func (e *RejectionError) Error() string {
+	return e.Rejection.Reason + ": " + e.Rejection.Error
+}
+
+// Reject builds the error for reason with the message shown to the patient.
+func Reject(reason, message string) error {
+	return &RejectionError{Rejection: ModerationRejection{Error: message, Reason: reason}}
+}
+
+// AIRejection is one request turned away by the moderation stage. Exactly
+// one of UserId and GuestId is set when the caller is known.
+type AIRejection struct {
+	UserId   int
+	GuestId  string
+	Endpoint string
+	Reason   string
+}
+
+// RejectionConsumer is a user or guest with how often they were rejected,
+// in total and per reason.
+type RejectionConsumer struct {
+	UserId     int              `json:"user_id,omitempty"`
+	GuestId    string           `json:"guest_id,omitempty"`
+	Rejections int64            `json:"rejections"`
+	ByReason   map[string]int64 `json:"by_reason"`
+}
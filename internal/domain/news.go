@@ -18,4 +18,22 @@ type NewWithSinglePhoto struct {
 	CreatedAt string `json:"created_at"`
 	Source    string `json:"source"`
 	Category string `json:"category"`
-}
\ No newline at end of file
+	// Summary is missing until the article has been summarised in the
+	// caller's language, which happens in the background.
+	Summary *NewsSummary `json:"summary,omitempty"`
+}
+// NewsSummary is an article rewritten for patients by the model in one
+// language. It is stored with the hash of the text it was written from, so
+// an article whose text changed under the same ID is summarised again.
+type NewsSummary struct {
+	ArticleId string   `json:"-"`
+	Language  string   `json:"language" example:"uz"`
+	Summary   string   `json:"summary"`
+	Takeaways []string `json:"takeaways"`
+	// Relevance is how much the article matters for skin health, from 0
+	// (not at all) to 10.
+	Relevance  int    `json:"relevance" example:"7"`
+	SourceHash string `json:"-"`
+	Model      string `json:"model,omitempty"`
+	CreatedAt  string `json:"created_at"`
+}
@@ -0,0 +1,78 @@
+package domain
+
+// Names of the prompts the assistant uses. Each can have versions per
+// locale in prompt_templates; without an active version the built-in
+// default is used.
+const (
+	PromptSystem     = "system"
+	PromptImage      = "image"
+	PromptAnalysis   = "analysis"
+	PromptComparison = "comparison"
+)
+
+var PromptNames = []string{PromptSystem, PromptImage, PromptAnalysis, PromptComparison}
+
+const DefaultLocale = "en"
+
+// PromptTemplate is one version of a prompt. Body is a text/template filled
+// with PromptData.
+type PromptTemplate struct {
+	Id          int    `json:"id"`
+	Name        string `json:"name"`
+	Locale      string `json:"locale"`
+	Version     int    `json:"version"`
+	Body        string `json:"body"`
+	IsActive    bool   `json:"is_active"`
+	CreatedAt   string `json:"created_at"`
+	PublishedAt string `json:"published_at,omitempty"`
+}
+
+type NewPromptTemplate struct {
+	Name   string `json:"name" example:"system"`
+	Locale string `json:"locale" example:"en"`
+	Body   string `json:"body" example:"You are a dermatology assistant. {{if .Firstname}}The patient is {{.Firstname}}.{{end}}"`
+}
+
+type PromptRollback struct {
+	Name   string `json:"name" example:"system"`
+	Locale string `json:"locale" example:"en"`
+}
+
+// PromptData are the variables available to a template. They are empty for
+// guests and for users without a profile, except Locale: the language the
+// answer is written in.
+type PromptData struct {
+	Registered bool
+	Firstname  string
+	Lastname   string
+	Gender     string
+	Age        int
+	SkinType   int
+	SkinColor  int
+	Conditions string
+	Allergies  string
+	Locale     string
+}
+
+// RenderedPrompt is a template filled for one caller. TemplateId is 0 when
+// the built-in default was used. Language is the one resolved for the
+// caller; the system prompt asks the model to answer in it.
+type RenderedPrompt struct {
+	TemplateId int
+	Text       string
+	Language   string
+}
+
+// Profile is what the assistant knows about a registered user.
+type Profile struct {
+	UserId     int
+	Firstname  string
+	Lastname   string
+	Gender     string
+	SkinType   int
+	SkinColor  int
+	Birth      string
+	Conditions string
+	Allergies  string
+	Language   string
+}
@@ -0,0 +1,44 @@
+package domain
+
+// A quiz draft is generated from a fact by the model and waits for an
+// editor; only approved drafts become questions of the fact.
+const (
+	QuizDraftPending  = "draft"
+	QuizDraftApproved = "approved"
+	QuizDraftRejected = "rejected"
+)
+
+var QuizDraftStatuses = []string{QuizDraftPending, QuizDraftApproved, QuizDraftRejected}
+
+type QuizChoice struct {
+	Content string `json:"content"`
+	IsTrue  bool   `json:"is_true"`
+}
+
+type QuizQuestion struct {
+	Question string       `json:"question"`
+	Choices  []QuizChoice `json:"choices"`
+}
+
+// QuizDraft holds generated multiple-choice questions for a fact until an
+// editor approves or rejects them.
+type QuizDraft struct {
+	Id         int            `json:"id"`
+	FactId     int            `json:"fact_id"`
+	Status     string         `json:"status" example:"draft"`
+	Questions  []QuizQuestion `json:"questions"`
+	Model      string         `json:"model,omitempty"`
+	CreatedAt  string         `json:"created_at"`
+	ReviewedAt string         `json:"reviewed_at,omitempty"`
+}
+
+type NewQuizDraft struct {
+	FactId int `json:"fact_id" example:"3"`
+	// Count is the number of questions, 5 when left out.
+	Count int `json:"count,omitempty" example:"5"`
+}
+
+// QuizDraftEdit replaces the questions of a draft before it is approved.
+type QuizDraftEdit struct {
+	Questions []QuizQuestion `json:"questions"`
+}
@@ -0,0 +1,8 @@
+package domain
+
+// Report is an exported document, ready to be downloaded.
+type Report struct {
+	Filename string
+	MIMEType string
+	Data     []byte
+}
@@ -0,0 +1,53 @@
+package domain
+
+import "strings"
+
+// Red flags the safety layer looks for in what the patient wrote and in what
+// the model answered. The first five are the ABCDE signs of melanoma.
+const (
+	RedFlagAsymmetry    = "asymmetry"
+	RedFlagBorder       = "irregular_border"
+	RedFlagColour       = "colour_variation"
+	RedFlagDiameter     = "large_diameter"
+	RedFlagEvolving     = "evolving"
+	RedFlagRapidGrowth  = "rapid_growth"
+	RedFlagBleeding     = "bleeding"
+	RedFlagInfection    = "infection"
+	RedFlagMelanoma     = "suspected_melanoma"
+	RedFlagModelUrgency = "urgent_assessment"
+)
+
+// Every AI answer carries one of these; the urgent one when a red flag was
+// found.
+const (
+	DisclaimerStandard   = "This is general information, not a diagnosis. Please see a doctor for any skin concern."
+	DisclaimerUrgentCare = "Some of what was described can be a sign of a serious condition such as melanoma or an infection. " +
+		"This is not a diagnosis: please see a dermatologist as soon as possible, or emergency services if you feel unwell."
+)
+
+// SafetyReview is the verdict of the safety layer on one exchange.
+type SafetyReview struct {
+	RedFlags       []string
+	Disclaimer     string
+	UrgentReferral *UrgentReferral
+}
+
+// Apply attaches the verdict to a chat reply. An urgent disclaimer is also
+// appended to the answer itself so clients that only show the text still
+// show it.
+func (r *SafetyReview) Apply(reply *ChatReply) *ChatReply {
+	reply.Disclaimer = r.Disclaimer
+	reply.UrgentReferral = r.UrgentReferral
+	if r.UrgentReferral != nil {
+		reply.Response = strings.TrimSpace(reply.Response) + "\n\n" + r.Disclaimer
+	}
+	return reply
+}
+
+// UrgentReferral is attached to answers with red flags and lists the
+// dermatologists the patient can contact.
+type UrgentReferral struct {
+	Reason   string            `json:"reason"`
+	RedFlags []string          `json:"red_flags"`
+	Doctors  []*DoctorWithType `json:"doctors"`
+}
@@ -0,0 +1,25 @@
+package domain
+
+// SkinRecord is one saved photo of a tracked body location together with
+// the analysis made when it was uploaded.
+type SkinRecord struct {
+	Id        int           `json:"id"`
+	UserId    int           `json:"user_id"`
+	Location  string        `json:"location"`
+	Note      string        `json:"note,omitempty"`
+	ImageURL  string        `json:"image_url"`
+	Analysis  *SkinAnalysis `json:"analysis"`
+	CreatedAt string        `json:"created_at"`
+
+	ImagePath string `json:"-"`
+	MIMEType  string `json:"-"`
+	// Image is only loaded when the record is given to the model as context.
+	Image []byte `json:"-"`
+}
+
+// SkinLocation summarises the records of one tracked location.
+type SkinLocation struct {
+	Location     string `json:"location"`
+	Records      int    `json:"records"`
+	LastRecordAt string `json:"last_record_at"`
+}
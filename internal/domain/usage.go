@@ -0,0 +1,62 @@
+package domain
+
+// AIUsage is one recorded model call. Exactly one of UserId and GuestId is
+// set for calls made on behalf of someone; both are empty for background
+// work.
+type AIUsage struct {
+	UserId          int
+	GuestId         string
+	Endpoint        string
+	Provider        string
+	Model           string
+	PromptTokens    int32
+	CandidateTokens int32
+	TotalTokens     int32
+	LatencyMs       int64
+	Failed          bool
+}
+
+// UsageTotals aggregates a set of calls. CostUSD is estimated from the
+// configured per-million-token prices.
+type UsageTotals struct {
+	Calls           int64   `json:"calls"`
+	Failed          int64   `json:"failed"`
+	PromptTokens    int64   `json:"prompt_tokens"`
+	CandidateTokens int64   `json:"candidate_tokens"`
+	TotalTokens     int64   `json:"total_tokens"`
+	AvgLatencyMs    float64 `json:"avg_latency_ms"`
+	CostUSD         float64 `json:"cost_usd"`
+}
+
+type UsageDay struct {
+	Day string `json:"day" example:"2024-05-01"`
+	UsageTotals
+}
+
+// UsageConsumer is a user or guest with what they consumed.
+type UsageConsumer struct {
+	UserId  int    `json:"user_id,omitempty"`
+	GuestId string `json:"guest_id,omitempty"`
+	UsageTotals
+}
+
+type UsageBreakdown struct {
+	Endpoint string `json:"endpoint"`
+	Model    string `json:"model"`
+	UsageTotals
+}
+
+// UsageSummary is what one user or guest consumed, split by endpoint and
+// model.
+type UsageSummary struct {
+	UsageConsumer
+	ByEndpoint []*UsageBreakdown `json:"by_endpoint"`
+}
+
+// UsageFilter narrows the aggregates to the last Days days and, optionally,
+// to one user or guest.
+type UsageFilter struct {
+	Days    int
+	UserId  int
+	GuestId string
+}
@@ -11,4 +11,36 @@ type UserInfo struct {
 	Gender    string
 	Date      time.Time
 	UpdatedAt time.Time
+	// Conditions and Allergies are free text written by the user, e.g.
+	// "eczema, rosacea".
+	Conditions string
+	Allergies  string
+	// Language the assistant answers the user in, one of Languages or
+	// empty to follow the app or browser.
+	Language string
+}
+
+// Skin types as stored in user_info.skin_type; 0 is unknown.
+var skinTypes = map[int]string{
+	1: "normal",
+	2: "dry",
+	3: "oily",
+	4: "combination",
+	5: "sensitive",
+}
+
+// SkinTypeName is the readable name of a stored skin type, empty when it is
+// unknown.
+func SkinTypeName(skinType int) string {
+	return skinTypes[skinType]
+}
+
+// SkinColorName is the Fitzpatrick phototype of user_info.skin_color (1 to
+// 6), empty when it is unknown.
+func SkinColorName(skinColor int) string {
+	numerals := []string{"I", "II", "III", "IV", "V", "VI"}
+	if skinColor < 1 || skinColor > len(numerals) {
+		return ""
+	}
+	return "Fitzpatrick type " + numerals[skinColor-1]
 }
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+type IAnalysisCacheRepository interface {
+	// Get returns a value that has not expired, with its expiry.
+	Get(ctx context.Context, key string) ([]byte, time.Time, error)
+	Put(ctx context.Context, key string, value []byte, expiresAt time.Time) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"context"
+	"testDeployment/internal/domain"
+)
+
+type IChatRepository interface {
+	CreateConversation(ctx context.Context, conversation *domain.Conversation) error
+	GetConversations(ctx context.Context, userId int) ([]*domain.Conversation, error)
+	GetConversation(ctx context.Context, userId int, id int) (*domain.Conversation, error)
+	DeleteConversation(ctx context.Context, userId int, id int) error
+	TouchConversation(ctx context.Context, id int) error
+	CreateMessage(ctx context.Context, message *domain.Message) error
+	GetMessages(ctx context.Context, conversationId int) ([]domain.Message, error)
+	// DeleteMessage deletes a message with the other half of its turn and
+	// returns the lowest id deleted.
+	DeleteMessage(ctx context.Context, conversationId int, id int) (int, error)
+	GetSummary(ctx context.Context, conversationId int) (*domain.ConversationSummary, error)
+	SaveSummary(ctx context.Context, summary *domain.ConversationSummary) error
+	DeleteSummary(ctx context.Context, conversationId int) error
+}
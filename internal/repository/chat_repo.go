@@ -19,7 +19,9 @@ return id,nil
 }
 func ( r repo) GetAllMessages(userId string )(messages []domain.Message,err error){
 	query:=`
-		select * from messages where user_id=$1 
+		select m.id,m.user_id,m.is_ai,m.message,m.created_at,coalesce(m.conversation_id,0) from messages m
+		left join conversations c on c.id=m.conversation_id
+		where m.user_id=$1 and c.deleted_at is null
 	`
 	rows,err:=r.db.Query(query,userId)
 	if err!=nil{
@@ -34,6 +36,7 @@ func ( r repo) GetAllMessages(userId string )(messages []domain.Message,err erro
 			&message.IsAi,
 			&message.Text,
 			&message.CreatedAt,
+			&message.ConversationId,
 		)
 		messages=append(messages, message)
 	}
@@ -6,4 +6,5 @@ import (
 type IDoctorRepository interface{
 	GetAllDoctor(ctx context.Context)([]*domain.DoctorByType,error)
 	GetById(ctx context.Context,name string) ([]*domain.DoctorWithType,error)
+	GetTypes(ctx context.Context) ([]string, error)
 }
\ No newline at end of file
@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"testDeployment/internal/domain"
+)
+
+type IDrugRepository interface {
+	Get(ctx context.Context, id int) (*domain.Drug, error)
+	// Search returns up to limit drugs matching the to_tsquery expression
+	// query over name, type and description, best match first.
+	Search(ctx context.Context, query string, limit int) ([]*domain.Drug, error)
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"testDeployment/internal/domain"
+)
+
+type IEmailVerificationRepository interface {
+	// Save replaces the pending code of the user and resets its attempts.
+	Save(ctx context.Context, verification *domain.EmailVerification) error
+	Get(ctx context.Context, userId int) (*domain.EmailVerification, error)
+	// AddAttempt counts a guess. It reports false when the code has no
+	// attempts left.
+	AddAttempt(ctx context.Context, userId, maxAttempts int) (bool, error)
+	Delete(ctx context.Context, userId int) error
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"testDeployment/internal/delivery/dto"
+	"testDeployment/internal/domain"
 )
 
 type IFactRepository interface {
@@ -27,4 +28,11 @@ type IFactRepository interface {
 	GetChoices(ctx context.Context, id int) ([]dto.Choices, error)
 	UpdatePoint(ctx context.Context, id int) (int, error)
 	UpdateImage(ctx context.Context, id int, path string) error
+	GetFact(ctx context.Context, id int) (*dto.Fact, error)
+	UpdateQuestionCount(ctx context.Context, id int) error
+	CreateQuizDraft(ctx context.Context, draft *domain.QuizDraft) error
+	GetQuizDraft(ctx context.Context, id int) (*domain.QuizDraft, error)
+	GetQuizDrafts(ctx context.Context, status string, factId int) ([]*domain.QuizDraft, error)
+	EditQuizDraft(ctx context.Context, id int, questions []domain.QuizQuestion) (*domain.QuizDraft, error)
+	SetQuizDraftStatus(ctx context.Context, id int, from, to string) (*domain.QuizDraft, error)
 }
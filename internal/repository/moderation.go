@@ -0,0 +1,11 @@
+package repository
+
+import (
+	"context"
+	"testDeployment/internal/domain"
+)
+
+type IModerationRepository interface {
+	Create(ctx context.Context, rejection *domain.AIRejection) error
+	GetTopRejected(ctx context.Context, filter domain.UsageFilter, limit int) ([]*domain.RejectionConsumer, error)
+}
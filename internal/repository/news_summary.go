@@ -0,0 +1,13 @@
+package repository
+
+import (
+	"context"
+	"testDeployment/internal/domain"
+)
+
+type INewsSummaryRepository interface {
+	// GetSummaries returns the stored summaries in language of the articles
+	// with the given IDs, keyed by article ID.
+	GetSummaries(ctx context.Context, language string, articleIds []string) (map[string]*domain.NewsSummary, error)
+	SaveSummary(ctx context.Context, summary *domain.NewsSummary) error
+}
@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/repository"
+	"testDeployment/pkg/Bot"
+	"time"
+)
+
+type analysisCache struct {
+	db  *sql.DB
+	bot Bot.Bot
+}
+
+func NewAnalysisCacheRepository(db *sql.DB, bot Bot.Bot) repository.IAnalysisCacheRepository {
+	return &analysisCache{
+		db:  db,
+		bot: bot,
+	}
+}
+
+func (r *analysisCache) Get(ctx context.Context, key string) ([]byte, time.Time, error) {
+	var (
+		value     []byte
+		expiresAt time.Time
+	)
+	err := r.db.QueryRowContext(ctx, getAnalysisCache, key).Scan(&value, &expiresAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, time.Time{}, domain.ErrCacheMiss
+		}
+		r.bot.SendErrorNotification(err)
+		return nil, time.Time{}, err
+	}
+	return value, expiresAt, nil
+}
+
+func (r *analysisCache) Put(ctx context.Context, key string, value []byte, expiresAt time.Time) error {
+	if _, err := r.db.ExecContext(ctx, putAnalysisCache, key, value, expiresAt); err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	return nil
+}
+
+func (r *analysisCache) DeleteExpired(ctx context.Context) (int64, error) {
+	res, err := r.db.ExecContext(ctx, deleteExpiredAnalysisCache)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,10 @@
+package postgres
+
+const (
+	getAnalysisCache = `select value,expires_at from analysis_cache
+where key=$1 and expires_at > now()`
+	putAnalysisCache = `insert into analysis_cache(key,value,expires_at)
+values($1,$2,$3)
+on conflict (key) do update set value=excluded.value,created_at=now(),expires_at=excluded.expires_at`
+	deleteExpiredAnalysisCache = `delete from analysis_cache where expires_at <= now()`
+)
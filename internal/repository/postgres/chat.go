@@ -0,0 +1,231 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/repository"
+	"testDeployment/pkg/Bot"
+)
+
+type chat struct {
+	db  *sql.DB
+	bot Bot.Bot
+}
+
+func NewChatRepository(db *sql.DB, bot Bot.Bot) repository.IChatRepository {
+	return &chat{
+		db:  db,
+		bot: bot,
+	}
+}
+
+func (r *chat) CreateConversation(ctx context.Context, conversation *domain.Conversation) error {
+	err := r.db.QueryRowContext(
+		ctx,
+		createConversation,
+		conversation.UserId,
+		conversation.Title,
+	).Scan(
+		&conversation.Id,
+		&conversation.CreatedAt,
+		&conversation.UpdatedAt,
+	)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	return nil
+}
+
+func (r *chat) GetConversations(ctx context.Context, userId int) ([]*domain.Conversation, error) {
+	rows, err := r.db.QueryContext(ctx, getConversations, userId)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	conversations := []*domain.Conversation{}
+	for rows.Next() {
+		conversation := &domain.Conversation{}
+		err := rows.Scan(
+			&conversation.Id,
+			&conversation.UserId,
+			&conversation.Title,
+			&conversation.CreatedAt,
+			&conversation.UpdatedAt,
+		)
+		if err != nil {
+			r.bot.SendErrorNotification(err)
+			return nil, err
+		}
+		conversations = append(conversations, conversation)
+	}
+	return conversations, nil
+}
+
+func (r *chat) GetConversation(ctx context.Context, userId int, id int) (*domain.Conversation, error) {
+	conversation := &domain.Conversation{}
+	err := r.db.QueryRowContext(ctx, getConversation, id, userId).Scan(
+		&conversation.Id,
+		&conversation.UserId,
+		&conversation.Title,
+		&conversation.CreatedAt,
+		&conversation.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrConversationNotFound
+		}
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	return conversation, nil
+}
+
+func (r *chat) DeleteConversation(ctx context.Context, userId int, id int) error {
+	res, err := r.db.ExecContext(ctx, deleteConversation, id, userId)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrConversationNotFound
+	}
+	return nil
+}
+
+func (r *chat) TouchConversation(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, touchConversation, id)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	return nil
+}
+
+func (r *chat) CreateMessage(ctx context.Context, message *domain.Message) error {
+	err := r.db.QueryRowContext(
+		ctx,
+		createMessage,
+		message.User_id,
+		message.IsAi,
+		message.Text,
+		message.CreatedAt,
+		message.ConversationId,
+		message.PromptTemplateId,
+		message.Model,
+	).Scan(&message.Id)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	return nil
+}
+
+func (r *chat) GetMessages(ctx context.Context, conversationId int) ([]domain.Message, error) {
+	rows, err := r.db.QueryContext(ctx, getMessages, conversationId)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []domain.Message
+	for rows.Next() {
+		var message domain.Message
+		err := rows.Scan(
+			&message.Id,
+			&message.User_id,
+			&message.IsAi,
+			&message.Text,
+			&message.CreatedAt,
+			&message.ConversationId,
+			&message.PromptTemplateId,
+			&message.Model,
+		)
+		if err != nil {
+			r.bot.SendErrorNotification(err)
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+func (r *chat) DeleteMessage(ctx context.Context, conversationId int, id int) (int, error) {
+	rows, err := r.db.QueryContext(ctx, deleteMessage, id, conversationId)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return 0, err
+	}
+	defer rows.Close()
+	first := 0
+	for rows.Next() {
+		var deleted int
+		if err := rows.Scan(&deleted); err != nil {
+			r.bot.SendErrorNotification(err)
+			return 0, err
+		}
+		if first == 0 || deleted < first {
+			first = deleted
+		}
+	}
+	if err := rows.Err(); err != nil {
+		r.bot.SendErrorNotification(err)
+		return 0, err
+	}
+	if first == 0 {
+		return 0, domain.ErrMessageNotFound
+	}
+	return first, nil
+}
+
+func (r *chat) GetSummary(ctx context.Context, conversationId int) (*domain.ConversationSummary, error) {
+	summary := &domain.ConversationSummary{}
+	err := r.db.QueryRowContext(ctx, getSummary, conversationId).Scan(
+		&summary.ConversationId,
+		&summary.Summary,
+		&summary.UpToMessageId,
+		&summary.Messages,
+		&summary.Model,
+		&summary.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrSummaryNotFound
+		}
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	return summary, nil
+}
+
+// SaveSummary stores summary unless the stored one already reaches as far
+// or a message it condenses has been deleted since.
+func (r *chat) SaveSummary(ctx context.Context, summary *domain.ConversationSummary) error {
+	err := r.db.QueryRowContext(
+		ctx,
+		saveSummary,
+		summary.ConversationId,
+		summary.Summary,
+		summary.UpToMessageId,
+		summary.Messages,
+		summary.Model,
+	).Scan(&summary.UpdatedAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	return nil
+}
+
+func (r *chat) DeleteSummary(ctx context.Context, conversationId int) error {
+	if _, err := r.db.ExecContext(ctx, deleteSummary, conversationId); err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package postgres
+
+const (
+	createConversation = `insert into conversations(user_id,title) values($1,$2) returning id,created_at,updated_at`
+	getConversations   = `select id,user_id,title,created_at,updated_at from conversations
+where user_id=$1 and deleted_at is null
+order by updated_at desc`
+	getConversation = `select id,user_id,title,created_at,updated_at from conversations
+where id=$1 and user_id=$2 and deleted_at is null`
+	deleteConversation = `update conversations set deleted_at=current_timestamp where id=$1 and user_id=$2 and deleted_at is null`
+	touchConversation  = `update conversations set updated_at=current_timestamp where id=$1`
+	createMessage      = `insert into messages(user_id,is_ai,message,created_at,conversation_id,prompt_template_id,model) values($1,$2,$3,$4,$5,nullif($6,0),$7) returning id`
+	getMessages        = `select id,user_id,is_ai,message,created_at,conversation_id,coalesce(prompt_template_id,0),model from messages
+where conversation_id=$1
+order by id`
+	// A request goes with the answer right after it and an answer with the
+	// request right before it, so the history keeps alternating.
+	deleteMessage = `with target as (
+	select id,is_ai from messages where id=$1 and conversation_id=$2
+), pair as (
+	select m.id from messages m join target t on m.is_ai<>t.is_ai
+	where m.conversation_id=$2 and m.id=(
+		select case when t.is_ai then max(o.id) else min(o.id) end from messages o
+		where o.conversation_id=$2 and ((t.is_ai and o.id<t.id) or (not t.is_ai and o.id>t.id)))
+)
+delete from messages where conversation_id=$2 and id in (select id from target union select id from pair)
+returning id`
+	getSummary = `select conversation_id,summary,up_to_message_id,messages,model,updated_at from conversation_summaries
+where conversation_id=$1`
+	// A summary is only replaced by one that reaches further, so a slow
+	// summarisation cannot overwrite a newer one. It is only saved while
+	// every message it condenses is still there, so a summarisation that
+	// was running when one of them was deleted cannot bring it back.
+	saveSummary = `insert into conversation_summaries(conversation_id,summary,up_to_message_id,messages,model)
+select $1::int,$2::text,$3::int,$4::int,$5::varchar
+where (select count(*) from messages where conversation_id=$1::int and id<=$3::int)=$4::int
+on conflict (conversation_id) do update
+set summary=excluded.summary,up_to_message_id=excluded.up_to_message_id,messages=excluded.messages,
+model=excluded.model,updated_at=current_timestamp
+where conversation_summaries.up_to_message_id<excluded.up_to_message_id
+returning updated_at`
+	deleteSummary = `delete from conversation_summaries where conversation_id=$1`
+)
@@ -91,4 +91,24 @@ func (r *doctor) GetById(ctx context.Context,name string) ([]*domain.DoctorWithT
 }
 	
 	return doctors,nil
-}
\ No newline at end of file
+}
+
+func (r *doctor) GetTypes(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, GetAllTypes)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var types []string
+	for rows.Next() {
+		var tip string
+		if err := rows.Scan(&tip); err != nil {
+			r.bot.SendErrorNotification(err)
+			return nil, err
+		}
+		types = append(types, tip)
+	}
+	return types, nil
+}
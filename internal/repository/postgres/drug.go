@@ -0,0 +1,98 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/repository"
+	"testDeployment/pkg/Bot"
+)
+
+type drug struct {
+	db  *sql.DB
+	bot Bot.Bot
+}
+
+func NewDrugRepository(db *sql.DB, bot Bot.Bot) repository.IDrugRepository {
+	return &drug{
+		db:  db,
+		bot: bot,
+	}
+}
+
+// Get returns the drug with its types and photos.
+func (r *drug) Get(ctx context.Context, id int) (*domain.Drug, error) {
+	d := &domain.Drug{}
+	err := r.db.QueryRowContext(ctx, getDrug, id).Scan(
+		&d.Id,
+		&d.Name,
+		&d.Description,
+		&d.Manufacturer,
+		&d.Receipt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrDrugNotFound
+		}
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	if d.Type, err = r.strings(ctx, getDrugTypes, id); err != nil {
+		return nil, err
+	}
+	if d.Photo, err = r.strings(ctx, getDrugPhotos, id); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (r *drug) Search(ctx context.Context, query string, limit int) ([]*domain.Drug, error) {
+	rows, err := r.db.QueryContext(ctx, searchDrugs, query, limit)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	drugs := []*domain.Drug{}
+	for rows.Next() {
+		var id int
+		var types string
+		d := &domain.Drug{}
+		if err := rows.Scan(&id, &d.Name, &d.Description, &d.Manufacturer, &d.Receipt, &types); err != nil {
+			r.bot.SendErrorNotification(err)
+			return nil, err
+		}
+		d.Id = strconv.Itoa(id)
+		if types != "" {
+			d.Type = strings.Split(types, "|")
+		}
+		drugs = append(drugs, d)
+	}
+	return drugs, nil
+}
+
+func (r *drug) strings(ctx context.Context, query string, id int) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := []string{}
+	for rows.Next() {
+		var value sql.NullString
+		if err := rows.Scan(&value); err != nil {
+			r.bot.SendErrorNotification(err)
+			return nil, err
+		}
+		if value.Valid {
+			values = append(values, value.String)
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,29 @@
+package postgres
+
+const (
+	getDrug = `select id,coalesce(name,''),coalesce(description,''),coalesce(manufacturer,''),coalesce(reciept,'')
+from drug where id=$1`
+	getDrugTypes  = `select type_name from type where drug_id=$1 order by type_name`
+	getDrugPhotos = `select path from photos where owner_id=$1`
+
+	// Name matches weigh more than type matches, type more than description.
+	searchDrugs = `with catalog as (
+	select d.id,coalesce(d.name,'') as name,coalesce(d.description,'') as description,
+	coalesce(d.manufacturer,'') as manufacturer,coalesce(d.reciept,'') as reciept,
+	coalesce(array_to_string(array_agg(distinct t.type_name) filter (where t.type_name is not null),'|'),'') as types
+	from drug d
+	left join type t on t.drug_id=d.id
+	group by d.id
+), ranked as (
+	select c.*,
+	setweight(to_tsvector('simple',c.name),'A') ||
+	setweight(to_tsvector('simple',replace(c.types,'|',' ')),'B') ||
+	setweight(to_tsvector('simple',c.description),'C') as document
+	from catalog c
+)
+select id,name,description,manufacturer,reciept,types
+from ranked
+where document @@ to_tsquery('simple',$1)
+order by ts_rank(document,to_tsquery('simple',$1)) desc, id
+limit $2`
+)
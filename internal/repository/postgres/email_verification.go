@@ -0,0 +1,81 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/repository"
+	"testDeployment/pkg/Bot"
+)
+
+type emailVerification struct {
+	db  *sql.DB
+	bot Bot.Bot
+}
+
+func NewEmailVerificationRepository(db *sql.DB, bot Bot.Bot) repository.IEmailVerificationRepository {
+	return &emailVerification{
+		db:  db,
+		bot: bot,
+	}
+}
+
+func (r *emailVerification) Save(ctx context.Context, verification *domain.EmailVerification) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		saveEmailVerification,
+		verification.UserId,
+		verification.Email,
+		verification.CodeHash,
+		verification.ExpiresAt,
+		verification.SentAt,
+	)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	return nil
+}
+
+func (r *emailVerification) Get(ctx context.Context, userId int) (*domain.EmailVerification, error) {
+	var verification domain.EmailVerification
+	err := r.db.QueryRowContext(ctx, getEmailVerification, userId).Scan(
+		&verification.UserId,
+		&verification.Email,
+		&verification.CodeHash,
+		&verification.Attempts,
+		&verification.ExpiresAt,
+		&verification.SentAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrNoVerificationCode
+		}
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	return &verification, nil
+}
+
+func (r *emailVerification) AddAttempt(ctx context.Context, userId, maxAttempts int) (bool, error) {
+	res, err := r.db.ExecContext(ctx, addEmailVerificationAttempt, userId, maxAttempts)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func (r *emailVerification) Delete(ctx context.Context, userId int) error {
+	if _, err := r.db.ExecContext(ctx, deleteEmailVerification, userId); err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,13 @@
+package postgres
+
+const (
+	saveEmailVerification = `insert into email_verifications(user_id,email,code_hash,attempts,expires_at,sent_at)
+values($1,$2,$3,0,$4,$5)
+on conflict (user_id) do update set email=excluded.email,code_hash=excluded.code_hash,attempts=0,
+expires_at=excluded.expires_at,sent_at=excluded.sent_at`
+	getEmailVerification = `select user_id,email,code_hash,attempts,expires_at,sent_at
+from email_verifications where user_id=$1`
+	addEmailVerificationAttempt = `update email_verifications set attempts=attempts+1
+where user_id=$1 and attempts<$2`
+	deleteEmailVerification = `delete from email_verifications where user_id=$1`
+)
@@ -20,3 +20,25 @@ LIMIT 5;`
 	UpdatePoint = `update  bonus set score =score+1 where user_id=$1 returning score`
 	UpdateImage = ` update facts set image=$2 where id=$1`
 )
+
+const (
+	quizDraftColumns = `id,fact_id,status,questions,model,created_at,reviewed_at`
+
+	getFact             = `select id,title,content,coalesce(image,''),coalesce(number_question,0) from facts where id=$1`
+	updateQuestionCount = `update facts set number_question=(select count(*) from question where fact_id=$1) where id=$1`
+
+	createQuizDraft = `insert into fact_quiz_drafts(fact_id,questions,model) values($1,$2,$3)
+returning ` + quizDraftColumns
+	getQuizDraft   = `select ` + quizDraftColumns + ` from fact_quiz_drafts where id=$1`
+	listQuizDrafts = `select ` + quizDraftColumns + ` from fact_quiz_drafts
+where ($1='' or status=$1) and ($2=0 or fact_id=$2)
+order by created_at desc`
+	// Only a pending draft can be edited or reviewed; back to pending clears
+	// reviewed_at.
+	editQuizDraft = `update fact_quiz_drafts set questions=$2 where id=$1 and status='draft'
+returning ` + quizDraftColumns
+	setQuizDraftStatus = `update fact_quiz_drafts set status=$3,
+reviewed_at=case when $3='draft' then null else current_timestamp end
+where id=$1 and status=$2
+returning ` + quizDraftColumns
+)
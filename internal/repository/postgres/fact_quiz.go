@@ -0,0 +1,144 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testDeployment/internal/delivery/dto"
+	"testDeployment/internal/domain"
+)
+
+func (r fact) GetFact(ctx context.Context, id int) (*dto.Fact, error) {
+	var fact dto.Fact
+	err := r.db.QueryRowContext(ctx, getFact, id).Scan(
+		&fact.Id,
+		&fact.Title,
+		&fact.Content,
+		&fact.Image,
+		&fact.NumberOfQuestion,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrFactNotFound
+		}
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	return &fact, nil
+}
+
+// UpdateQuestionCount sets number_question to the questions the fact has.
+func (r fact) UpdateQuestionCount(ctx context.Context, id int) error {
+	_, err := r.db.ExecContext(ctx, updateQuestionCount, id)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	return nil
+}
+
+func (r fact) CreateQuizDraft(ctx context.Context, draft *domain.QuizDraft) error {
+	questions, err := json.Marshal(draft.Questions)
+	if err != nil {
+		return err
+	}
+	created, err := scanQuizDraft(r.db.QueryRowContext(ctx, createQuizDraft, draft.FactId, questions, draft.Model))
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	*draft = *created
+	return nil
+}
+
+func (r fact) GetQuizDraft(ctx context.Context, id int) (*domain.QuizDraft, error) {
+	draft, err := scanQuizDraft(r.db.QueryRowContext(ctx, getQuizDraft, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrQuizDraftNotFound
+		}
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	return draft, nil
+}
+
+// GetQuizDrafts lists the drafts, newest first; an empty status or a zero
+// factId matches all.
+func (r fact) GetQuizDrafts(ctx context.Context, status string, factId int) ([]*domain.QuizDraft, error) {
+	rows, err := r.db.QueryContext(ctx, listQuizDrafts, status, factId)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	drafts := []*domain.QuizDraft{}
+	for rows.Next() {
+		draft, err := scanQuizDraft(rows)
+		if err != nil {
+			r.bot.SendErrorNotification(err)
+			return nil, err
+		}
+		drafts = append(drafts, draft)
+	}
+	return drafts, nil
+}
+
+// EditQuizDraft replaces the questions of a pending draft.
+func (r fact) EditQuizDraft(ctx context.Context, id int, questions []domain.QuizQuestion) (*domain.QuizDraft, error) {
+	data, err := json.Marshal(questions)
+	if err != nil {
+		return nil, err
+	}
+	return r.updateQuizDraft(ctx, id, editQuizDraft, id, data)
+}
+
+// SetQuizDraftStatus moves a draft from one status to another. It fails
+// with domain.ErrQuizDraftReviewed when the draft is not in from, so two
+// editors cannot approve it twice.
+func (r fact) SetQuizDraftStatus(ctx context.Context, id int, from, to string) (*domain.QuizDraft, error) {
+	return r.updateQuizDraft(ctx, id, setQuizDraftStatus, id, from, to)
+}
+
+func (r fact) updateQuizDraft(ctx context.Context, id int, query string, args ...interface{}) (*domain.QuizDraft, error) {
+	draft, err := scanQuizDraft(r.db.QueryRowContext(ctx, query, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		// Tell a missing draft from one in another status.
+		if _, err := r.GetQuizDraft(ctx, id); err != nil {
+			return nil, err
+		}
+		return nil, domain.ErrQuizDraftReviewed
+	}
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	return draft, nil
+}
+
+func scanQuizDraft(row scanner) (*domain.QuizDraft, error) {
+	var (
+		draft      domain.QuizDraft
+		questions  []byte
+		reviewedAt sql.NullString
+	)
+	err := row.Scan(
+		&draft.Id,
+		&draft.FactId,
+		&draft.Status,
+		&questions,
+		&draft.Model,
+		&draft.CreatedAt,
+		&reviewedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(questions, &draft.Questions); err != nil {
+		return nil, err
+	}
+	draft.ReviewedAt = reviewedAt.String
+	return &draft, nil
+}
@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/repository"
+	"testDeployment/pkg/Bot"
+)
+
+type moderation struct {
+	db  *sql.DB
+	bot Bot.Bot
+}
+
+func NewModerationRepository(db *sql.DB, bot Bot.Bot) repository.IModerationRepository {
+	return &moderation{
+		db:  db,
+		bot: bot,
+	}
+}
+
+func (r *moderation) Create(ctx context.Context, rejection *domain.AIRejection) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		createRejection,
+		rejection.UserId,
+		rejection.GuestId,
+		rejection.Endpoint,
+		rejection.Reason,
+	)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	return nil
+}
+
+func (r *moderation) GetTopRejected(ctx context.Context, filter domain.UsageFilter, limit int) ([]*domain.RejectionConsumer, error) {
+	rows, err := r.db.QueryContext(ctx, getTopRejected, append(filterArgs(filter), limit)...)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// The rows of one consumer are adjacent.
+	consumers := []*domain.RejectionConsumer{}
+	var last *domain.RejectionConsumer
+	for rows.Next() {
+		var (
+			userId  int
+			guestId string
+			reason  string
+			count   int64
+		)
+		if err := rows.Scan(&userId, &guestId, &reason, &count); err != nil {
+			r.bot.SendErrorNotification(err)
+			return nil, err
+		}
+		if last == nil || last.UserId != userId || last.GuestId != guestId {
+			last = &domain.RejectionConsumer{UserId: userId, GuestId: guestId, ByReason: make(map[string]int64)}
+			consumers = append(consumers, last)
+		}
+		last.ByReason[reason] = count
+		last.Rejections += count
+	}
+	return consumers, nil
+}
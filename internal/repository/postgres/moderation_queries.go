@@ -0,0 +1,20 @@
+package postgres
+
+// getTopRejected picks the limit ($4) users and guests rejected most often
+// and returns one row per reason for each; it shares usageFilter.
+const (
+	createRejection = `insert into ai_rejections(user_id,guest_id,endpoint,reason)
+values(nullif($1,0),nullif($2,''),$3,$4)`
+	getTopRejected = `with top as (
+select user_id,guest_id,count(*) as total from ai_rejections
+` + usageFilter + `
+and (user_id is not null or guest_id is not null)
+group by user_id,guest_id
+order by count(*) desc
+limit $4)
+select coalesce(t.user_id,0),coalesce(t.guest_id,''),r.reason,count(*) from top t
+join ai_rejections r on r.user_id is not distinct from t.user_id and r.guest_id is not distinct from t.guest_id
+where r.created_at >= current_date - ($1::int - 1)
+group by t.user_id,t.guest_id,t.total,r.reason
+order by t.total desc,t.user_id,t.guest_id,r.reason`
+)
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/repository"
+	"testDeployment/pkg/Bot"
+)
+
+type newsSummary struct {
+	db  *sql.DB
+	bot Bot.Bot
+}
+
+func NewNewsSummaryRepository(db *sql.DB, bot Bot.Bot) repository.INewsSummaryRepository {
+	return &newsSummary{
+		db:  db,
+		bot: bot,
+	}
+}
+
+func (r *newsSummary) GetSummaries(ctx context.Context, language string, articleIds []string) (map[string]*domain.NewsSummary, error) {
+	summaries := make(map[string]*domain.NewsSummary, len(articleIds))
+	if len(articleIds) == 0 {
+		return summaries, nil
+	}
+	ids, err := json.Marshal(articleIds)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.QueryContext(ctx, getNewsSummaries, language, ids)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			summary   domain.NewsSummary
+			takeaways []byte
+		)
+		err := rows.Scan(
+			&summary.ArticleId,
+			&summary.Language,
+			&summary.Summary,
+			&takeaways,
+			&summary.Relevance,
+			&summary.SourceHash,
+			&summary.Model,
+			&summary.CreatedAt,
+		)
+		if err == nil {
+			err = json.Unmarshal(takeaways, &summary.Takeaways)
+		}
+		if err != nil {
+			r.bot.SendErrorNotification(err)
+			return nil, err
+		}
+		summaries[summary.ArticleId] = &summary
+	}
+	if err := rows.Err(); err != nil {
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	return summaries, nil
+}
+
+func (r *newsSummary) SaveSummary(ctx context.Context, summary *domain.NewsSummary) error {
+	takeaways, err := json.Marshal(summary.Takeaways)
+	if err != nil {
+		return err
+	}
+	err = r.db.QueryRowContext(
+		ctx,
+		saveNewsSummary,
+		summary.ArticleId,
+		summary.Language,
+		summary.Summary,
+		takeaways,
+		summary.Relevance,
+		summary.SourceHash,
+		summary.Model,
+	).Scan(&summary.CreatedAt)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	return nil
+}
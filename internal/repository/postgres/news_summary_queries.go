@@ -0,0 +1,14 @@
+package postgres
+
+// The article IDs of getNewsSummaries are passed as a JSON array.
+const (
+	getNewsSummaries = `select article_id,language,summary,takeaways,relevance,source_hash,model,created_at
+from news_summaries
+where language=$1 and article_id in (select jsonb_array_elements_text($2::jsonb))`
+	saveNewsSummary = `insert into news_summaries(article_id,language,summary,takeaways,relevance,source_hash,model)
+values($1,$2,$3,$4,$5,$6,$7)
+on conflict (article_id,language) do update
+set summary=excluded.summary,takeaways=excluded.takeaways,relevance=excluded.relevance,
+source_hash=excluded.source_hash,model=excluded.model,created_at=current_timestamp
+returning created_at`
+)
@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/repository"
+	"testDeployment/pkg/Bot"
+)
+
+type profile struct {
+	db  *sql.DB
+	bot Bot.Bot
+}
+
+func NewProfileRepository(db *sql.DB, bot Bot.Bot) repository.IProfileRepository {
+	return &profile{
+		db:  db,
+		bot: bot,
+	}
+}
+
+// GetProfile returns domain.ErrProfileNotFound for users who have not filled
+// in their info yet, which is common and not worth a notification.
+func (r *profile) GetProfile(ctx context.Context, userId int) (*domain.Profile, error) {
+	p := &domain.Profile{}
+	err := r.db.QueryRowContext(ctx, getProfile, userId).Scan(
+		&p.UserId,
+		&p.Firstname,
+		&p.Lastname,
+		&p.Gender,
+		&p.SkinType,
+		&p.SkinColor,
+		&p.Birth,
+		&p.Conditions,
+		&p.Allergies,
+		&p.Language,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrProfileNotFound
+		}
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	return p, nil
+}
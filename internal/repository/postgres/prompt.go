@@ -0,0 +1,138 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/repository"
+	"testDeployment/pkg/Bot"
+)
+
+type prompt struct {
+	db  *sql.DB
+	bot Bot.Bot
+}
+
+func NewPromptRepository(db *sql.DB, bot Bot.Bot) repository.IPromptRepository {
+	return &prompt{
+		db:  db,
+		bot: bot,
+	}
+}
+
+// Create stores template as the next version of its name and locale. It is
+// not active until published.
+func (r *prompt) Create(ctx context.Context, template *domain.PromptTemplate) error {
+	err := r.db.QueryRowContext(
+		ctx,
+		createPrompt,
+		template.Name,
+		template.Locale,
+		template.Body,
+	).Scan(
+		&template.Id,
+		&template.Version,
+		&template.CreatedAt,
+	)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	return nil
+}
+
+func (r *prompt) Get(ctx context.Context, id int) (*domain.PromptTemplate, error) {
+	return r.getOne(ctx, getPrompt, id)
+}
+
+func (r *prompt) GetActive(ctx context.Context, name, locale string) (*domain.PromptTemplate, error) {
+	return r.getOne(ctx, getActivePrompt, name, locale)
+}
+
+func (r *prompt) GetPreviousPublished(ctx context.Context, name, locale string) (*domain.PromptTemplate, error) {
+	return r.getOne(ctx, getPreviousPublishedPrompt, name, locale)
+}
+
+func (r *prompt) getOne(ctx context.Context, query string, args ...interface{}) (*domain.PromptTemplate, error) {
+	template, err := scanPrompt(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrPromptNotFound
+		}
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	return template, nil
+}
+
+func (r *prompt) List(ctx context.Context, name, locale string) ([]*domain.PromptTemplate, error) {
+	rows, err := r.db.QueryContext(ctx, listPrompts, name, locale)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []*domain.PromptTemplate{}
+	for rows.Next() {
+		template, err := scanPrompt(rows)
+		if err != nil {
+			r.bot.SendErrorNotification(err)
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+	return templates, nil
+}
+
+// Activate makes id the active version of its name and locale. publish
+// stamps published_at; rollbacks keep the original stamp.
+func (r *prompt) Activate(ctx context.Context, id int, publish bool) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, deactivatePrompts, id); err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	res, err := tx.ExecContext(ctx, activatePrompt, id, publish)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrPromptNotFound
+	}
+	if err := tx.Commit(); err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	return nil
+}
+
+func scanPrompt(row scanner) (*domain.PromptTemplate, error) {
+	var (
+		template    domain.PromptTemplate
+		publishedAt sql.NullString
+	)
+	err := row.Scan(
+		&template.Id,
+		&template.Name,
+		&template.Locale,
+		&template.Version,
+		&template.Body,
+		&template.IsActive,
+		&template.CreatedAt,
+		&publishedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	template.PublishedAt = publishedAt.String
+	return &template, nil
+}
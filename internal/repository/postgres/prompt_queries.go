@@ -0,0 +1,33 @@
+package postgres
+
+const (
+	promptColumns = `id,name,locale,version,body,is_active,created_at,published_at`
+
+	createPrompt = `insert into prompt_templates(name,locale,version,body)
+select $1,$2,coalesce(max(version),0)+1,$3 from prompt_templates where name=$1 and locale=$2
+returning id,version,created_at`
+	getPrompt       = `select ` + promptColumns + ` from prompt_templates where id=$1`
+	getActivePrompt = `select ` + promptColumns + ` from prompt_templates where name=$1 and locale=$2 and is_active`
+	// The version published before the active one; rollbacks do not move
+	// published_at, so repeated rollbacks walk further back.
+	getPreviousPublishedPrompt = `select ` + promptColumns + ` from prompt_templates
+where name=$1 and locale=$2 and not is_active and published_at is not null
+and published_at < coalesce((select published_at from prompt_templates where name=$1 and locale=$2 and is_active), 'infinity')
+order by published_at desc
+limit 1`
+	listPrompts = `select ` + promptColumns + ` from prompt_templates
+where ($1='' or name=$1) and ($2='' or locale=$2)
+order by name, locale, version desc`
+	deactivatePrompts = `update prompt_templates set is_active=false
+where is_active and (name,locale)=(select name,locale from prompt_templates where id=$1)`
+	activatePrompt = `update prompt_templates set is_active=true,
+published_at=case when $2 then current_timestamp else published_at end
+where id=$1`
+
+	getProfile = `select user_id,coalesce(firstname,''),coalesce(lastname,''),coalesce(gender,''),
+coalesce(skin_type,0),coalesce(skin_color,0),coalesce(to_char(birth,'YYYY-MM-DD'),''),
+conditions,allergies,language
+from user_info where user_id=$1
+order by id desc
+limit 1`
+)
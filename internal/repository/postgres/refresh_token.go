@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/repository"
+	"testDeployment/pkg/Bot"
+)
+
+type refreshToken struct {
+	db  *sql.DB
+	bot Bot.Bot
+}
+
+func NewRefreshTokenRepository(db *sql.DB, bot Bot.Bot) repository.IRefreshTokenRepository {
+	return &refreshToken{
+		db:  db,
+		bot: bot,
+	}
+}
+
+func (r *refreshToken) Create(ctx context.Context, token *domain.RefreshToken) error {
+	err := r.db.QueryRowContext(
+		ctx,
+		createRefreshToken,
+		token.UserId,
+		token.Role,
+		token.FamilyId,
+		token.TokenHash,
+		token.ExpiresAt,
+	).Scan(&token.Id)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	return nil
+}
+
+func (r *refreshToken) GetByHash(ctx context.Context, hash string) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	err := r.db.QueryRowContext(ctx, getRefreshToken, hash).Scan(
+		&token.Id,
+		&token.UserId,
+		&token.Role,
+		&token.FamilyId,
+		&token.TokenHash,
+		&token.ExpiresAt,
+		&token.UsedAt,
+		&token.RevokedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrInvalidRefreshToken
+		}
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *refreshToken) Use(ctx context.Context, id int) (bool, error) {
+	res, err := r.db.ExecContext(ctx, useRefreshToken, id)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return false, err
+	}
+	return n == 1, nil
+}
+
+func (r *refreshToken) RevokeFamily(ctx context.Context, familyId string) error {
+	if _, err := r.db.ExecContext(ctx, revokeRefreshFamily, familyId); err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	return nil
+}
+
+func (r *refreshToken) RevokeUser(ctx context.Context, userId int) error {
+	if _, err := r.db.ExecContext(ctx, revokeRefreshUser, userId); err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	return nil
+}
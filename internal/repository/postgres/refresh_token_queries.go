@@ -0,0 +1,14 @@
+package postgres
+
+const (
+	createRefreshToken = `insert into refresh_tokens(user_id,role,family_id,token_hash,expires_at)
+values($1,$2,$3,$4,$5) returning id`
+	getRefreshToken = `select id,user_id,role,family_id,token_hash,expires_at,used_at,revoked_at
+from refresh_tokens where token_hash=$1`
+	useRefreshToken = `update refresh_tokens set used_at=current_timestamp
+where id=$1 and used_at is null and revoked_at is null`
+	revokeRefreshFamily = `update refresh_tokens set revoked_at=current_timestamp
+where family_id=$1 and revoked_at is null`
+	revokeRefreshUser = `update refresh_tokens set revoked_at=current_timestamp
+where user_id=$1 and revoked_at is null`
+)
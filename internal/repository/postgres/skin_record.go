@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/repository"
+	"testDeployment/pkg/Bot"
+)
+
+type skinRecord struct {
+	db  *sql.DB
+	bot Bot.Bot
+}
+
+func NewSkinRecordRepository(db *sql.DB, bot Bot.Bot) repository.ISkinRecordRepository {
+	return &skinRecord{
+		db:  db,
+		bot: bot,
+	}
+}
+
+func (r *skinRecord) Create(ctx context.Context, record *domain.SkinRecord) error {
+	analysis, err := json.Marshal(record.Analysis)
+	if err != nil {
+		return err
+	}
+	err = r.db.QueryRowContext(
+		ctx,
+		createSkinRecord,
+		record.UserId,
+		record.Location,
+		record.Note,
+		record.ImagePath,
+		record.MIMEType,
+		analysis,
+	).Scan(
+		&record.Id,
+		&record.CreatedAt,
+	)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	return nil
+}
+
+func (r *skinRecord) Get(ctx context.Context, userId int, id int) (*domain.SkinRecord, error) {
+	return r.getOne(ctx, getSkinRecord, id, userId)
+}
+
+func (r *skinRecord) GetLatest(ctx context.Context, userId int, location string) (*domain.SkinRecord, error) {
+	return r.getOne(ctx, getLatestSkinRecord, userId, location)
+}
+
+func (r *skinRecord) getOne(ctx context.Context, query string, args ...interface{}) (*domain.SkinRecord, error) {
+	record, err := scanSkinRecord(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrSkinRecordNotFound
+		}
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	return record, nil
+}
+
+func (r *skinRecord) GetByLocation(ctx context.Context, userId int, location string) ([]*domain.SkinRecord, error) {
+	rows, err := r.db.QueryContext(ctx, getSkinRecordsByLocation, userId, location)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []*domain.SkinRecord{}
+	for rows.Next() {
+		record, err := scanSkinRecord(rows)
+		if err != nil {
+			r.bot.SendErrorNotification(err)
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func (r *skinRecord) GetLocations(ctx context.Context, userId int) ([]*domain.SkinLocation, error) {
+	rows, err := r.db.QueryContext(ctx, getSkinLocations, userId)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	locations := []*domain.SkinLocation{}
+	for rows.Next() {
+		location := &domain.SkinLocation{}
+		err := rows.Scan(
+			&location.Location,
+			&location.Records,
+			&location.LastRecordAt,
+		)
+		if err != nil {
+			r.bot.SendErrorNotification(err)
+			return nil, err
+		}
+		locations = append(locations, location)
+	}
+	return locations, nil
+}
+
+func (r *skinRecord) Delete(ctx context.Context, userId int, id int) error {
+	res, err := r.db.ExecContext(ctx, deleteSkinRecord, id, userId)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return domain.ErrSkinRecordNotFound
+	}
+	return nil
+}
+
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSkinRecord(row scanner) (*domain.SkinRecord, error) {
+	var (
+		record   domain.SkinRecord
+		analysis []byte
+	)
+	err := row.Scan(
+		&record.Id,
+		&record.UserId,
+		&record.Location,
+		&record.Note,
+		&record.ImagePath,
+		&record.MIMEType,
+		&analysis,
+		&record.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(analysis, &record.Analysis); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
@@ -0,0 +1,20 @@
+package postgres
+
+const (
+	createSkinRecord = `insert into skin_records(user_id,location,note,image_path,mime_type,analysis)
+values($1,$2,$3,$4,$5,$6) returning id,created_at`
+	getSkinRecord = `select id,user_id,location,note,image_path,mime_type,analysis,created_at from skin_records
+where id=$1 and user_id=$2`
+	getLatestSkinRecord = `select id,user_id,location,note,image_path,mime_type,analysis,created_at from skin_records
+where user_id=$1 and location=$2
+order by created_at desc, id desc
+limit 1`
+	getSkinRecordsByLocation = `select id,user_id,location,note,image_path,mime_type,analysis,created_at from skin_records
+where user_id=$1 and location=$2
+order by created_at, id`
+	getSkinLocations = `select location,count(*),max(created_at) from skin_records
+where user_id=$1
+group by location
+order by max(created_at) desc`
+	deleteSkinRecord = `delete from skin_records where id=$1 and user_id=$2`
+)
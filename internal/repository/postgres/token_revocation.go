@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/repository"
+	"testDeployment/pkg/Bot"
+	"time"
+)
+
+type tokenRevocation struct {
+	db  *sql.DB
+	bot Bot.Bot
+}
+
+func NewTokenRevocationRepository(db *sql.DB, bot Bot.Bot) repository.ITokenRevocationRepository {
+	return &tokenRevocation{
+		db:  db,
+		bot: bot,
+	}
+}
+
+func (r *tokenRevocation) RevokeToken(ctx context.Context, token domain.RevokedToken) error {
+	userId := sql.NullInt64{Int64: int64(token.UserId), Valid: token.UserId > 0}
+	if _, err := r.db.ExecContext(ctx, revokeToken, token.Jti, userId, token.ExpiresAt); err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	return nil
+}
+
+func (r *tokenRevocation) RevokeUser(ctx context.Context, revocation domain.UserRevocation) error {
+	if _, err := r.db.ExecContext(ctx, revokeUserTokens, revocation.UserId, revocation.RevokedBefore); err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	return nil
+}
+
+func (r *tokenRevocation) TokensSince(ctx context.Context, since time.Time) ([]domain.RevokedToken, error) {
+	rows, err := r.db.QueryContext(ctx, revokedTokensSince, since)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []domain.RevokedToken
+	for rows.Next() {
+		var token domain.RevokedToken
+		if err := rows.Scan(&token.Jti, &token.UserId, &token.ExpiresAt); err != nil {
+			r.bot.SendErrorNotification(err)
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+	return tokens, rows.Err()
+}
+
+func (r *tokenRevocation) UsersSince(ctx context.Context, since time.Time) ([]domain.UserRevocation, error) {
+	rows, err := r.db.QueryContext(ctx, userRevocationsSince, since)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revocations []domain.UserRevocation
+	for rows.Next() {
+		var revocation domain.UserRevocation
+		if err := rows.Scan(&revocation.UserId, &revocation.RevokedBefore); err != nil {
+			r.bot.SendErrorNotification(err)
+			return nil, err
+		}
+		revocations = append(revocations, revocation)
+	}
+	return revocations, rows.Err()
+}
+
+func (r *tokenRevocation) DeleteExpired(ctx context.Context) (int64, error) {
+	res, err := r.db.ExecContext(ctx, deleteExpiredRevokedTokens)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,13 @@
+package postgres
+
+const (
+	revokeToken = `insert into revoked_tokens(jti,user_id,expires_at) values($1,$2,$3)
+on conflict (jti) do nothing`
+	revokeUserTokens = `insert into user_token_revocations(user_id,revoked_before) values($1,$2)
+on conflict (user_id) do update set revoked_before=greatest(user_token_revocations.revoked_before,excluded.revoked_before)`
+	revokedTokensSince = `select jti,coalesce(user_id,0),expires_at from revoked_tokens
+where created_at>$1 and expires_at>current_timestamp`
+	userRevocationsSince = `select user_id,revoked_before from user_token_revocations
+where revoked_before>$1`
+	deleteExpiredRevokedTokens = `delete from revoked_tokens where expires_at<=current_timestamp`
+)
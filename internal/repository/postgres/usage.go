@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/repository"
+	"testDeployment/pkg/Bot"
+)
+
+type usage struct {
+	db  *sql.DB
+	bot Bot.Bot
+}
+
+func NewUsageRepository(db *sql.DB, bot Bot.Bot) repository.IUsageRepository {
+	return &usage{
+		db:  db,
+		bot: bot,
+	}
+}
+
+func (r *usage) Create(ctx context.Context, u *domain.AIUsage) error {
+	_, err := r.db.ExecContext(
+		ctx,
+		createUsage,
+		u.UserId,
+		u.GuestId,
+		u.Endpoint,
+		u.Provider,
+		u.Model,
+		u.PromptTokens,
+		u.CandidateTokens,
+		u.TotalTokens,
+		u.LatencyMs,
+		u.Failed,
+	)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return err
+	}
+	return nil
+}
+
+func (r *usage) GetDaily(ctx context.Context, filter domain.UsageFilter) ([]*domain.UsageDay, error) {
+	rows, err := r.db.QueryContext(ctx, getUsageDaily, filterArgs(filter)...)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	days := []*domain.UsageDay{}
+	for rows.Next() {
+		day := &domain.UsageDay{}
+		if err := rows.Scan(append([]interface{}{&day.Day}, totalsDest(&day.UsageTotals)...)...); err != nil {
+			r.bot.SendErrorNotification(err)
+			return nil, err
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+func (r *usage) GetTotals(ctx context.Context, filter domain.UsageFilter) (*domain.UsageTotals, error) {
+	totals := &domain.UsageTotals{}
+	err := r.db.QueryRowContext(ctx, getUsageTotals, filterArgs(filter)...).Scan(totalsDest(totals)...)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	return totals, nil
+}
+
+func (r *usage) GetBreakdown(ctx context.Context, filter domain.UsageFilter) ([]*domain.UsageBreakdown, error) {
+	rows, err := r.db.QueryContext(ctx, getUsageBreakdown, filterArgs(filter)...)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	breakdown := []*domain.UsageBreakdown{}
+	for rows.Next() {
+		b := &domain.UsageBreakdown{}
+		if err := rows.Scan(append([]interface{}{&b.Endpoint, &b.Model}, totalsDest(&b.UsageTotals)...)...); err != nil {
+			r.bot.SendErrorNotification(err)
+			return nil, err
+		}
+		breakdown = append(breakdown, b)
+	}
+	return breakdown, nil
+}
+
+func (r *usage) GetTopConsumers(ctx context.Context, filter domain.UsageFilter, limit int) ([]*domain.UsageConsumer, error) {
+	rows, err := r.db.QueryContext(ctx, getUsageTopConsumers, append(filterArgs(filter), limit)...)
+	if err != nil {
+		r.bot.SendErrorNotification(err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	consumers := []*domain.UsageConsumer{}
+	for rows.Next() {
+		c := &domain.UsageConsumer{}
+		if err := rows.Scan(append([]interface{}{&c.UserId, &c.GuestId}, totalsDest(&c.UsageTotals)...)...); err != nil {
+			r.bot.SendErrorNotification(err)
+			return nil, err
+		}
+		consumers = append(consumers, c)
+	}
+	return consumers, nil
+}
+
+func filterArgs(filter domain.UsageFilter) []interface{} {
+	return []interface{}{filter.Days, filter.UserId, filter.GuestId}
+}
+
+// totalsDest matches the usageTotals columns.
+func totalsDest(t *domain.UsageTotals) []interface{} {
+	return []interface{}{
+		&t.Calls,
+		&t.Failed,
+		&t.PromptTokens,
+		&t.CandidateTokens,
+		&t.TotalTokens,
+		&t.AvgLatencyMs,
+	}
+}
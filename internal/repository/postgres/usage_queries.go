@@ -0,0 +1,30 @@
+package postgres
+
+// The aggregates share the same filter: $1 is the number of days, $2 a user
+// id (0 for any) and $3 a guest id (” for any).
+const (
+	createUsage = `insert into ai_usage(user_id,guest_id,endpoint,provider,model,prompt_tokens,candidate_tokens,total_tokens,latency_ms,failed)
+values(nullif($1,0),nullif($2,''),$3,$4,$5,$6,$7,$8,$9,$10)`
+	usageTotals = `count(*),count(*) filter (where failed),
+coalesce(sum(prompt_tokens),0),coalesce(sum(candidate_tokens),0),coalesce(sum(total_tokens),0),
+coalesce(avg(latency_ms),0)`
+	usageFilter = `where created_at >= current_date - ($1::int - 1)
+and ($2::int = 0 or user_id = $2)
+and ($3::text = '' or guest_id = $3)`
+	getUsageDaily = `select to_char(created_at::date,'YYYY-MM-DD'),` + usageTotals + ` from ai_usage
+` + usageFilter + `
+group by created_at::date
+order by created_at::date desc`
+	getUsageTotals = `select ` + usageTotals + ` from ai_usage
+` + usageFilter
+	getUsageBreakdown = `select endpoint,model,` + usageTotals + ` from ai_usage
+` + usageFilter + `
+group by endpoint,model
+order by sum(total_tokens) desc`
+	getUsageTopConsumers = `select coalesce(user_id,0),coalesce(guest_id,''),` + usageTotals + ` from ai_usage
+` + usageFilter + `
+and (user_id is not null or guest_id is not null)
+group by user_id,guest_id
+order by sum(total_tokens) desc
+limit $4`
+)
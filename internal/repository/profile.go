@@ -0,0 +1,10 @@
+package repository
+
+import (
+	"context"
+	"testDeployment/internal/domain"
+)
+
+type IProfileRepository interface {
+	GetProfile(ctx context.Context, userId int) (*domain.Profile, error)
+}
@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"testDeployment/internal/domain"
+)
+
+type IPromptRepository interface {
+	Create(ctx context.Context, template *domain.PromptTemplate) error
+	Get(ctx context.Context, id int) (*domain.PromptTemplate, error)
+	GetActive(ctx context.Context, name, locale string) (*domain.PromptTemplate, error)
+	GetPreviousPublished(ctx context.Context, name, locale string) (*domain.PromptTemplate, error)
+	List(ctx context.Context, name, locale string) ([]*domain.PromptTemplate, error)
+	Activate(ctx context.Context, id int, publish bool) error
+}
@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"testDeployment/internal/domain"
+)
+
+type IRefreshTokenRepository interface {
+	Create(ctx context.Context, token *domain.RefreshToken) error
+	GetByHash(ctx context.Context, hash string) (*domain.RefreshToken, error)
+	// Use marks the token spent. It reports false when the token was spent
+	// or revoked in the meantime.
+	Use(ctx context.Context, id int) (bool, error)
+	RevokeFamily(ctx context.Context, familyId string) error
+	RevokeUser(ctx context.Context, userId int) error
+}
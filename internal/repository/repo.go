@@ -36,7 +36,10 @@ type Repo interface {
 	UpdateInfo(user domain.UserInfo) (id int, err error)
 	UpdateName(user domain.UserInfo) (id int, err error)
 	UpdateGender(user domain.UserInfo) (id int, err error)
+	UpdateHealth(user domain.UserInfo) (id int, err error)
+	UpdateLanguage(user domain.UserInfo) (id int, err error)
 	UpdateVerified(userId interface{}) (err error)
+	GetEmail(userId int) (email string, verified bool, err error)
 	InsertDrug(drug domain.Drug) (id int, err error)
 	CreatePhoto(id int, path []string) (err error)
 	GetDrugByName(name string) (drugs []domain.Drug, err error)
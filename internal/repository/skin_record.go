@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"context"
+	"testDeployment/internal/domain"
+)
+
+type ISkinRecordRepository interface {
+	Create(ctx context.Context, record *domain.SkinRecord) error
+	Get(ctx context.Context, userId int, id int) (*domain.SkinRecord, error)
+	GetLatest(ctx context.Context, userId int, location string) (*domain.SkinRecord, error)
+	GetByLocation(ctx context.Context, userId int, location string) ([]*domain.SkinRecord, error)
+	GetLocations(ctx context.Context, userId int) ([]*domain.SkinLocation, error)
+	Delete(ctx context.Context, userId int, id int) error
+}
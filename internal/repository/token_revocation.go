@@ -0,0 +1,19 @@
+package repository
+
+import (
+	"context"
+	"testDeployment/internal/domain"
+	"time"
+)
+
+type ITokenRevocationRepository interface {
+	RevokeToken(ctx context.Context, token domain.RevokedToken) error
+	// RevokeUser refuses the tokens of the user issued before before; an
+	// earlier time than the one stored is ignored.
+	RevokeUser(ctx context.Context, revocation domain.UserRevocation) error
+	// TokensSince returns the tokens revoked after since that have not
+	// expired yet.
+	TokensSince(ctx context.Context, since time.Time) ([]domain.RevokedToken, error)
+	UsersSince(ctx context.Context, since time.Time) ([]domain.UserRevocation, error)
+	DeleteExpired(ctx context.Context) (int64, error)
+}
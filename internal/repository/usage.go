@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+	"testDeployment/internal/domain"
+)
+
+type IUsageRepository interface {
+	Create(ctx context.Context, usage *domain.AIUsage) error
+	GetDaily(ctx context.Context, filter domain.UsageFilter) ([]*domain.UsageDay, error)
+	GetTotals(ctx context.Context, filter domain.UsageFilter) (*domain.UsageTotals, error)
+	GetBreakdown(ctx context.Context, filter domain.UsageFilter) ([]*domain.UsageBreakdown, error)
+	GetTopConsumers(ctx context.Context, filter domain.UsageFilter, limit int) ([]*domain.UsageConsumer, error)
+}
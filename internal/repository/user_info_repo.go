@@ -35,9 +35,9 @@ func (r repo) ExistUserInfo(userId int) (exist bool, err error) {
 }
 func (r repo) CreateInfo(user domain.UserInfo) (id int, err error) {
 	query := `
-	insert into  user_info (user_id,firstname,lastname,skin_color,skin_type,gender,created_at,birth) values($1,$2,$3,$4,$5,$6,$7,$8) RETURNING id
+	insert into  user_info (user_id,firstname,lastname,skin_color,skin_type,gender,created_at,birth,conditions,allergies,language) values($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11) RETURNING id
 `
-	row := r.db.QueryRow(query, user.Id, user.Firstname, user.Lastname, user.SkinColor, user.SkinType, user.Gender, user.UpdatedAt, user.Date)
+	row := r.db.QueryRow(query, user.Id, user.Firstname, user.Lastname, user.SkinColor, user.SkinType, user.Gender, user.UpdatedAt, user.Date, user.Conditions, user.Allergies, user.Language)
 	if err = row.Scan(&id); err != nil {
 		r.Bot.SendErrorNotification(err)
 		return 0, err
@@ -46,7 +46,7 @@ func (r repo) CreateInfo(user domain.UserInfo) (id int, err error) {
 }
 func (r repo) GetUserInfo(userId int) (user domain.UserInfo, err error) {
 	query := `
-SELECT id, firstname, lastname, skin_color, skin_type, gender, birth 
+SELECT id, firstname, lastname, skin_color, skin_type, gender, birth, conditions, allergies, language
 FROM user_info 
 WHERE user_id=$1 
 ORDER BY id DESC;
@@ -59,6 +59,9 @@ ORDER BY id DESC;
 		&user.SkinType,
 		&user.Gender,
 		&user.Date,
+		&user.Conditions,
+		&user.Allergies,
+		&user.Language,
 	)
 	if err != nil {
 		r.Bot.SendErrorNotification(err)
@@ -102,9 +105,33 @@ func (r repo) UpdateGender(user domain.UserInfo) (id int, err error) {
 	return id, nil
 }
 
+func (r repo) UpdateHealth(user domain.UserInfo) (id int, err error) {
+	query := `
+	update user_info set conditions=$2,allergies=$3,updated_at=$4 where user_id=$1 returning id
+	`
+	err = r.db.QueryRow(query, user.Id, user.Conditions, user.Allergies, user.UpdatedAt).Scan(&id)
+	if err != nil {
+		r.Bot.SendErrorNotification(err)
+		return 0, domain.ErrCouldNotScan
+	}
+	return id, nil
+}
+
+func (r repo) UpdateLanguage(user domain.UserInfo) (id int, err error) {
+	query := `
+	update user_info set language=$2,updated_at=$3 where user_id=$1 returning id
+	`
+	err = r.db.QueryRow(query, user.Id, user.Language, user.UpdatedAt).Scan(&id)
+	if err != nil {
+		r.Bot.SendErrorNotification(err)
+		return 0, domain.ErrCouldNotScan
+	}
+	return id, nil
+}
+
 func (r repo) UpdateEmail(user dto.UserEmail) (id int, err error) {
 	query := `
-	update users set email=$2 where id=$1 returning id
+	update users set email=$2, is_email_verified=(coalesce(is_email_verified, false) and email=$2) where id=$1 returning id
 	`
 	err = r.db.QueryRow(query, user.ID, user.Email).Scan(&id)
 	if err != nil {
@@ -95,6 +95,18 @@ func (r repo) UpdateVerified(userId interface{}) (err error) {
 	return nil
 }
 
+func (r repo) GetEmail(userId int) (email string, verified bool, err error) {
+	query := `
+		select email, coalesce(is_email_verified, false) from users where id=$1
+`
+	err = r.db.QueryRow(query, userId).Scan(&email, &verified)
+	if err != nil {
+		r.Bot.SendErrorNotification(err)
+		return "", false, err
+	}
+	return email, verified, nil
+}
+
 func (r repo) IsPremium(userId interface{}) (int, error) {
 	var isPremium int
 	query := `
@@ -15,9 +15,11 @@ import (
 	configs "testDeployment/internal/common/config"
 	"testDeployment/internal/delivery"
 	request "testDeployment/internal/delivery/http"
+	"testDeployment/internal/repository/postgres"
 	"testDeployment/internal/usecase"
 	"testDeployment/pkg/Bot"
 	ai2 "testDeployment/pkg/ai"
+	"testDeployment/pkg/sms"
 	"time"
 )
 
@@ -77,27 +79,85 @@ func (s Server) Run() error {
 		fmt.Println(err)
 		return err
 	}
-	uc := usecase.New(pg, NewBot)
 	conf.Instruction = os.Getenv("INSTRUCTION")
-	geminiKey := os.Getenv("GEMINI_API_KEY")
-	ai, err := ai2.NewDermato(geminiKey)
+	apiKey := conf.Ai.APIKey
+	if apiKey == "" {
+		apiKey = conf.Ai.GeminiKey
+	}
+	aiConfig := ai2.Config{
+		Provider:    conf.Ai.Provider,
+		Model:       conf.Ai.Model,
+		APIKey:      apiKey,
+		BaseURL:     conf.Ai.BaseURL,
+		Instruction: conf.Instruction,
+		Temperature: 0.7,
+		TopP:        0.95,
+		TopK:        40,
+		MaxTokens:   300,
+	}
+	ai, err := ai2.New(aiConfig)
+	if err != nil {
+		NewBot.SendErrorNotification(err)
+		fmt.Println(err)
+		return err
+	}
+	var fallback ai2.Provider
+	if conf.Ai.FallbackModel != "" {
+		fallbackConfig := aiConfig
+		fallbackConfig.Model = conf.Ai.FallbackModel
+		if conf.Ai.FallbackProvider != "" {
+			fallbackConfig.Provider = conf.Ai.FallbackProvider
+		}
+		fallback, err = ai2.New(fallbackConfig)
+		if err != nil {
+			NewBot.SendErrorNotification(err)
+			fmt.Println(err)
+			return err
+		}
+	}
+	conf.Ai.Prompt = os.Getenv("PROMPT")
+	// Every model call, from any use case or controller, is accounted to
+	// the user or guest of the request; retries and fallback calls are
+	// accounted one by one.
+	usage := usecase.NewUsageUseCase(postgres.NewUsageRepository(pg, NewBot), conf.Ai, NewBot)
+	ai = ai2.WithRecorder(ai, usage.Record)
+	if fallback != nil {
+		fallback = ai2.WithRecorder(fallback, usage.Record)
+	}
+	ai = ai2.WithResilience(ai, fallback, ai2.ResilienceOptions{
+		Attempts:         conf.Ai.Retries,
+		Timeout:          conf.Ai.Timeout,
+		FailureThreshold: conf.Ai.BreakerThreshold,
+		OpenFor:          conf.Ai.BreakerCooldown,
+		OnStateChange: func(model string, from, to ai2.BreakerState) {
+			NewBot.SendNotification(fmt.Sprintf("AI circuit breaker of `%s`: %s → %s", model, from, to))
+		},
+	})
+	mailer, err := sms.NewMailer(sms.MailConfig{
+		Provider: conf.MailProvider,
+		Host:     conf.SMTPHost,
+		Port:     conf.SMTPPort,
+		Username: conf.SMTPUsername,
+		Password: conf.SMTPPassword,
+		From:     conf.MailFrom,
+	})
 	if err != nil {
 		NewBot.SendErrorNotification(err)
 		fmt.Println(err)
 		return err
 	}
-	ai.Configure(conf.Instruction, 0.7, 0.95, 40, 300)
-	conf.Ai.Prompt=os.Getenv("PROMPT")
+	uc := usecase.New(pg, NewBot, ai, conf.Ai, conf.Cache, conf.Image, conf.Moderation, conf.News, conf.JWT, conf.Mail, mailer, usage)
 	conf.Port = os.Getenv("PORT")
 	if conf.Port == "" {
 		conf.Port = "8080"
 	}
 
 	// Inject dependencies into bot for health checks and stats
-	NewBot.SetDependencies(pg, geminiKey, conf.Port)
+	NewBot.SetDependencies(pg, ai, conf.Port)
+	NewBot.SetUsageReporter(usage)
 	NewBot.StartCommandListener()
 
-	delivery.SetUp(r, uc, NewBot, *jsonRequester, ai, *conf, pg, geminiKey)
+	delivery.SetUp(r, uc, NewBot, *jsonRequester, ai, *conf, pg)
 	NewBot.SendNotification("Running on : " + conf.Port)
 	return r.Run(fmt.Sprintf(":%s", conf.Port))
 }
@@ -0,0 +1,449 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"testDeployment/internal/domain"
+	"testDeployment/pkg/Bot"
+	"testDeployment/pkg/ai"
+	"time"
+)
+
+const (
+	// maxAgentSteps bounds the model calls of one request; the last one
+	// may not call tools so the model has to answer.
+	maxAgentSteps = 6
+	// maxAgentItems bounds the list a tool hands back to the model.
+	maxAgentItems   = 10
+	agentDateLayout = "2006-01-02"
+	agentTimeLayout = "15:04"
+)
+
+const (
+	toolListDoctors      = "list_doctors"
+	toolFindDoctor       = "find_doctor"
+	toolSearchDrugs      = "search_drugs"
+	toolDrugsByType      = "drugs_by_type"
+	toolBookAppointment  = "book_appointment"
+	toolListAppointments = "list_appointments"
+)
+
+var (
+	publicTools = []ai.Tool{
+		{
+			Name:        toolListDoctors,
+			Description: "Lists the clinic's doctors grouped by speciality.",
+			Parameters:  &ai.Schema{Type: ai.TypeObject},
+		},
+		{
+			Name:        toolFindDoctor,
+			Description: "Finds doctors whose name contains the given text.",
+			Parameters: &ai.Schema{
+				Type: ai.TypeObject,
+				Properties: map[string]*ai.Schema{
+					"name": {Type: ai.TypeString, Description: "Part of the doctor's name"},
+				},
+				Required: []string{"name"},
+			},
+		},
+		{
+			Name:        toolSearchDrugs,
+			Description: "Searches the pharmacy catalog by product name.",
+			Parameters: &ai.Schema{
+				Type: ai.TypeObject,
+				Properties: map[string]*ai.Schema{
+					"name": {Type: ai.TypeString, Description: "Part of the product name"},
+				},
+				Required: []string{"name"},
+			},
+		},
+		{
+			Name:        toolDrugsByType,
+			Description: "Lists the pharmacy catalog products of a type, e.g. cream, gel or ointment.",
+			Parameters: &ai.Schema{
+				Type: ai.TypeObject,
+				Properties: map[string]*ai.Schema{
+					"type": {Type: ai.TypeString, Description: "Product type"},
+				},
+				Required: []string{"type"},
+			},
+		},
+	}
+	// userTools act on the caller's own appointments and are only offered
+	// to registered users.
+	userTools = []ai.Tool{
+		{
+			Name:        toolBookAppointment,
+			Description: "Books an appointment with a doctor for the patient. Only call it once the patient has asked for the booking and given every value.",
+			Parameters: &ai.Schema{
+				Type: ai.TypeObject,
+				Properties: map[string]*ai.Schema{
+					"doctor_id":    {Type: ai.TypeInteger, Description: "Id of the doctor, from list_doctors or find_doctor"},
+					"date":         {Type: ai.TypeString, Description: "Day of the appointment, YYYY-MM-DD"},
+					"from_time":    {Type: ai.TypeString, Description: "Start time, HH:MM"},
+					"to_time":      {Type: ai.TypeString, Description: "End time, HH:MM"},
+					"purpose":      {Type: ai.TypeString, Description: "Reason for the visit"},
+					"name":         {Type: ai.TypeString, Description: "Patient's full name"},
+					"phone_number": {Type: ai.TypeString, Description: "Patient's phone number"},
+				},
+				Required: []string{"doctor_id", "date", "from_time", "to_time", "purpose", "name", "phone_number"},
+			},
+		},
+		{
+			Name:        toolListAppointments,
+			Description: "Lists the patient's booked appointments.",
+			Parameters:  &ai.Schema{Type: ai.TypeObject},
+		},
+	}
+)
+
+type agentUseCase struct {
+	model        ai.Provider
+	prompts      IPromptUseCase
+	doctors      IDoctorUsecase
+	catalog      Usecase
+	schedule     IScheduleUseCase
+	verification IVerificationUseCase
+	safety       ISafetyUseCase
+	moderation   IModerationUseCase
+	bot          Bot.Bot
+}
+
+// NewAgentUseCase lets the model look up doctors and drugs and book
+// appointments through the existing use cases, always on behalf of the
+// caller.
+func NewAgentUseCase(
+	model ai.Provider,
+	prompts IPromptUseCase,
+	doctors IDoctorUsecase,
+	catalog Usecase,
+	schedule IScheduleUseCase,
+	verification IVerificationUseCase,
+	safety ISafetyUseCase,
+	moderation IModerationUseCase,
+	bot Bot.Bot,
+) IAgentUseCase {
+	return &agentUseCase{
+		model:        model,
+		prompts:      prompts,
+		doctors:      doctors,
+		catalog:      catalog,
+		schedule:     schedule,
+		verification: verification,
+		safety:       safety,
+		moderation:   moderation,
+		bot:          bot,
+	}
+}
+
+// agentRun is the state of one request: the drugs the tools returned are
+// the only ones the answer may cite, and phones are the numbers the model
+// only saw as [phone].
+type agentRun struct {
+	caller domain.Caller
+	drugs  map[string]*domain.Drug
+	phones []string
+	trace  []domain.AgentStep
+}
+
+// Run answers message, calling the tools the model asks for and handing
+// their results back until the model answers in text.
+func (u *agentUseCase) Run(ctx context.Context, caller domain.Caller, message string) (*domain.AgentReply, error) {
+	if strings.TrimSpace(message) == "" {
+		return nil, domain.ErrEmptyField
+	}
+	phones := redactedPhones(message)
+	message, err := u.moderation.CheckText(ctx, caller, message)
+	if err != nil {
+		return nil, err
+	}
+	system, err := u.prompts.Render(ctx, domain.PromptSystem, caller)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := u.prompts.ProfileContext(ctx, caller)
+	if err != nil {
+		return nil, err
+	}
+
+	tools := publicTools
+	if caller.IsRegistered() {
+		tools = append(append([]ai.Tool{}, publicTools...), userTools...)
+	}
+	run := &agentRun{caller: caller, drugs: make(map[string]*domain.Drug), phones: phones, trace: []domain.AgentStep{}}
+	req := ai.Request{
+		System: agentSystem(system.Text, caller, time.Now()),
+		Prompt: withProfile(profile, message),
+		Tools:  tools,
+	}
+
+	var (
+		res   *ai.Result
+		usage ai.Usage
+		turns []ai.Turn
+	)
+	for step := 1; ; step++ {
+		if step == maxAgentSteps {
+			// The tools stay declared for the calls in the history.
+			req.NoToolCalls = true
+			req.Prompt = "Answer the patient now with what you have found."
+		}
+		res, err = u.model.Generate(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		usage.PromptTokens += res.Usage.PromptTokens
+		usage.CandidateTokens += res.Usage.CandidateTokens
+		usage.TotalTokens += res.Usage.TotalTokens
+		if len(res.Calls) == 0 || req.NoToolCalls {
+			break
+		}
+
+		turns = append(turns,
+			ai.Turn{Role: ai.RoleUser, Text: req.Prompt, Results: req.ToolResults},
+			ai.Turn{Role: ai.RoleModel, Text: res.Text, Calls: res.Calls},
+		)
+		results := make([]ai.ToolResult, 0, len(res.Calls))
+		for _, call := range res.Calls {
+			results = append(results, u.call(ctx, run, call))
+		}
+		req = ai.Request{System: req.System, History: turns, Tools: tools, ToolResults: results}
+	}
+	if strings.TrimSpace(res.Text) == "" {
+		return nil, fmt.Errorf("agent gave no answer after %d steps", maxAgentSteps)
+	}
+
+	res.Usage = usage
+	grounding := &domain.DrugGrounding{System: req.System}
+	for _, d := range run.drugs {
+		grounding.Drugs = append(grounding.Drugs, d)
+	}
+	review := u.safety.Review(ctx, caller, "agent", message, res.Text)
+	reply := review.Apply(grounding.Apply(toChatReply(0, system.TemplateId, system.Language, res)))
+	return &domain.AgentReply{ChatReply: *reply, Trace: run.trace}, nil
+}
+
+// call runs one tool and records it in the trace. A failing tool is
+// reported to the model, which can then tell the patient or try again.
+func (u *agentUseCase) call(ctx context.Context, run *agentRun, call ai.ToolCall) ai.ToolResult {
+	start := time.Now()
+	out, err := u.dispatch(ctx, run, call)
+	step := domain.AgentStep{
+		Tool:       call.Name,
+		Args:       call.Args,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	result := ai.ToolResult{ID: call.ID, Name: call.Name}
+	if err != nil {
+		step.Error = err.Error()
+		result.Response = map[string]interface{}{"error": err.Error()}
+	} else {
+		// The round trip through JSON gives the model and the trace the
+		// same plain values the API would return.
+		var value interface{}
+		data, _ := json.Marshal(out)
+		_ = json.Unmarshal(data, &value)
+		step.Result = value
+		result.Response = map[string]interface{}{"result": value}
+	}
+	run.trace = append(run.trace, step)
+	return result
+}
+
+func (u *agentUseCase) dispatch(ctx context.Context, run *agentRun, call ai.ToolCall) (interface{}, error) {
+	switch call.Name {
+	case toolListDoctors:
+		return u.doctors.GetAll(ctx)
+	case toolFindDoctor:
+		name, err := argString(call.Args, "name")
+		if err != nil {
+			return nil, err
+		}
+		doctors, err := u.doctors.GetOneByID(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		if len(doctors) > maxAgentItems {
+			doctors = doctors[:maxAgentItems]
+		}
+		return doctors, nil
+	case toolSearchDrugs:
+		name, err := argString(call.Args, "name")
+		if err != nil {
+			return nil, err
+		}
+		drugs, err := u.catalog.GetDrugs(domain.DrugSearch{Name: name})
+		if err != nil {
+			return nil, err
+		}
+		return run.offer(drugs), nil
+	case toolDrugsByType:
+		kind, err := argString(call.Args, "type")
+		if err != nil {
+			return nil, err
+		}
+		found, err := u.catalog.GetDrugByType(ctx, kind)
+		if err != nil {
+			return nil, err
+		}
+		drugs := make([]domain.Drug, len(found))
+		for i, d := range found {
+			drugs[i] = domain.Drug{
+				Id:           d.Id,
+				Name:         d.Name,
+				Manufacturer: d.Manufacturer,
+				Description:  d.Description,
+				Receipt:      d.Receipt,
+				Type:         []string{kind},
+			}
+		}
+		return run.offer(drugs), nil
+	case toolBookAppointment:
+		if !run.caller.IsRegistered() {
+			return nil, fmt.Errorf("the patient must sign in to book an appointment")
+		}
+		verified, err := u.verification.IsVerified(ctx, run.caller.UserID)
+		if err != nil {
+			return nil, fmt.Errorf("could not book the appointment")
+		}
+		if !verified {
+			return nil, fmt.Errorf("the patient must verify their email to book an appointment")
+		}
+		appointment, err := appointmentFrom(call.Args)
+		if err != nil {
+			return nil, err
+		}
+		// The appointment is booked with the number the patient wrote; the
+		// model keeps seeing the placeholder.
+		phone := appointment.PhoneNumber
+		if appointment.PhoneNumber, err = run.phone(phone); err != nil {
+			return nil, err
+		}
+		appointment.UserId = strconv.Itoa(run.caller.UserID)
+		if err := u.schedule.Create(ctx, appointment); err != nil {
+			return nil, fmt.Errorf("could not book the appointment")
+		}
+		appointment.PhoneNumber = phone
+		return appointment, nil
+	case toolListAppointments:
+		if !run.caller.IsRegistered() {
+			return nil, fmt.Errorf("the patient must sign in to see appointments")
+		}
+		return u.schedule.GetAll(ctx, run.caller.UserID)
+	}
+	return nil, fmt.Errorf("unknown tool %q", call.Name)
+}
+
+// offer remembers drugs as citable and returns them without photos and with
+// short descriptions, which is all the model needs.
+func (r *agentRun) offer(drugs []domain.Drug) []domain.Drug {
+	if len(drugs) > maxAgentItems {
+		drugs = drugs[:maxAgentItems]
+	}
+	for i := range drugs {
+		drugs[i].Photo = nil
+		drugs[i].Description = truncateRunes(strings.TrimSpace(drugs[i].Description), groundingMaxDesc)
+		d := drugs[i]
+		r.drugs[d.Id] = &d
+	}
+	return drugs
+}
+
+// phone puts back the number behind the [phone] placeholder of the message.
+// With several numbers in the message the placeholder is ambiguous.
+func (r *agentRun) phone(s string) (string, error) {
+	if !strings.Contains(s, "[phone]") {
+		return s, nil
+	}
+	if len(r.phones) == 0 {
+		return "", fmt.Errorf("phone_number is required")
+	}
+	if len(r.phones) > 1 {
+		return "", fmt.Errorf("the message has %d phone numbers; the patient must send the request again with only the one to book with", len(r.phones))
+	}
+	return strings.TrimSpace(strings.ReplaceAll(s, "[phone]", r.phones[0])), nil
+}
+
+// agentSystem tells the model how to use its tools on top of the system
+// prompt. The agent answers one message at a time with no history, so it
+// must not ask follow-up questions it will not see the answer to.
+func agentSystem(system string, caller domain.Caller, now time.Time) string {
+	var sb strings.Builder
+	sb.WriteString(strings.TrimSpace(system))
+	sb.WriteString("\n\nYou can look up the clinic's doctors and the pharmacy catalog with the tools provided. Use them rather than guessing, and only state what they returned.")
+	sb.WriteString(" When you recommend a product a tool returned, cite it right after its name as [drug:ID]; recommend no other products.")
+	if caller.IsRegistered() {
+		sb.WriteString(" You can also book appointments and list the patient's appointments. Book only when the patient asked for it and gave the doctor, date, time, purpose, name and phone number in this message.")
+		sb.WriteString(" You only see this one message, not earlier ones: when a booking detail is missing, do not book or invent it, but say what is missing and ask the patient to send the whole request again with it.")
+	} else {
+		sb.WriteString(" The patient is not signed in: to book an appointment they have to sign in first.")
+	}
+	sb.WriteString(" Today is " + now.Format(agentDateLayout) + ".")
+	return sb.String()
+}
+
+// appointmentFrom validates the arguments of book_appointment.
+func appointmentFrom(args map[string]interface{}) (*domain.Schedule, error) {
+	doctorId, err := argInt(args, "doctor_id")
+	if err != nil {
+		return nil, err
+	}
+	appointment := &domain.Schedule{DoctorID: strconv.Itoa(doctorId)}
+	for name, dest := range map[string]*string{
+		"date":         &appointment.Date,
+		"from_time":    &appointment.FromTime,
+		"to_time":      &appointment.ToTime,
+		"purpose":      &appointment.Purpose,
+		"name":         &appointment.Name,
+		"phone_number": &appointment.PhoneNumber,
+	} {
+		if *dest, err = argString(args, name); err != nil {
+			return nil, err
+		}
+	}
+
+	day, err := time.Parse(agentDateLayout, appointment.Date)
+	if err != nil {
+		return nil, fmt.Errorf("date must be YYYY-MM-DD")
+	}
+	if day.Before(time.Now().Truncate(24 * time.Hour)) {
+		return nil, fmt.Errorf("date is in the past")
+	}
+	from, err := time.Parse(agentTimeLayout, appointment.FromTime)
+	if err != nil {
+		return nil, fmt.Errorf("from_time must be HH:MM")
+	}
+	to, err := time.Parse(agentTimeLayout, appointment.ToTime)
+	if err != nil {
+		return nil, fmt.Errorf("to_time must be HH:MM")
+	}
+	if !to.After(from) {
+		return nil, fmt.Errorf("to_time must be after from_time")
+	}
+	return appointment, nil
+}
+
+func argString(args map[string]interface{}, name string) (string, error) {
+	s, _ := args[name].(string)
+	if s = strings.TrimSpace(s); s == "" {
+		return "", fmt.Errorf("%s is required", name)
+	}
+	return s, nil
+}
+
+// argInt accepts numbers, which JSON decodes as float64, and numeric
+// strings.
+func argInt(args map[string]interface{}, name string) (int, error) {
+	switch v := args[name].(type) {
+	case float64:
+		return int(v), nil
+	case string:
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			return n, nil
+		}
+	}
+	return 0, fmt.Errorf("%s must be a number", name)
+}
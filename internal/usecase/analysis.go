@@ -0,0 +1,632 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/repository"
+	"testDeployment/pkg/Bot"
+	"testDeployment/pkg/ai"
+)
+
+const (
+	analysisAttempts   = 3
+	analysisMaxTokens  = 1024
+	maxConditions      = 5
+	analysisBasePrompt = "Analyse the skin shown in the photo. List up to 5 candidate conditions, most likely first, " +
+		"each with a confidence between 0 and 1; the visible features you base them on; how urgently a doctor " +
+		"should see it (low, moderate, high or emergency); short self-care advice; and the doctor specialty to " +
+		"visit. Finish with a one or two sentence summary for the patient. Reply with JSON only."
+	comparisonBasePrompt = "For every photo, in the same order and with the same label, describe what it shows. Then list the " +
+		"changes between them, say whether the area improved, is unchanged, worsened or whether that is unclear " +
+		"(for example when the photos are only different angles), how urgently a doctor should see it " +
+		"(low, moderate, high or emergency) and summarise for the patient in one or two sentences. Reply with JSON only."
+)
+
+type analysisUseCase struct {
+	model      ai.Provider
+	doctors    repository.IDoctorRepository
+	prompts    IPromptUseCase
+	drugs      IDrugUseCase
+	safety     ISafetyUseCase
+	moderation IModerationUseCase
+	cache      IAnalysisCache
+	bot        Bot.Bot
+}
+
+func NewAnalysisUseCase(
+	model ai.Provider,
+	doctors repository.IDoctorRepository,
+	prompts IPromptUseCase,
+	drugs IDrugUseCase,
+	safety ISafetyUseCase,
+	moderation IModerationUseCase,
+	cache IAnalysisCache,
+	bot Bot.Bot,
+) IAnalysisUseCase {
+	return &analysisUseCase{
+		model:      model,
+		doctors:    doctors,
+		prompts:    prompts,
+		drugs:      drugs,
+		safety:     safety,
+		moderation: moderation,
+		cache:      cache,
+		bot:        bot,
+	}
+}
+
+// Analyze asks the model for a SkinAnalysis constrained by a response
+// schema. previous, when set, is the last record of the same tracked
+// location; its photo and verdict are sent along so the model can describe
+// the change. When the model keeps returning invalid JSON the free-text
+// answer is returned as a fallback analysis.
+func (u *analysisUseCase) Analyze(ctx context.Context, caller domain.Caller, image []byte, mimeType, note string, previous *domain.SkinRecord) (*domain.SkinAnalysis, error) {
+	note, err := u.moderation.CheckText(ctx, caller, note)
+	if err != nil {
+		return nil, err
+	}
+	specialties, err := u.doctors.GetTypes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	system, task, err := u.renderPrompts(ctx, caller, domain.PromptAnalysis)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := u.prompts.ProfileContext(ctx, caller)
+	if err != nil {
+		return nil, err
+	}
+
+	images := []ai.Image{{Data: image, MIMEType: mimeType}}
+	if previous != nil && len(previous.Image) > 0 {
+		images = append(images, ai.Image{Data: previous.Image, MIMEType: previous.MIMEType})
+	}
+
+	req := ai.Request{
+		System:    system.Text,
+		Images:    images,
+		Prompt:    withProfile(profile, analysisPrompt(task.Text, note, specialties, previous)),
+		Schema:    analysisSchema(specialties, previous != nil),
+		MaxTokens: analysisMaxTokens,
+	}
+	key := cacheKey("analysis", u.model, req)
+	var analysis *domain.SkinAnalysis
+	if u.cache.Get(ctx, key, &analysis) {
+		analysis.Cached = true
+		// Reviewed again so every upload of a red-flag photo is escalated.
+		u.reviewAnalysis(ctx, caller, note, analysis)
+		return analysis, nil
+	}
+	// A cached photo has passed already; the previous one is the patient's
+	// own record.
+	if err := u.moderation.CheckImages(ctx, caller, images[0]); err != nil {
+		return nil, err
+	}
+
+	model, err := u.generateStructured(ctx, req, func(text string) (err error) {
+		analysis, err = parseAnalysis(text, specialties, previous != nil)
+		return err
+	})
+	if errors.Is(err, domain.ErrMalformedAnalysis) {
+		prompt := "Describe what you see on the skin in the photo and what the patient should do next."
+		if previous != nil {
+			prompt += previousContext(previous)
+		}
+		var summary string
+		summary, model, err = u.describe(ctx, system.Text, images, withProfile(profile, withNote(prompt, note)))
+		if err != nil {
+			return nil, err
+		}
+		analysis = &domain.SkinAnalysis{
+			Conditions:      []domain.ConditionCandidate{},
+			VisibleFeatures: []string{},
+			SelfCare:        []string{},
+			Summary:         summary,
+			Fallback:        true,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	analysis.PromptTemplateIds = templateIds(system, task)
+	analysis.Language = system.Language
+	analysis.Model = model
+	u.reviewAnalysis(ctx, caller, note, analysis)
+
+	// A fallback is not cached so the next upload tries for structured
+	// output again, nor is an answer of the fallback model, as the key is
+	// that of the primary one.
+	if !analysis.Fallback && model == u.model.Model() {
+		u.cache.Put(ctx, key, analysis)
+	}
+	return analysis, nil
+}
+
+// Compare sends all photos in one request and asks how the area changed
+// between them, in the order given.
+func (u *analysisUseCase) Compare(ctx context.Context, caller domain.Caller, photos []domain.LabeledImage, note string) (*domain.ImageComparison, error) {
+	note, err := u.moderation.CheckText(ctx, caller, note)
+	if err != nil {
+		return nil, err
+	}
+	system, task, err := u.renderPrompts(ctx, caller, domain.PromptComparison)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := u.prompts.ProfileContext(ctx, caller)
+	if err != nil {
+		return nil, err
+	}
+
+	images := make([]ai.Image, len(photos))
+	labels := make([]string, len(photos))
+	for i, p := range photos {
+		images[i] = ai.Image{Data: p.Data, MIMEType: p.MIMEType}
+		labels[i] = p.Label
+		if strings.TrimSpace(labels[i]) == "" {
+			labels[i] = fmt.Sprintf("image %d", i+1)
+		}
+	}
+
+	req := ai.Request{
+		System:    system.Text,
+		Images:    images,
+		Prompt:    withProfile(profile, comparisonPrompt(task.Text, labels, note)),
+		Schema:    comparisonSchema(),
+		MaxTokens: analysisMaxTokens,
+	}
+	key := cacheKey("comparison", u.model, req)
+	var comparison *domain.ImageComparison
+	if u.cache.Get(ctx, key, &comparison) {
+		comparison.Cached = true
+		u.reviewComparison(ctx, caller, note, comparison)
+		return comparison, nil
+	}
+	if err := u.moderation.CheckImages(ctx, caller, images...); err != nil {
+		return nil, err
+	}
+
+	model, err := u.generateStructured(ctx, req, func(text string) (err error) {
+		comparison, err = parseComparison(text, labels)
+		return err
+	})
+	if errors.Is(err, domain.ErrMalformedAnalysis) {
+		var summary string
+		summary, model, err = u.describe(ctx, system.Text, images, withProfile(profile, withNote("The photos show the same skin area, labelled in order: "+
+			strings.Join(labels, ", ")+". Describe how it has changed between them.", note)))
+		if err != nil {
+			return nil, err
+		}
+		comparison = &domain.ImageComparison{
+			Images:   []domain.ImageObservation{},
+			Changes:  []string{},
+			Summary:  summary,
+			Fallback: true,
+		}
+	} else if err != nil {
+		return nil, err
+	}
+	comparison.PromptTemplateIds = templateIds(system, task)
+	comparison.Language = system.Language
+	comparison.Model = model
+	u.reviewComparison(ctx, caller, note, comparison)
+
+	if !comparison.Fallback && model == u.model.Model() {
+		u.cache.Put(ctx, key, comparison)
+	}
+	return comparison, nil
+}
+
+// Describe answers a free-text question (note) about a photo, or the
+// active image prompt when there is none. With onChunk the answer is
+// streamed; a cached answer arrives as a single chunk.
+func (u *analysisUseCase) Describe(ctx context.Context, caller domain.Caller, image []byte, mimeType, note string, onChunk func(string) error) (*domain.ChatReply, error) {
+	note, err := u.moderation.CheckText(ctx, caller, note)
+	if err != nil {
+		return nil, err
+	}
+	system, err := u.prompts.Render(ctx, domain.PromptSystem, caller)
+	if err != nil {
+		return nil, err
+	}
+	// The image prompt is only used when the user wrote none.
+	promptTemplateId := system.TemplateId
+	prompt := note
+	if strings.TrimSpace(prompt) == "" {
+		rendered, err := u.prompts.Render(ctx, domain.PromptImage, caller)
+		if err != nil {
+			return nil, err
+		}
+		prompt = rendered.Text
+		if rendered.TemplateId != 0 {
+			promptTemplateId = rendered.TemplateId
+		}
+	}
+
+	profile, err := u.prompts.ProfileContext(ctx, caller)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only what the patient wrote says which products are relevant.
+	grounding := u.drugs.Ground(ctx, system.Text, note)
+	req := ai.Request{
+		System: grounding.System,
+		Images: []ai.Image{{Data: image, MIMEType: mimeType}},
+		Prompt: withProfile(profile, prompt),
+	}
+	key := cacheKey("describe", u.model, req)
+	var reply *domain.ChatReply
+	if u.cache.Get(ctx, key, &reply) {
+		reply.Cached = true
+		reply.Usage = nil
+		// The cached text already ends with the urgent disclaimer, so the
+		// review is not applied again; it escalates and refreshes the
+		// doctors.
+		review := u.safety.Review(ctx, caller, "upload", note, reply.Response)
+		reply.Disclaimer = review.Disclaimer
+		reply.UrgentReferral = review.UrgentReferral
+		if onChunk != nil {
+			if err := onChunk(reply.Response); err != nil {
+				return nil, err
+			}
+		}
+		return reply, nil
+	}
+	if err := u.moderation.CheckImages(ctx, caller, req.Images...); err != nil {
+		return nil, err
+	}
+
+	var res *ai.Result
+	if onChunk != nil {
+		res, err = u.model.Stream(ctx, req, onChunk)
+	} else {
+		res, err = u.model.Generate(ctx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+	review := u.safety.Review(ctx, caller, "upload", note, res.Text)
+	reply = review.Apply(grounding.Apply(toChatReply(0, promptTemplateId, system.Language, res)))
+	if ai.ModelOf(u.model, res) == u.model.Model() {
+		u.cache.Put(ctx, key, reply)
+	}
+	return reply, nil
+}
+
+// reviewAnalysis runs the safety review of a fresh or cached analysis.
+func (u *analysisUseCase) reviewAnalysis(ctx context.Context, caller domain.Caller, note string, analysis *domain.SkinAnalysis) {
+	var flags []string
+	if isUrgent(analysis.Urgency) {
+		flags = append(flags, domain.RedFlagModelUrgency)
+	}
+	review := u.safety.Review(ctx, caller, "analysis", note, analysisText(analysis), flags...)
+	analysis.Disclaimer = review.Disclaimer
+	analysis.UrgentReferral = review.UrgentReferral
+}
+
+func (u *analysisUseCase) reviewComparison(ctx context.Context, caller domain.Caller, note string, comparison *domain.ImageComparison) {
+	var flags []string
+	if isUrgent(comparison.Urgency) {
+		flags = append(flags, domain.RedFlagModelUrgency)
+	}
+	if comparison.Trend == domain.TrendWorsened {
+		flags = append(flags, domain.RedFlagEvolving)
+	}
+	review := u.safety.Review(ctx, caller, "compare", note, comparisonText(comparison), flags...)
+	comparison.Disclaimer = review.Disclaimer
+	comparison.UrgentReferral = review.UrgentReferral
+}
+
+// analysisText is what the safety layer reads of a structured analysis.
+func analysisText(a *domain.SkinAnalysis) string {
+	text := append([]string{a.Summary, a.ChangeSincePrevious}, a.VisibleFeatures...)
+	for _, c := range a.Conditions {
+		text = append(text, c.Name)
+	}
+	return strings.Join(text, ".\n")
+}
+
+func comparisonText(c *domain.ImageComparison) string {
+	text := append([]string{c.Summary}, c.Changes...)
+	for _, image := range c.Images {
+		text = append(text, image.Findings)
+	}
+	return strings.Join(text, ".\n")
+}
+
+// renderPrompts renders the system prompt and the task prompt name.
+func (u *analysisUseCase) renderPrompts(ctx context.Context, caller domain.Caller, name string) (system, task *domain.RenderedPrompt, err error) {
+	if system, err = u.prompts.Render(ctx, domain.PromptSystem, caller); err != nil {
+		return nil, nil, err
+	}
+	if task, err = u.prompts.Render(ctx, name, caller); err != nil {
+		return nil, nil, err
+	}
+	return system, task, nil
+}
+
+// templateIds lists the stored prompt versions used, skipping built-ins.
+func templateIds(prompts ...*domain.RenderedPrompt) []int {
+	var ids []int
+	for _, p := range prompts {
+		if p.TemplateId != 0 {
+			ids = append(ids, p.TemplateId)
+		}
+	}
+	return ids
+}
+
+// generateStructured runs req until parse accepts the reply. Each rejection
+// is fed back to the model; after analysisAttempts it gives up with
+// domain.ErrMalformedAnalysis. model is the model that answered.
+func (u *analysisUseCase) generateStructured(ctx context.Context, req ai.Request, parse func(text string) error) (model string, err error) {
+	prompt := req.Prompt
+	var lastErr error
+	for attempt := 0; attempt < analysisAttempts; attempt++ {
+		res, err := u.model.Generate(ctx, req)
+		if err != nil {
+			return "", err
+		}
+		if err = parse(res.Text); err == nil {
+			return ai.ModelOf(u.model, res), nil
+		}
+		lastErr = err
+		req.Prompt = prompt + "\n\nYour previous reply was rejected (" + err.Error() + "). Reply again with valid JSON only."
+	}
+
+	err = fmt.Errorf("%w after %d attempts: %v", domain.ErrMalformedAnalysis, analysisAttempts, lastErr)
+	u.bot.SendErrorNotification(err)
+	return "", err
+}
+
+// describe is the free-text fallback for when structured output fails.
+func (u *analysisUseCase) describe(ctx context.Context, system string, images []ai.Image, prompt string) (text, model string, err error) {
+	res, err := u.model.Generate(ctx, ai.Request{System: system, Images: images, Prompt: prompt})
+	if err != nil {
+		return "", "", err
+	}
+	return res.Text, ai.ModelOf(u.model, res), nil
+}
+
+func withNote(prompt, note string) string {
+	if note = strings.TrimSpace(note); note != "" {
+		prompt += "\nPatient note: " + note
+	}
+	return prompt
+}
+
+func analysisPrompt(base, note string, specialties []string, previous *domain.SkinRecord) string {
+	prompt := base
+	if len(specialties) > 0 {
+		prompt += "\nThe specialty must be one of: " + strings.Join(specialties, ", ") + "."
+	}
+	if previous != nil {
+		prompt += previousContext(previous) + "\nDescribe in change_since_previous how the spot has changed since then."
+	}
+	return withNote(prompt, note)
+}
+
+// previousContext describes the earlier record of a tracked location. Its
+// photo, when loaded, is the second image of the request.
+func previousContext(previous *domain.SkinRecord) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "\nThis spot (%s) was analysed before, on %s.", previous.Location, previous.CreatedAt)
+	if len(previous.Image) > 0 {
+		b.WriteString(" The second photo is from that time; analyse the first one.")
+	}
+	if a := previous.Analysis; a != nil {
+		if len(a.Conditions) > 0 {
+			names := make([]string, len(a.Conditions))
+			for i, c := range a.Conditions {
+				names[i] = fmt.Sprintf("%s (%.2f)", c.Name, c.Confidence)
+			}
+			b.WriteString(" Candidate conditions then: " + strings.Join(names, ", ") + ".")
+		}
+		if a.Urgency != "" {
+			b.WriteString(" Urgency then: " + a.Urgency + ".")
+		}
+		if a.Summary != "" {
+			b.WriteString(" Summary then: " + a.Summary)
+		}
+	}
+	return b.String()
+}
+
+func analysisSchema(specialties []string, withPrevious bool) *ai.Schema {
+	text := &ai.Schema{Type: ai.TypeString}
+	schema := &ai.Schema{
+		Type: ai.TypeObject,
+		Properties: map[string]*ai.Schema{
+			"conditions": {
+				Type: ai.TypeArray,
+				Items: &ai.Schema{
+					Type: ai.TypeObject,
+					Properties: map[string]*ai.Schema{
+						"name":       {Type: ai.TypeString},
+						"confidence": {Type: ai.TypeNumber, Description: "0 to 1"},
+					},
+					Required: []string{"name", "confidence"},
+				},
+			},
+			"visible_features":      {Type: ai.TypeArray, Items: text},
+			"urgency":               {Type: ai.TypeString, Enum: domain.UrgencyLevels},
+			"self_care":             {Type: ai.TypeArray, Items: text},
+			"recommended_specialty": {Type: ai.TypeString, Enum: specialties},
+			"summary":               {Type: ai.TypeString},
+		},
+		Required: []string{"conditions", "visible_features", "urgency", "self_care", "recommended_specialty", "summary"},
+	}
+	if withPrevious {
+		schema.Properties["change_since_previous"] = text
+		schema.Required = append(schema.Required, "change_since_previous")
+	}
+	return schema
+}
+
+// parseAnalysis decodes the model reply strictly and checks every field
+// against what the app can render.
+func parseAnalysis(text string, specialties []string, withPrevious bool) (*domain.SkinAnalysis, error) {
+	dec := json.NewDecoder(strings.NewReader(stripCodeFence(text)))
+	dec.DisallowUnknownFields()
+
+	var analysis domain.SkinAnalysis
+	if err := dec.Decode(&analysis); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if analysis.Fallback {
+		return nil, fmt.Errorf("unexpected field fallback")
+	}
+
+	if len(analysis.Conditions) == 0 {
+		return nil, fmt.Errorf("no conditions")
+	}
+	for _, c := range analysis.Conditions {
+		if strings.TrimSpace(c.Name) == "" {
+			return nil, fmt.Errorf("condition without a name")
+		}
+		if c.Confidence < 0 || c.Confidence > 1 {
+			return nil, fmt.Errorf("confidence %v of %q is outside 0..1", c.Confidence, c.Name)
+		}
+	}
+	sort.SliceStable(analysis.Conditions, func(i, j int) bool {
+		return analysis.Conditions[i].Confidence > analysis.Conditions[j].Confidence
+	})
+	if len(analysis.Conditions) > maxConditions {
+		analysis.Conditions = analysis.Conditions[:maxConditions]
+	}
+
+	if !contains(domain.UrgencyLevels, analysis.Urgency) {
+		return nil, fmt.Errorf("unknown urgency %q", analysis.Urgency)
+	}
+
+	if len(specialties) > 0 {
+		specialty, ok := matchFold(specialties, analysis.RecommendedSpecialty)
+		if !ok {
+			return nil, fmt.Errorf("unknown specialty %q", analysis.RecommendedSpecialty)
+		}
+		analysis.RecommendedSpecialty = specialty
+	}
+
+	if withPrevious && strings.TrimSpace(analysis.ChangeSincePrevious) == "" {
+		return nil, fmt.Errorf("no change_since_previous")
+	}
+	if !withPrevious {
+		analysis.ChangeSincePrevious = ""
+	}
+
+	if analysis.VisibleFeatures == nil {
+		analysis.VisibleFeatures = []string{}
+	}
+	if analysis.SelfCare == nil {
+		analysis.SelfCare = []string{}
+	}
+	return &analysis, nil
+}
+
+func comparisonPrompt(base string, labels []string, note string) string {
+	var b strings.Builder
+	b.WriteString("The photos show the same area of skin, possibly from different angles or weeks apart. They are, in order:\n")
+	for i, label := range labels {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, label)
+	}
+	b.WriteString(base)
+	return withNote(b.String(), note)
+}
+
+func comparisonSchema() *ai.Schema {
+	return &ai.Schema{
+		Type: ai.TypeObject,
+		Properties: map[string]*ai.Schema{
+			"images": {
+				Type: ai.TypeArray,
+				Items: &ai.Schema{
+					Type: ai.TypeObject,
+					Properties: map[string]*ai.Schema{
+						"label":    {Type: ai.TypeString},
+						"findings": {Type: ai.TypeString},
+					},
+					Required: []string{"label", "findings"},
+				},
+			},
+			"changes": {Type: ai.TypeArray, Items: &ai.Schema{Type: ai.TypeString}},
+			"trend":   {Type: ai.TypeString, Enum: domain.Trends},
+			"urgency": {Type: ai.TypeString, Enum: domain.UrgencyLevels},
+			"summary": {Type: ai.TypeString},
+		},
+		Required: []string{"images", "changes", "trend", "urgency", "summary"},
+	}
+}
+
+func parseComparison(text string, labels []string) (*domain.ImageComparison, error) {
+	dec := json.NewDecoder(strings.NewReader(stripCodeFence(text)))
+	dec.DisallowUnknownFields()
+
+	var comparison domain.ImageComparison
+	if err := dec.Decode(&comparison); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if comparison.Fallback {
+		return nil, fmt.Errorf("unexpected field fallback")
+	}
+
+	if len(comparison.Images) != len(labels) {
+		return nil, fmt.Errorf("described %d photos, expected %d", len(comparison.Images), len(labels))
+	}
+	// The order is what identifies a photo; the model may paraphrase labels.
+	for i := range comparison.Images {
+		if strings.TrimSpace(comparison.Images[i].Findings) == "" {
+			return nil, fmt.Errorf("no findings for photo %d", i+1)
+		}
+		comparison.Images[i].Label = labels[i]
+	}
+	if !contains(domain.Trends, comparison.Trend) {
+		return nil, fmt.Errorf("unknown trend %q", comparison.Trend)
+	}
+	if !contains(domain.UrgencyLevels, comparison.Urgency) {
+		return nil, fmt.Errorf("unknown urgency %q", comparison.Urgency)
+	}
+	if comparison.Changes == nil {
+		comparison.Changes = []string{}
+	}
+	return &comparison, nil
+}
+
+// stripCodeFence removes the ```json fence some models wrap JSON in even
+// when asked not to.
+func stripCodeFence(text string) string {
+	text = strings.TrimSpace(text)
+	if !strings.HasPrefix(text, "```") {
+		return text
+	}
+	text = strings.TrimPrefix(text, "```")
+	if i := strings.IndexByte(text, '\n'); i >= 0 {
+		text = text[i+1:]
+	}
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(text), "```"))
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// matchFold returns the canonical spelling of v from values.
+func matchFold(values []string, v string) (string, bool) {
+	v = strings.TrimSpace(v)
+	for _, value := range values {
+		if strings.EqualFold(value, v) {
+			return value, true
+		}
+	}
+	return "", false
+}
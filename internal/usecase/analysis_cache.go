@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	configs "testDeployment/internal/common/config"
+	"testDeployment/internal/repository"
+	"testDeployment/pkg/Bot"
+	"testDeployment/pkg/ai"
+	"testDeployment/pkg/cache"
+	"time"
+)
+
+const (
+	cacheTierTimeout   = 2 * time.Second
+	cacheSweepInterval = time.Hour
+)
+
+type cacheBypassKey struct{}
+
+// WithoutCache makes the analyses of ctx skip cached answers. The fresh
+// answer still replaces the cached one.
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, cacheBypassKey{}, true)
+}
+
+func cacheBypassed(ctx context.Context) bool {
+	bypass, _ := ctx.Value(cacheBypassKey{}).(bool)
+	return bypass
+}
+
+type analysisCache struct {
+	lru  *cache.LRU
+	repo repository.IAnalysisCacheRepository
+	ttl  time.Duration
+	bot  Bot.Bot
+}
+
+// NewAnalysisCache caches finished analyses in process and, when repo is
+// not nil, in Postgres so they survive restarts and are shared between
+// instances.
+func NewAnalysisCache(repo repository.IAnalysisCacheRepository, cfg configs.Cache, bot Bot.Bot) IAnalysisCache {
+	c := &analysisCache{
+		lru:  cache.NewLRU(cfg.Size, cfg.TTL),
+		repo: repo,
+		ttl:  cfg.TTL,
+		bot:  bot,
+	}
+	if repo != nil {
+		go c.sweep()
+	}
+	return c
+}
+
+// Get decodes the value of key into dest. Errors of the Postgres tier are
+// a miss: the cache only ever saves a model call.
+func (c *analysisCache) Get(ctx context.Context, key string, dest interface{}) bool {
+	if cacheBypassed(ctx) {
+		return false
+	}
+	if value, ok := c.lru.Get(key); ok {
+		return json.Unmarshal(value, dest) == nil
+	}
+	if c.repo == nil {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, cacheTierTimeout)
+	defer cancel()
+	value, expiresAt, err := c.repo.Get(ctx, key)
+	if err != nil {
+		return false
+	}
+	if json.Unmarshal(value, dest) != nil {
+		return false
+	}
+	c.lru.AddUntil(key, value, expiresAt)
+	return true
+}
+
+func (c *analysisCache) Put(ctx context.Context, key string, value interface{}) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.bot.SendErrorNotification(err)
+		return
+	}
+	c.lru.Add(key, data)
+	if c.repo == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cacheTierTimeout)
+	defer cancel()
+	_ = c.repo.Put(ctx, key, data, time.Now().Add(c.ttl))
+}
+
+func (c *analysisCache) sweep() {
+	for {
+		time.Sleep(cacheSweepInterval)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		_, _ = c.repo.DeleteExpired(ctx)
+		cancel()
+	}
+}
+
+// cacheKey addresses a model answer by everything that shapes it: the kind
+// of analysis, the model, the rendered prompts (and so their versions) and
+// the preprocessed image bytes.
+func cacheKey(kind string, model ai.Provider, req ai.Request) string {
+	h := sha256.New()
+	write := func(b []byte) {
+		var n [8]byte
+		binary.BigEndian.PutUint64(n[:], uint64(len(b)))
+		h.Write(n[:])
+		h.Write(b)
+	}
+	write([]byte(kind))
+	write([]byte(model.Name()))
+	write([]byte(model.Model()))
+	write([]byte(req.System))
+	write([]byte(req.Prompt))
+	for _, img := range req.Images {
+		write([]byte(img.MIMEType))
+		write(img.Data)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	configs "testDeployment/internal/common/config"
+	"testDeployment/internal/delivery/dto"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/repository"
+	"testDeployment/pkg/Bot"
+	"testDeployment/pkg/jwt"
+	"time"
+)
+
+const refreshTokenBytes = 32
+
+type authUseCase struct {
+	tokens      repository.IRefreshTokenRepository
+	revocations *revocationList
+	accessTTL   time.Duration
+	refreshTTL  time.Duration
+	bot         Bot.Bot
+}
+
+// NewAuthUseCase issues access and refresh tokens with the lifetimes of cfg
+// and keeps the list of revoked access tokens, synced from revocations
+// every cfg.RevocationSync.
+func NewAuthUseCase(tokens repository.IRefreshTokenRepository, revocations repository.ITokenRevocationRepository, cfg configs.JWT, bot Bot.Bot) IAuthUseCase {
+	return &authUseCase{
+		tokens:      tokens,
+		revocations: newRevocationList(revocations, cfg.RevocationSync),
+		accessTTL:   cfg.TokenTTL,
+		refreshTTL:  cfg.RefreshTokenTTL,
+		bot:         bot,
+	}
+}
+
+// Issue starts a new token family for a login or signup.
+func (u *authUseCase) Issue(ctx context.Context, userId int, role string) (*dto.AuthResponse, error) {
+	family, err := randomToken(16)
+	if err != nil {
+		return nil, err
+	}
+	return u.issue(ctx, userId, role, hex.EncodeToString(family))
+}
+
+// Refresh spends refreshToken and issues the next pair of its family. A
+// spent token presented again was stolen, or it is the thief presenting it
+// after the owner: either way the whole family is revoked, which signs both
+// out, and ErrRefreshTokenReused is returned.
+func (u *authUseCase) Refresh(ctx context.Context, refreshToken string) (*dto.AuthResponse, error) {
+	token, err := u.tokens.GetByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if token.RevokedAt != nil || !time.Now().Before(token.ExpiresAt) {
+		return nil, domain.ErrInvalidRefreshToken
+	}
+	if token.UsedAt != nil {
+		return nil, u.reused(ctx, token)
+	}
+	fresh, err := u.tokens.Use(ctx, token.Id)
+	if err != nil {
+		return nil, err
+	}
+	if !fresh {
+		// Spent by a concurrent refresh between the read and the update.
+		return nil, u.reused(ctx, token)
+	}
+	return u.issue(ctx, token.UserId, token.Role, token.FamilyId)
+}
+
+// Logout signs out the device claims were issued to: the access token is
+// revoked and so is its refresh token family.
+func (u *authUseCase) Logout(ctx context.Context, claims *jwt.Claims) error {
+	if claims.ID != "" {
+		expiresAt := claims.ExpiresAt
+		if expiresAt.IsZero() {
+			expiresAt = time.Now().Add(u.accessTTL)
+		}
+		err := u.revocations.revokeToken(ctx, domain.RevokedToken{
+			Jti:       claims.ID,
+			UserId:    claims.UserID,
+			ExpiresAt: expiresAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	if claims.SessionID != "" {
+		return u.tokens.RevokeFamily(ctx, claims.SessionID)
+	}
+	return nil
+}
+
+// LogoutEverywhere revokes every token and session issued to the user so
+// far, and every refresh token.
+func (u *authUseCase) LogoutEverywhere(ctx context.Context, userId int) error {
+	// iat has whole seconds, so a login right after this one, in the same
+	// second, must not be refused.
+	err := u.revocations.revokeUser(ctx, domain.UserRevocation{
+		UserId:        userId,
+		RevokedBefore: time.Now().Truncate(time.Second),
+	})
+	if err != nil {
+		return err
+	}
+	return u.tokens.RevokeUser(ctx, userId)
+}
+
+func (u *authUseCase) IsRevoked(claims *jwt.Claims) bool {
+	return u.revocations.revoked(claims)
+}
+
+func (u *authUseCase) reused(ctx context.Context, token *domain.RefreshToken) error {
+	if err := u.tokens.RevokeFamily(ctx, token.FamilyId); err != nil {
+		return err
+	}
+	u.bot.SendNotification(fmt.Sprintf("Refresh token reuse for user %d: session family %s revoked", token.UserId, token.FamilyId[:8]))
+	return domain.ErrRefreshTokenReused
+}
+
+func (u *authUseCase) issue(ctx context.Context, userId int, role, family string) (*dto.AuthResponse, error) {
+	access, err := jwt.CreateToken(userId, role, family, u.accessTTL)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomToken(refreshTokenBytes)
+	if err != nil {
+		return nil, err
+	}
+	refresh := base64.RawURLEncoding.EncodeToString(secret)
+	err = u.tokens.Create(ctx, &domain.RefreshToken{
+		UserId:    userId,
+		Role:      role,
+		FamilyId:  family,
+		TokenHash: hashToken(refresh),
+		ExpiresAt: time.Now().Add(u.refreshTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.AuthResponse{
+		AccessToken:      access,
+		UserID:           userId,
+		Role:             role,
+		ExpiresIn:        int(u.accessTTL.Seconds()),
+		RefreshToken:     refresh,
+		RefreshExpiresIn: int(u.refreshTTL.Seconds()),
+	}, nil
+}
+
+func randomToken(n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return nil, errors.New("could not generate a token")
+	}
+	return b, nil
+}
+
+// hashToken is what is stored of a refresh token. The tokens are random,
+// so an unsalted fast hash is enough.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,226 @@
+package usecase
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/repository"
+	"testDeployment/pkg/Bot"
+	"testDeployment/pkg/ai"
+	"time"
+)
+
+const (
+	messageTimeLayout = "2006-01-02T15:04:05"
+	maxTitleLength    = 60
+)
+
+type chatUseCase struct {
+	repo       repository.IChatRepository
+	model      ai.Provider
+	prompts    IPromptUseCase
+	drugs      IDrugUseCase
+	safety     ISafetyUseCase
+	moderation IModerationUseCase
+	summary    domain.SummaryPolicy
+	bot        Bot.Bot
+	// summarising holds the conversations being summarised, so a burst of
+	// turns starts only one summarisation each.
+	summarising sync.Map
+}
+
+func NewChatUseCase(repo repository.IChatRepository, model ai.Provider, prompts IPromptUseCase, drugs IDrugUseCase, safety ISafetyUseCase, moderation IModerationUseCase, summary domain.SummaryPolicy, bot Bot.Bot) IChatUseCase {
+	return &chatUseCase{
+		repo:       repo,
+		model:      model,
+		prompts:    prompts,
+		drugs:      drugs,
+		safety:     safety,
+		moderation: moderation,
+		summary:    summary,
+		bot:        bot,
+	}
+}
+
+func (u *chatUseCase) CreateConversation(ctx context.Context, userId int, title string) (*domain.Conversation, error) {
+	conversation := &domain.Conversation{
+		UserId: userId,
+		Title:  conversationTitle(title),
+	}
+	if err := u.repo.CreateConversation(ctx, conversation); err != nil {
+		return nil, err
+	}
+	return conversation, nil
+}
+
+func (u *chatUseCase) GetConversations(ctx context.Context, userId int) ([]*domain.Conversation, error) {
+	return u.repo.GetConversations(ctx, userId)
+}
+
+func (u *chatUseCase) GetConversation(ctx context.Context, userId int, id int) (*domain.Conversation, error) {
+	conversation, err := u.repo.GetConversation(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+	conversation.Messages, err = u.repo.GetMessages(ctx, conversation.Id)
+	if err != nil {
+		return nil, err
+	}
+	return conversation, nil
+}
+
+func (u *chatUseCase) DeleteConversation(ctx context.Context, userId int, id int) error {
+	return u.repo.DeleteConversation(ctx, userId, id)
+}
+
+// SendMessage answers one turn. Guests get a stateless answer; registered
+// users have the turn stored in a conversation (a new one when no id is
+// given) and the earlier turns replayed to the model as history: the
+// conversation summary, when there is one, and the messages after it.
+func (u *chatUseCase) SendMessage(ctx context.Context, caller domain.Caller, message domain.NewMessage) (*domain.ChatReply, error) {
+	return u.reply(ctx, caller, message, func(req ai.Request) (*ai.Result, error) {
+		return u.model.Generate(ctx, req)
+	})
+}
+
+// StreamMessage is SendMessage with the answer forwarded to onChunk while it
+// is generated. The turn is only stored once the stream has completed.
+func (u *chatUseCase) StreamMessage(ctx context.Context, caller domain.Caller, message domain.NewMessage, onChunk func(string) error) (*domain.ChatReply, error) {
+	return u.reply(ctx, caller, message, func(req ai.Request) (*ai.Result, error) {
+		return u.model.Stream(ctx, req, onChunk)
+	})
+}
+
+func (u *chatUseCase) reply(
+	ctx context.Context,
+	caller domain.Caller,
+	message domain.NewMessage,
+	generate func(req ai.Request) (*ai.Result, error),
+) (*domain.ChatReply, error) {
+	// The redacted message is the one sent and stored.
+	text, err := u.moderation.CheckText(ctx, caller, message.Request)
+	if err != nil {
+		return nil, err
+	}
+	message.Request = text
+	system, err := u.prompts.Render(ctx, domain.PromptSystem, caller)
+	if err != nil {
+		return nil, err
+	}
+	profile, err := u.prompts.ProfileContext(ctx, caller)
+	if err != nil {
+		return nil, err
+	}
+	// Only the question is stored; the profile is prepended afresh on every
+	// turn so it follows changes to the user's info.
+	grounding := u.drugs.Ground(ctx, system.Text, message.Request)
+	req := ai.Request{System: grounding.System, Prompt: withProfile(profile, message.Request)}
+
+	if !caller.IsRegistered() {
+		res, err := generate(req)
+		if err != nil {
+			return nil, err
+		}
+		review := u.safety.Review(ctx, caller, "chat", message.Request, res.Text)
+		return review.Apply(grounding.Apply(toChatReply(0, system.TemplateId, system.Language, res))), nil
+	}
+
+	conversation, err := u.resolveConversation(ctx, caller.UserID, message)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := u.repo.GetMessages(ctx, conversation.Id)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := u.getSummary(ctx, conversation.Id)
+	if err != nil {
+		return nil, err
+	}
+	if summary != nil {
+		messages = after(messages, summary.UpToMessageId)
+		req.System = withSummary(req.System, summary)
+	}
+	req.History = toTurns(messages)
+
+	res, err := generate(req)
+	if err != nil {
+		return nil, err
+	}
+	review := u.safety.Review(ctx, caller, "chat", message.Request, res.Text)
+	reply := review.Apply(grounding.Apply(toChatReply(conversation.Id, system.TemplateId, system.Language, res)))
+
+	// The stored answer is the one the patient saw, urgent disclaimer included.
+	turn, err := u.saveTurn(ctx, caller.UserID, conversation.Id, message.Request, reply.Response, system.TemplateId, ai.ModelOf(u.model, res))
+	if err != nil {
+		return nil, err
+	}
+	unsummarised := append(messages, turn...)
+	if u.needsSummary(unsummarised, res.Usage.PromptTokens) {
+		u.summariseLater(ctx, conversation.Id, summary, unsummarised)
+	}
+	return reply, nil
+}
+
+func (u *chatUseCase) resolveConversation(ctx context.Context, userId int, message domain.NewMessage) (*domain.Conversation, error) {
+	if message.ConversationId == 0 {
+		return u.CreateConversation(ctx, userId, message.Request)
+	}
+	return u.repo.GetConversation(ctx, userId, message.ConversationId)
+}
+
+// saveTurn stores the user message and the model answer together so the
+// history never holds a question without its reply, and returns them.
+func (u *chatUseCase) saveTurn(ctx context.Context, userId int, conversationId int, request, response string, promptTemplateId int, model string) ([]domain.Message, error) {
+	now := time.Now().Format(messageTimeLayout)
+	turn := []domain.Message{
+		{User_id: strconv.Itoa(userId), ConversationId: conversationId, IsAi: false, Text: request, CreatedAt: now},
+		{User_id: strconv.Itoa(userId), ConversationId: conversationId, IsAi: true, Text: response, CreatedAt: now, PromptTemplateId: promptTemplateId, Model: model},
+	}
+	for i := range turn {
+		if err := u.repo.CreateMessage(ctx, &turn[i]); err != nil {
+			return nil, err
+		}
+	}
+	return turn, u.repo.TouchConversation(ctx, conversationId)
+}
+
+func toChatReply(conversationId int, promptTemplateId int, language string, res *ai.Result) *domain.ChatReply {
+	return &domain.ChatReply{
+		ConversationId:   conversationId,
+		Response:         res.Text,
+		FinishReason:     res.FinishReason,
+		PromptTemplateId: promptTemplateId,
+		Language:         language,
+		Usage: &domain.TokenUsage{
+			PromptTokens:    res.Usage.PromptTokens,
+			CandidateTokens: res.Usage.CandidateTokens,
+			TotalTokens:     res.Usage.TotalTokens,
+		},
+	}
+}
+
+func toTurns(messages []domain.Message) []ai.Turn {
+	turns := make([]ai.Turn, 0, len(messages))
+	for _, m := range messages {
+		role := ai.RoleUser
+		if m.IsAi {
+			role = ai.RoleModel
+		}
+		turns = append(turns, ai.Turn{Role: role, Text: m.Text})
+	}
+	return turns
+}
+
+func conversationTitle(title string) string {
+	title = strings.Join(strings.Fields(title), " ")
+	if title == "" {
+		return "New conversation"
+	}
+	if r := []rune(title); len(r) > maxTitleLength {
+		return string(r[:maxTitleLength-3]) + "..."
+	}
+	return title
+}
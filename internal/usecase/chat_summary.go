@@ -0,0 +1,159 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testDeployment/internal/domain"
+	"testDeployment/pkg/ai"
+)
+
+const (
+	summaryMaxTokens   = 512
+	summaryInstruction = "You condense a conversation between a patient and a dermatology assistant so it can be continued " +
+		"without the full transcript. Keep every medically relevant fact: symptoms and where they are, how long they " +
+		"have lasted and how they changed, photos discussed, conditions suspected, advice and products recommended " +
+		"(keep [drug:ID] citations), what the patient tried and how it went, and open questions. Leave out greetings " +
+		"and repetition. Write plain sentences in the language of the conversation, at most 250 words."
+)
+
+// GetSummary returns the stored summary of one of the user's conversations.
+func (u *chatUseCase) GetSummary(ctx context.Context, userId int, id int) (*domain.ConversationSummary, error) {
+	conversation, err := u.repo.GetConversation(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+	return u.repo.GetSummary(ctx, conversation.Id)
+}
+
+// DeleteMessage deletes one message of the user's conversation together
+// with the other half of its turn, the request or the answer, so the
+// history replayed to the model keeps alternating. When the summary
+// condenses either, the summary is dropped and rebuilt from the
+// remaining messages in the background. A summarisation already running is
+// not saved: SaveSummary refuses a summary of a deleted message.
+func (u *chatUseCase) DeleteMessage(ctx context.Context, userId int, conversationId int, id int) error {
+	conversation, err := u.repo.GetConversation(ctx, userId, conversationId)
+	if err != nil {
+		return err
+	}
+	summary, err := u.getSummary(ctx, conversation.Id)
+	if err != nil {
+		return err
+	}
+	first, err := u.repo.DeleteMessage(ctx, conversation.Id, id)
+	if err != nil {
+		return err
+	}
+	if summary == nil || first > summary.UpToMessageId {
+		return nil
+	}
+
+	if err := u.repo.DeleteSummary(ctx, conversation.Id); err != nil {
+		return err
+	}
+	messages, err := u.repo.GetMessages(ctx, conversation.Id)
+	if err != nil {
+		return err
+	}
+	u.summariseLater(ctx, conversation.Id, nil, messages)
+	return nil
+}
+
+// getSummary is the stored summary, nil when there is none yet.
+func (u *chatUseCase) getSummary(ctx context.Context, conversationId int) (*domain.ConversationSummary, error) {
+	summary, err := u.repo.GetSummary(ctx, conversationId)
+	if errors.Is(err, domain.ErrSummaryNotFound) {
+		return nil, nil
+	}
+	return summary, err
+}
+
+// needsSummary reports whether the messages not yet condensed, or the
+// prompt tokens of the last request, have reached the policy's limits.
+func (u *chatUseCase) needsSummary(unsummarised []domain.Message, promptTokens int32) bool {
+	if len(unsummarised) <= 2*u.summary.KeepTurns {
+		return false
+	}
+	return (u.summary.AfterTurns > 0 && len(unsummarised)/2 >= u.summary.AfterTurns) ||
+		(u.summary.AfterTokens > 0 && int(promptTokens) >= u.summary.AfterTokens)
+}
+
+// summariseLater condenses previous and the older unsummarised messages
+// after the answer has been sent. The patient does not wait for it, and a
+// failure only means the next request replays more history.
+func (u *chatUseCase) summariseLater(ctx context.Context, conversationId int, previous *domain.ConversationSummary, unsummarised []domain.Message) {
+	if _, running := u.summarising.LoadOrStore(conversationId, true); running {
+		return
+	}
+	// The request context ends with the response; its values still tag the
+	// model call with the user.
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		defer u.summarising.Delete(conversationId)
+		if err := u.summarise(ctx, conversationId, previous, unsummarised); err != nil {
+			u.bot.SendErrorNotification(fmt.Errorf("summarise conversation %d: %w", conversationId, err))
+		}
+	}()
+}
+
+// summarise folds all but the last KeepTurns turns of unsummarised into
+// previous and stores the result.
+func (u *chatUseCase) summarise(ctx context.Context, conversationId int, previous *domain.ConversationSummary, unsummarised []domain.Message) error {
+	keep := min(len(unsummarised), 2*u.summary.KeepTurns)
+	older := unsummarised[:len(unsummarised)-keep]
+	if len(older) == 0 {
+		return nil
+	}
+
+	var b strings.Builder
+	condensed := 0
+	if previous != nil {
+		b.WriteString("Summary of the conversation so far:\n" + previous.Summary + "\n\nMessages since then:\n")
+		condensed = previous.Messages
+	} else {
+		b.WriteString("Messages:\n")
+	}
+	for _, m := range older {
+		role := "Patient"
+		if m.IsAi {
+			role = "Assistant"
+		}
+		b.WriteString(role + ": " + strings.TrimSpace(m.Text) + "\n")
+	}
+
+	res, err := u.model.Generate(ctx, ai.Request{
+		System:    summaryInstruction,
+		Prompt:    b.String(),
+		MaxTokens: summaryMaxTokens,
+	})
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(res.Text) == "" {
+		return errors.New("model returned an empty summary")
+	}
+	return u.repo.SaveSummary(ctx, &domain.ConversationSummary{
+		ConversationId: conversationId,
+		Summary:        strings.TrimSpace(res.Text),
+		UpToMessageId:  older[len(older)-1].Id,
+		Messages:       condensed + len(older),
+		Model:          ai.ModelOf(u.model, res),
+	})
+}
+
+// after returns the messages that came after id.
+func after(messages []domain.Message, id int) []domain.Message {
+	for i, m := range messages {
+		if m.Id > id {
+			return messages[i:]
+		}
+	}
+	return nil
+}
+
+// withSummary gives the model the summary of the turns it no longer sees.
+func withSummary(system string, summary *domain.ConversationSummary) string {
+	return strings.TrimSpace(system + "\n\nSummary of the earlier part of this conversation, which is not repeated below:\n" + summary.Summary)
+}
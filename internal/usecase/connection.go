@@ -2,9 +2,13 @@ package usecase
 
 import (
 	"database/sql"
+	configs "testDeployment/internal/common/config"
+	"testDeployment/internal/domain"
 	repo "testDeployment/internal/repository"
 	"testDeployment/internal/repository/postgres"
 	"testDeployment/pkg/Bot"
+	"testDeployment/pkg/ai"
+	"testDeployment/pkg/sms"
 )
 
 type IUseCase interface {
@@ -13,52 +17,239 @@ type IUseCase interface {
 	IDoctorUseCase() IDoctorUsecase
 	IScheduleUseCase() IScheduleUseCase
 	IFactUseCase() IFactUseCase
+	IChatUseCase() IChatUseCase
+	IAnalysisUseCase() IAnalysisUseCase
+	ISkinRecordUseCase() ISkinRecordUseCase
+	IPromptUseCase() IPromptUseCase
+	ISafetyUseCase() ISafetyUseCase
+	IUsageUseCase() IUsageUseCase
+	IDrugUseCase() IDrugUseCase
+	IAgentUseCase() IAgentUseCase
+	IReportUseCase() IReportUseCase
+	IModerationUseCase() IModerationUseCase
+	IAuthUseCase() IAuthUseCase
+	IVerificationUseCase() IVerificationUseCase
 }
 type SUsecase struct {
 	connection map[string]interface{}
 }
 
 const (
-	_UseCase         = "Use_Case"
-	_NewsUseCase     = "news_use_case"
-	_DoctorUseCase   = "doctor_use_case"
-	_ScheduleUseCase = "schedule_use_case"
-	_FactUseCase     = "fact_use_case"
+	_UseCase             = "Use_Case"
+	_NewsUseCase         = "news_use_case"
+	_DoctorUseCase       = "doctor_use_case"
+	_ScheduleUseCase     = "schedule_use_case"
+	_FactUseCase         = "fact_use_case"
+	_ChatUseCase         = "chat_use_case"
+	_AnalysisUseCase     = "analysis_use_case"
+	_SkinRecordUseCase   = "skin_record_use_case"
+	_PromptUseCase       = "prompt_use_case"
+	_SafetyUseCase       = "safety_use_case"
+	_UsageUseCase        = "usage_use_case"
+	_DrugUseCase         = "drug_use_case"
+	_AgentUseCase        = "agent_use_case"
+	_ReportUseCase       = "report_use_case"
+	_ModerationUseCase   = "moderation_use_case"
+	_AuthUseCase         = "auth_use_case"
+	_VerificationUseCase = "verification_use_case"
 )
 
 func New(
 	db *sql.DB,
 	bot Bot.Bot,
+	model ai.Provider,
+	cfg configs.Ai,
+	cacheCfg configs.Cache,
+	imageCfg configs.Image,
+	moderationCfg configs.Moderation,
+	newsCfg configs.News,
+	jwtCfg configs.JWT,
+	mailCfg configs.Mail,
+	mailer sms.Mailer,
+	usage IUsageUseCase,
 ) IUseCase {
 	var connections = make(map[string]interface{})
-	connections[_UseCase] = NewUserUsecase(
-		repo.NewRepo(db,
-			bot),
+	connections[_UsageUseCase] = usage
+	doctors := postgres.NewDoctorRepository(
+		db,
+		bot,
+	)
+	promptRepo := postgres.NewPromptRepository(
+		db,
+		bot,
+	)
+	profiles := postgres.NewProfileRepository(
+		db,
+		bot,
+	)
+	// The env prompts stay as the built-in versions until one is published.
+	prompts := NewPromptUseCase(
+		promptRepo,
+		profiles,
+		map[string]string{
+			domain.PromptSystem:     cfg.Instruction,
+			domain.PromptImage:      cfg.Prompt,
+			domain.PromptAnalysis:   analysisBasePrompt,
+			domain.PromptComparison: comparisonBasePrompt,
+		},
+		bot,
+	)
+	connections[_PromptUseCase] = prompts
+	users := repo.NewRepo(db,
+		bot)
+	catalog := NewUserUsecase(
+		users,
+		bot,
+	)
+	connections[_UseCase] = catalog
+	verification := NewVerificationUseCase(
+		postgres.NewEmailVerificationRepository(
+			db,
+			bot,
+		),
+		users,
+		mailer,
+		mailCfg,
+		bot,
+	)
+	connections[_VerificationUseCase] = verification
+	connections[_AuthUseCase] = NewAuthUseCase(
+		postgres.NewRefreshTokenRepository(
+			db,
+			bot,
+		),
+		postgres.NewTokenRevocationRepository(
+			db,
+			bot,
+		),
+		jwtCfg,
 		bot,
 	)
 	connections[_NewsUseCase] = NewNewsUseCase(
-		nil,
+		postgres.NewNewsSummaryRepository(
+			db,
+			bot,
+		),
+		profiles,
+		model,
+		newsCfg.SummaryWorkers,
+		newsCfg.SummaryQueue,
+		bot,
+	)
+	doctorUc := NewDoctorUseCase(
+		doctors,
+		bot,
+	)
+	connections[_DoctorUseCase] = doctorUc
+	safety := NewSafetyUseCase(
+		doctorUc,
+		cfg.ReferralSpecialty,
+		bot,
+	)
+	connections[_SafetyUseCase] = safety
+	drugs := NewDrugUseCase(
+		postgres.NewDrugRepository(
+			db,
+			bot,
+		),
+		cfg.GroundingDrugs,
 		bot,
 	)
-	connections[_DoctorUseCase] = NewDoctorUseCase(
-		postgres.NewDoctorRepository(
+	connections[_DrugUseCase] = drugs
+	moderation := NewModerationUseCase(
+		postgres.NewModerationRepository(
 			db,
 			bot,
 		),
+		model,
+		moderationCfg.MaxChars,
+		moderationCfg.Classify,
+		moderationCfg.Images,
 		bot,
 	)
-	connections[_ScheduleUseCase] = NewScheduleRepo(
+	connections[_ModerationUseCase] = moderation
+	schedule := NewScheduleRepo(
 		postgres.NewSchedule(
 			db,
 			bot,
 		),
 		bot,
 	)
+	connections[_ScheduleUseCase] = schedule
 	connections[_FactUseCase] = NewFactUseCase(
 		postgres.NewFactRepository(
 			db,
 			bot,
 		),
+		model,
+		bot,
+	)
+	chats := postgres.NewChatRepository(
+		db,
+		bot,
+	)
+	connections[_ChatUseCase] = NewChatUseCase(
+		chats,
+		model,
+		prompts,
+		drugs,
+		safety,
+		moderation,
+		domain.SummaryPolicy{
+			AfterTurns:  cfg.SummaryAfterTurns,
+			AfterTokens: cfg.SummaryAfterTokens,
+			KeepTurns:   cfg.SummaryKeepTurns,
+		},
+		bot,
+	)
+	connections[_AgentUseCase] = NewAgentUseCase(
+		model,
+		prompts,
+		doctorUc,
+		catalog,
+		schedule,
+		verification,
+		safety,
+		moderation,
+		bot,
+	)
+	var cacheTier repo.IAnalysisCacheRepository
+	if cacheCfg.Postgres {
+		cacheTier = postgres.NewAnalysisCacheRepository(
+			db,
+			bot,
+		)
+	}
+	analysis := NewAnalysisUseCase(
+		model,
+		doctors,
+		prompts,
+		drugs,
+		safety,
+		moderation,
+		NewAnalysisCache(
+			cacheTier,
+			cacheCfg,
+			bot,
+		),
+		bot,
+	)
+	connections[_AnalysisUseCase] = analysis
+	records := postgres.NewSkinRecordRepository(
+		db,
+		bot,
+	)
+	connections[_SkinRecordUseCase] = NewSkinRecordUseCase(
+		records,
+		analysis,
+		bot,
+	)
+	connections[_ReportUseCase] = NewReportUseCase(
+		chats,
+		records,
+		profiles,
+		promptRepo,
+		imageCfg.JPEGQuality,
 		bot,
 	)
 	return &SUsecase{
@@ -81,3 +272,39 @@ func (c *SUsecase) IScheduleUseCase() IScheduleUseCase {
 func (c *SUsecase) IFactUseCase() IFactUseCase {
 	return c.connection[_FactUseCase].(IFactUseCase)
 }
+func (c *SUsecase) IChatUseCase() IChatUseCase {
+	return c.connection[_ChatUseCase].(IChatUseCase)
+}
+func (c *SUsecase) IAnalysisUseCase() IAnalysisUseCase {
+	return c.connection[_AnalysisUseCase].(IAnalysisUseCase)
+}
+func (c *SUsecase) ISkinRecordUseCase() ISkinRecordUseCase {
+	return c.connection[_SkinRecordUseCase].(ISkinRecordUseCase)
+}
+func (c *SUsecase) IPromptUseCase() IPromptUseCase {
+	return c.connection[_PromptUseCase].(IPromptUseCase)
+}
+func (c *SUsecase) ISafetyUseCase() ISafetyUseCase {
+	return c.connection[_SafetyUseCase].(ISafetyUseCase)
+}
+func (c *SUsecase) IUsageUseCase() IUsageUseCase {
+	return c.connection[_UsageUseCase].(IUsageUseCase)
+}
+func (c *SUsecase) IDrugUseCase() IDrugUseCase {
+	return c.connection[_DrugUseCase].(IDrugUseCase)
+}
+func (c *SUsecase) IAgentUseCase() IAgentUseCase {
+	return c.connection[_AgentUseCase].(IAgentUseCase)
+}
+func (c *SUsecase) IReportUseCase() IReportUseCase {
+	return c.connection[_ReportUseCase].(IReportUseCase)
+}
+func (c *SUsecase) IModerationUseCase() IModerationUseCase {
+	return c.connection[_ModerationUseCase].(IModerationUseCase)
+}
+func (c *SUsecase) IAuthUseCase() IAuthUseCase {
+	return c.connection[_AuthUseCase].(IAuthUseCase)
+}
+func (c *SUsecase) IVerificationUseCase() IVerificationUseCase {
+	return c.connection[_VerificationUseCase].(IVerificationUseCase)
+}
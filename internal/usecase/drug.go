@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/repository"
+	"testDeployment/pkg/Bot"
+	"time"
+	"unicode"
+)
+
+const (
+	groundingTimeout = 2 * time.Second
+	// Words are cut to this many letters and matched as prefixes, a crude
+	// stemmer that also copes with Uzbek and Russian endings.
+	groundingStemLength = 6
+	groundingMinWord    = 3
+	groundingMaxTerms   = 12
+	groundingMaxDesc    = 200
+)
+
+// groundingStopWords are frequent words of patient questions that say
+// nothing about the product wanted.
+var groundingStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "what": true, "which": true,
+	"can": true, "should": true, "use": true, "have": true, "has": true, "my": true,
+	"you": true, "your": true, "this": true, "that": true, "how": true, "skin": true,
+	"from": true, "about": true, "does": true, "get": true, "rid": true,
+	"что": true, "как": true, "для": true, "мне": true, "меня": true, "можно": true,
+	"кожа": true, "кожи": true, "это": true, "есть": true,
+	"uchun": true, "qanday": true, "menga": true, "nima": true, "bilan": true, "teri": true,
+	"terim": true, "bor": true, "mumkin": true,
+}
+
+type drugUseCase struct {
+	repo  repository.IDrugRepository
+	limit int
+	bot   Bot.Bot
+}
+
+// NewDrugUseCase grounds AI answers in at most limit catalog drugs per
+// request; limit 0 turns the grounding off.
+func NewDrugUseCase(repo repository.IDrugRepository, limit int, bot Bot.Bot) IDrugUseCase {
+	return &drugUseCase{
+		repo:  repo,
+		limit: limit,
+		bot:   bot,
+	}
+}
+
+func (u *drugUseCase) Get(ctx context.Context, id int) (*domain.Drug, error) {
+	return u.repo.Get(ctx, id)
+}
+
+// Ground looks up the catalog drugs matching query and adds them to system
+// with the rule to recommend nothing else. A failed lookup leaves the model
+// without a catalog rather than failing the answer.
+func (u *drugUseCase) Ground(ctx context.Context, system, query string) *domain.DrugGrounding {
+	grounding := &domain.DrugGrounding{System: system}
+	if u.limit <= 0 {
+		return grounding
+	}
+
+	if tsquery := groundingQuery(query); tsquery != "" {
+		ctx, cancel := context.WithTimeout(ctx, groundingTimeout)
+		defer cancel()
+		drugs, err := u.repo.Search(ctx, tsquery, u.limit)
+		if err == nil {
+			grounding.Drugs = drugs
+		}
+	}
+	grounding.System = withCatalog(system, grounding.Drugs)
+	return grounding
+}
+
+// withCatalog appends the catalog rule, and the catalog when there is one,
+// to the system prompt.
+func withCatalog(system string, drugs []*domain.Drug) string {
+	var sb strings.Builder
+	sb.WriteString(strings.TrimSpace(system))
+	if len(drugs) == 0 {
+		sb.WriteString("\n\nDo not name any medicine or skin care product brands. Give general advice and suggest asking a pharmacist or dermatologist for a product.")
+		return sb.String()
+	}
+
+	sb.WriteString("\n\nWhen you recommend a medicine or skin care product, recommend only products from this pharmacy catalog and cite each one right after its name as [drug:ID], e.g. \"Name [drug:12]\". Never name a product that is not listed. If none of them fits, give general advice instead.\nCatalog:\n")
+	for _, d := range drugs {
+		sb.WriteString(fmt.Sprintf("[drug:%s] %s", d.Id, d.Name))
+		if len(d.Type) > 0 {
+			sb.WriteString(" (" + strings.Join(d.Type, ", ") + ")")
+		}
+		if desc := truncateRunes(strings.TrimSpace(d.Description), groundingMaxDesc); desc != "" {
+			sb.WriteString(": " + desc)
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// groundingQuery turns a patient's question into a to_tsquery expression
+// that matches any of its meaningful words by prefix, e.g. "acne:* |
+// cream:*". Only letters and digits survive, so the expression is always
+// valid.
+func groundingQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	seen := make(map[string]bool)
+	var terms []string
+	for _, w := range words {
+		if len([]rune(w)) < groundingMinWord || groundingStopWords[w] {
+			continue
+		}
+		w = truncateRunes(w, groundingStemLength)
+		if seen[w] {
+			continue
+		}
+		seen[w] = true
+		terms = append(terms, w+":*")
+		if len(terms) == groundingMaxTerms {
+			break
+		}
+	}
+	return strings.Join(terms, " | ")
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n])
+}
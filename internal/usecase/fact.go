@@ -5,17 +5,20 @@ import (
 	"testDeployment/internal/delivery/dto"
 	"testDeployment/internal/repository"
 	"testDeployment/pkg/Bot"
+	"testDeployment/pkg/ai"
 )
 
 type factUseCase struct {
-	repo repository.IFactRepository
-	bot  Bot.Bot
+	repo  repository.IFactRepository
+	model ai.Provider
+	bot   Bot.Bot
 }
 
-func NewFactUseCase(repo repository.IFactRepository, bot Bot.Bot) IFactUseCase {
+func NewFactUseCase(repo repository.IFactRepository, model ai.Provider, bot Bot.Bot) IFactUseCase {
 	return &factUseCase{
-		repo: repo,
-		bot:  bot,
+		repo:  repo,
+		model: model,
+		bot:   bot,
 	}
 }
 
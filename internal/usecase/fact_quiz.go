@@ -0,0 +1,225 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testDeployment/internal/delivery/dto"
+	"testDeployment/internal/domain"
+	"testDeployment/pkg/ai"
+)
+
+const (
+	quizAttempts         = 3
+	quizMaxTokens        = 2048
+	quizDefaultQuestions = 5
+	quizMaxQuestions     = 10
+	quizChoices          = 4
+	// Edited questions may have fewer or more choices than generated ones.
+	quizMinChoices = 2
+	quizMaxChoices = 6
+
+	quizInstruction = "You write quiz questions for the readers of a skin health app. Every question must be answerable " +
+		"from the fact alone, test what it teaches rather than trivia, and have exactly one correct choice; the wrong " +
+		"choices must be plausible. Write in the language of the fact. Reply with JSON only."
+)
+
+// GenerateQuizDraft asks the model for count multiple-choice questions about
+// a fact and stores them as a draft. Nothing reaches the quiz until an
+// editor approves it.
+func (u factUseCase) GenerateQuizDraft(ctx context.Context, factId int, count int) (*domain.QuizDraft, error) {
+	if count == 0 {
+		count = quizDefaultQuestions
+	}
+	if count < 1 || count > quizMaxQuestions {
+		return nil, fmt.Errorf("%w: between 1 and %d questions can be generated", domain.ErrInvalidQuiz, quizMaxQuestions)
+	}
+	fact, err := u.repo.GetFact(ctx, factId)
+	if err != nil {
+		return nil, err
+	}
+
+	prompt := fmt.Sprintf("Write %d questions with %d choices each about this fact.\n\nTitle: %s\n\n%s",
+		count, quizChoices, fact.Title, fact.Content)
+	req := ai.Request{
+		System:    quizInstruction,
+		Prompt:    prompt,
+		Schema:    quizSchema(),
+		MaxTokens: quizMaxTokens,
+	}
+	var (
+		questions []domain.QuizQuestion
+		model     string
+	)
+	for attempt := 0; ; attempt++ {
+		res, err := u.model.Generate(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if questions, err = parseQuiz(res.Text, count); err == nil {
+			model = ai.ModelOf(u.model, res)
+			break
+		}
+		if attempt == quizAttempts-1 {
+			return nil, fmt.Errorf("%w: %v", domain.ErrMalformedQuiz, err)
+		}
+		req.Prompt = prompt + "\n\nYour previous reply was rejected (" + err.Error() + "). Reply again with valid JSON only."
+	}
+
+	draft := &domain.QuizDraft{
+		FactId:    fact.Id,
+		Questions: questions,
+		Model:     model,
+	}
+	if err := u.repo.CreateQuizDraft(ctx, draft); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+func (u factUseCase) GetQuizDraft(ctx context.Context, id int) (*domain.QuizDraft, error) {
+	return u.repo.GetQuizDraft(ctx, id)
+}
+
+func (u factUseCase) GetQuizDrafts(ctx context.Context, status string, factId int) ([]*domain.QuizDraft, error) {
+	if status != "" && !contains(domain.QuizDraftStatuses, status) {
+		return nil, fmt.Errorf("%w: unknown status %q", domain.ErrInvalidQuiz, status)
+	}
+	return u.repo.GetQuizDrafts(ctx, status, factId)
+}
+
+// EditQuizDraft lets the editor correct the questions before approving.
+func (u factUseCase) EditQuizDraft(ctx context.Context, id int, questions []domain.QuizQuestion) (*domain.QuizDraft, error) {
+	questions = trimQuiz(questions)
+	if err := validateQuiz(questions, quizMinChoices, quizMaxChoices); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidQuiz, err)
+	}
+	return u.repo.EditQuizDraft(ctx, id, questions)
+}
+
+// ApproveQuizDraft adds the questions of a pending draft to its fact. When
+// that fails the draft is pending again so it can be approved once more.
+func (u factUseCase) ApproveQuizDraft(ctx context.Context, id int) (*domain.QuizDraft, error) {
+	draft, err := u.repo.SetQuizDraftStatus(ctx, id, domain.QuizDraftPending, domain.QuizDraftApproved)
+	if err != nil {
+		return nil, err
+	}
+	questions := make([]dto.FactQuestions, len(draft.Questions))
+	for i, q := range draft.Questions {
+		questions[i] = dto.FactQuestions{FactId: draft.FactId, Question: q.Question}
+		for _, c := range q.Choices {
+			questions[i].Choices = append(questions[i].Choices, dto.Choices{Content: c.Content, IsTrue: c.IsTrue})
+		}
+	}
+	if err := u.CreateQuestion(ctx, draft.FactId, &questions); err != nil {
+		if _, reopenErr := u.repo.SetQuizDraftStatus(ctx, id, domain.QuizDraftApproved, domain.QuizDraftPending); reopenErr != nil {
+			u.bot.SendErrorNotification(fmt.Errorf("quiz draft %d approved without its questions: %w", id, reopenErr))
+		}
+		return nil, err
+	}
+	if err := u.repo.UpdateQuestionCount(ctx, draft.FactId); err != nil {
+		return nil, err
+	}
+	return draft, nil
+}
+
+func (u factUseCase) RejectQuizDraft(ctx context.Context, id int) (*domain.QuizDraft, error) {
+	return u.repo.SetQuizDraftStatus(ctx, id, domain.QuizDraftPending, domain.QuizDraftRejected)
+}
+
+func quizSchema() *ai.Schema {
+	return &ai.Schema{
+		Type: ai.TypeObject,
+		Properties: map[string]*ai.Schema{
+			"questions": {
+				Type: ai.TypeArray,
+				Items: &ai.Schema{
+					Type: ai.TypeObject,
+					Properties: map[string]*ai.Schema{
+						"question": {Type: ai.TypeString},
+						"choices": {
+							Type: ai.TypeArray,
+							Items: &ai.Schema{
+								Type: ai.TypeObject,
+								Properties: map[string]*ai.Schema{
+									"content": {Type: ai.TypeString},
+									"is_true": {Type: ai.TypeBoolean},
+								},
+								Required: []string{"content", "is_true"},
+							},
+						},
+					},
+					Required: []string{"question", "choices"},
+				},
+			},
+		},
+		Required: []string{"questions"},
+	}
+}
+
+// parseQuiz accepts exactly count questions of quizChoices choices each.
+func parseQuiz(text string, count int) ([]domain.QuizQuestion, error) {
+	var quiz struct {
+		Questions []domain.QuizQuestion `json:"questions"`
+	}
+	if err := json.Unmarshal([]byte(stripCodeFence(text)), &quiz); err != nil {
+		return nil, err
+	}
+	questions := trimQuiz(quiz.Questions)
+	if len(questions) != count {
+		return nil, fmt.Errorf("expected %d questions, got %d", count, len(questions))
+	}
+	if err := validateQuiz(questions, quizChoices, quizChoices); err != nil {
+		return nil, err
+	}
+	return questions, nil
+}
+
+func trimQuiz(questions []domain.QuizQuestion) []domain.QuizQuestion {
+	for i := range questions {
+		questions[i].Question = strings.TrimSpace(questions[i].Question)
+		for j := range questions[i].Choices {
+			questions[i].Choices[j].Content = strings.TrimSpace(questions[i].Choices[j].Content)
+		}
+	}
+	return questions
+}
+
+// validateQuiz checks that every question has text, between minChoices and
+// maxChoices distinct choices and exactly one correct one.
+func validateQuiz(questions []domain.QuizQuestion, minChoices, maxChoices int) error {
+	if len(questions) == 0 {
+		return errors.New("no questions")
+	}
+	for i, q := range questions {
+		n := "question " + strconv.Itoa(i+1)
+		if q.Question == "" {
+			return errors.New(n + " is empty")
+		}
+		if len(q.Choices) < minChoices || len(q.Choices) > maxChoices {
+			return fmt.Errorf("%s has %d choices, expected %d to %d", n, len(q.Choices), minChoices, maxChoices)
+		}
+		correct := 0
+		seen := make(map[string]bool, len(q.Choices))
+		for _, c := range q.Choices {
+			key := strings.ToLower(c.Content)
+			if key == "" {
+				return errors.New(n + " has an empty choice")
+			}
+			if seen[key] {
+				return fmt.Errorf("%s repeats the choice %q", n, c.Content)
+			}
+			seen[key] = true
+			if c.IsTrue {
+				correct++
+			}
+		}
+		if correct != 1 {
+			return fmt.Errorf("%s has %d correct choices, expected exactly 1", n, correct)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,271 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/repository"
+	"testDeployment/pkg/Bot"
+	"testDeployment/pkg/ai"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	recordRejectionTimeout = 5 * time.Second
+	moderationMaxTokens    = 64
+	// Messages this short ("hi", "thanks a lot") are small talk the
+	// assistant handles itself; they are not worth a classification.
+	smallTalkWords = 3
+
+	topicOnTopic  = "on_topic"
+	topicUnclear  = "unclear"
+	topicOffTopic = "off_topic"
+
+	subjectSkin    = "skin"
+	subjectUnclear = "unclear"
+	subjectNotSkin = "not_skin"
+
+	topicInstruction = "You screen messages sent to the assistant of a dermatology app. The assistant answers questions " +
+		"about skin, hair, nails, cosmetics and skin care products, symptoms and health in general, doctors and " +
+		"appointments, and the app itself. Say off_topic only when the message clearly asks for something else, " +
+		"such as code, homework, essays, translations, politics, finance or entertainment. Follow-up questions, " +
+		"greetings and anything you are unsure about are on_topic or unclear. Reply with JSON only."
+	subjectInstruction = "You screen photos uploaded to a dermatology app. Say skin when the photo shows human skin, hair, " +
+		"nails, lips or the inside of the mouth, even blurred, close up or partly covered. Say not_skin only when it " +
+		"clearly shows none of these, such as a document, screenshot, food, a landscape, an object or an animal. " +
+		"Say unclear otherwise. Reply with JSON only."
+)
+
+// injectionPatterns catch attempts to override the system prompt, in
+// English, Russian and Uzbek (Latin).
+var injectionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(ignore|disregard|forget|override|bypass)\s+(all\s+(of\s+)?|any\s+)?(the\s+|your\s+)?(previous|prior|above|earlier|preceding|system|original)\s+(instructions|prompts?|rules|guidelines|directives)`),
+	regexp.MustCompile(`(?i)(ignore|disregard|forget|override|bypass)\s+(all|any|your)\s+(instructions|prompts?|rules|guidelines|directives)`),
+	regexp.MustCompile(`(?i)(reveal|show|print|repeat|output|tell me)\s+(me\s+)?(your|the)\s+(system\s+)?(prompt|instructions|rules)`),
+	regexp.MustCompile(`(?i)\bsystem\s+prompt\b|\bdeveloper\s+mode\b|\bjailbreak|\bDAN\s+mode\b|do anything now`),
+	regexp.MustCompile(`(?i)you\s+are\s+(now|no longer)\s+|from\s+now\s+on\s+you\s+(are|will)|pretend\s+(to\s+be|you\s+are)`),
+	regexp.MustCompile(`(?im)<\|?(im_start|im_end|system|endoftext)\|?>|\[/?(INST|SYS)\]|^\s*#{2,}\s*(system|instruction)`),
+	regexp.MustCompile(`(?i)(игнорируй|забудь|проигнорируй)\S*\s+(все\s+)?(предыдущ\S*|прошл\S*|свои|системн\S*)?\s*(инструкци|правил|указани)|системн\S*\s+промпт|ты\s+теперь\s+`),
+	regexp.MustCompile(`(?i)(oldingi|avvalgi)\s+(ko['‘ʻ]rsatma|qoida)\S*\s+(e['’ʼ]tiborsiz|unut)|ko['‘ʻ]rsatmalarni\s+unut|tizim\s+prompt`),
+}
+
+// PII is redacted from what the patient wrote before it is sent to the
+// model or stored.
+var (
+	emailPattern = regexp.MustCompile(`(?i)[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}`)
+	// phoneCandidate finds digit runs with the usual separators; only those
+	// with 9 to 15 digits are numbers, so doses are left alone.
+	phoneCandidate = regexp.MustCompile(`\+?\d[\d \-().]{6,}\d`)
+	// datePattern is a YYYY-MM-DD or DD.MM.YYYY date, which with a time
+	// after it has as many digits as a phone number.
+	datePattern = regexp.MustCompile(`\b\d{4}-(0?[1-9]|1[0-2])-(0?[1-9]|[12]\d|3[01])\b|\b(0?[1-9]|[12]\d|3[01])\.(0?[1-9]|1[0-2])\.\d{4}\b`)
+	// passportPattern is the Uzbek and most international format (two
+	// letters, 7 digits), or a document number right after the word
+	// passport, optionally with "no" or "№".
+	passportPattern = regexp.MustCompile(`\b[A-Z]{2} ?\d{7}\b|(?i:passport|паспорт|pasport)\S*[\s:#.,-]*(?:(?i:no)\.?|№)?[\s:#.,-]*[A-Z]{1,2} ?\d{6,9}\b`)
+)
+
+// onTopicHint is wording that is plainly about skin, health or the app;
+// such messages skip the classification.
+var onTopicHint = regexp.MustCompile(`(?i)skin|derma|rash|acne|pimple|mole|itch|eczema|psoria|wart|scar|spot|wrinkle|sunburn|burn|allerg|cream|ointment|lotion|serum|sunscreen|spf|hair|nail|doctor|clinic|appointment|drug|medic|pill|symptom|pain|swell|\b(red|dry|oily|face|lips?)\b|кож|сып|прыщ|угр|родин|зуд|чеш|экзем|пятн|крем|мазь|волос|ногт|врач|доктор|лекарств|таблет|симптом|боль|лиц|teri|toshma|husnbuzar|xol|qichi|dog'|krem|malham|soch|tirnoq|shifokor|doktor|dori|og'riq|yuz`)
+
+type moderationUseCase struct {
+	repo     repository.IModerationRepository
+	model    ai.Provider
+	maxChars int
+	classify bool
+	images   bool
+	bot      Bot.Bot
+}
+
+// NewModerationUseCase rejects texts longer than maxChars runes. classify
+// and images turn on the model checks for off-topic texts and non-skin
+// photos, which each cost a model call.
+func NewModerationUseCase(repo repository.IModerationRepository, model ai.Provider, maxChars int, classify, images bool, bot Bot.Bot) IModerationUseCase {
+	return &moderationUseCase{
+		repo:     repo,
+		model:    model,
+		maxChars: maxChars,
+		classify: classify,
+		images:   images,
+		bot:      bot,
+	}
+}
+
+// CheckText is run on what the patient wrote before it reaches the model.
+// It returns the text with phone numbers, emails and passport numbers
+// redacted, or a *domain.RejectionError when the text is too long, tries to
+// override the instructions or is off-topic.
+func (u *moderationUseCase) CheckText(ctx context.Context, caller domain.Caller, text string) (string, error) {
+	if u.maxChars > 0 && utf8.RuneCountInString(text) > u.maxChars {
+		return "", u.reject(ctx, caller, domain.RejectTooLong,
+			fmt.Sprintf("The message is too long, please keep it under %d characters.", u.maxChars))
+	}
+	for _, re := range injectionPatterns {
+		if re.MatchString(text) {
+			return "", u.reject(ctx, caller, domain.RejectInjection,
+				"The message tries to change how the assistant works. Please ask about your skin instead.")
+		}
+	}
+	text = redactPII(text)
+	if u.offTopic(ctx, text) {
+		return "", u.reject(ctx, caller, domain.RejectOffTopic,
+			"The assistant only answers questions about skin, hair, nails and your health.")
+	}
+	return text, nil
+}
+
+// CheckImages rejects with a *domain.RejectionError when one of the photos
+// clearly shows no skin. A photo the model is unsure about passes.
+func (u *moderationUseCase) CheckImages(ctx context.Context, caller domain.Caller, images ...ai.Image) error {
+	if !u.images {
+		return nil
+	}
+	for i, image := range images {
+		var verdict struct {
+			Subject string `json:"subject"`
+		}
+		if !u.screen(ctx, ai.Request{
+			System: subjectInstruction,
+			Images: []ai.Image{image},
+			Prompt: "What does the photo show?",
+			Schema: &ai.Schema{
+				Type: ai.TypeObject,
+				Properties: map[string]*ai.Schema{
+					"subject": {Type: ai.TypeString, Enum: []string{subjectSkin, subjectUnclear, subjectNotSkin}},
+				},
+				Required: []string{"subject"},
+			},
+		}, &verdict) {
+			continue
+		}
+		if verdict.Subject == subjectNotSkin {
+			message := "The photo does not seem to show skin. Please upload a clear photo of the affected area."
+			if len(images) > 1 {
+				message = fmt.Sprintf("Photo %d does not seem to show skin. Please upload clear photos of the affected area.", i+1)
+			}
+			return u.reject(ctx, caller, domain.RejectNotSkin, message)
+		}
+	}
+	return nil
+}
+
+func (u *moderationUseCase) GetTopRejected(ctx context.Context, filter domain.UsageFilter, limit int) ([]*domain.RejectionConsumer, error) {
+	return u.repo.GetTopRejected(ctx, filter, limit)
+}
+
+// offTopic asks the model about texts that are neither small talk nor
+// plainly about skin or health.
+func (u *moderationUseCase) offTopic(ctx context.Context, text string) bool {
+	if !u.classify || len(strings.Fields(text)) <= smallTalkWords || onTopicHint.MatchString(text) {
+		return false
+	}
+	var verdict struct {
+		Topic string `json:"topic"`
+	}
+	if !u.screen(ctx, ai.Request{
+		System: topicInstruction,
+		Prompt: "Message:\n" + text,
+		Schema: &ai.Schema{
+			Type: ai.TypeObject,
+			Properties: map[string]*ai.Schema{
+				"topic": {Type: ai.TypeString, Enum: []string{topicOnTopic, topicUnclear, topicOffTopic}},
+			},
+			Required: []string{"topic"},
+		},
+	}, &verdict) {
+		return false
+	}
+	return verdict.Topic == topicOffTopic
+}
+
+// screen runs a classification into verdict. The checks fail open: when the
+// model cannot answer, the request goes on and the failure is reported.
+func (u *moderationUseCase) screen(ctx context.Context, req ai.Request, verdict interface{}) bool {
+	req.MaxTokens = moderationMaxTokens
+	res, err := u.model.Generate(ctx, req)
+	if err == nil {
+		err = json.Unmarshal([]byte(stripCodeFence(res.Text)), verdict)
+	}
+	if err != nil {
+		u.bot.SendErrorNotification(fmt.Errorf("moderation check skipped: %w", err))
+		return false
+	}
+	return true
+}
+
+// reject counts the rejection against the caller and returns its error. The
+// count outlives a cancelled request and a failure to store it is only
+// reported.
+func (u *moderationUseCase) reject(ctx context.Context, caller domain.Caller, reason, message string) error {
+	store, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordRejectionTimeout)
+	defer cancel()
+	_ = u.repo.Create(store, &domain.AIRejection{
+		UserId:   caller.UserID,
+		GuestId:  caller.GuestID,
+		Endpoint: ai.TagFrom(ctx).Endpoint,
+		Reason:   reason,
+	})
+	return domain.Reject(reason, message)
+}
+
+// redactPII replaces emails, passport numbers and phone numbers with
+// placeholders the model understands.
+func redactPII(text string) string {
+	text = emailPattern.ReplaceAllString(text, "[email]")
+	text = passportPattern.ReplaceAllString(text, "[passport]")
+	var b strings.Builder
+	last := 0
+	for _, loc := range phoneLocations(text) {
+		b.WriteString(text[last:loc[0]] + "[phone]")
+		last = loc[1]
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// redactedPhones returns the phone numbers redactPII replaces in text, each
+// once, in order.
+func redactedPhones(text string) []string {
+	text = emailPattern.ReplaceAllString(text, "[email]")
+	text = passportPattern.ReplaceAllString(text, "[passport]")
+	var phones []string
+	for _, loc := range phoneLocations(text) {
+		if s := text[loc[0]:loc[1]]; !slices.Contains(phones, s) {
+			phones = append(phones, s)
+		}
+	}
+	return phones
+}
+
+// phoneLocations returns where the phone numbers of text are. Dates and
+// times are not numbers even when they have as many digits: a run with a
+// date in it or followed by ':' is skipped.
+func phoneLocations(text string) [][]int {
+	var found [][]int
+	for _, loc := range phoneCandidate.FindAllStringIndex(text, -1) {
+		s := text[loc[0]:loc[1]]
+		if strings.HasPrefix(text[loc[1]:], ":") || datePattern.MatchString(s) || !isPhone(s) {
+			continue
+		}
+		found = append(found, loc)
+	}
+	return found
+}
+
+// isPhone reports whether a phoneCandidate match has as many digits as a
+// phone number.
+func isPhone(s string) bool {
+	digits := 0
+	for _, r := range s {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	return digits >= 9 && digits <= 15
+}
@@ -12,23 +12,50 @@ import (
 	"strings"
 	"sync"
 	"testDeployment/internal/domain"
+	"testDeployment/internal/repository"
 	"testDeployment/pkg/Bot"
+	"testDeployment/pkg/ai"
 	"testDeployment/pkg/utils"
 	"time"
 )
 
 type newsUseCase struct {
-	bot    Bot.Bot
-	client *http.Client
+	summaries repository.INewsSummaryRepository
+	profiles  repository.IProfileRepository
+	model     ai.Provider
+	// queue feeds the summary workers; pending holds the jobs queued or
+	// running so an article is not summarised twice at once.
+	queue   chan newsSummaryJob
+	pending sync.Map
+	bot     Bot.Bot
+	client  *http.Client
 }
 
-func NewNewsUseCase(_ interface{}, bot Bot.Bot) INewsUseCase {
-	return &newsUseCase{
-		bot: bot,
+// NewNewsUseCase starts workers goroutines that summarise the fetched
+// articles in the background, with room for queue articles waiting.
+func NewNewsUseCase(
+	summaries repository.INewsSummaryRepository,
+	profiles repository.IProfileRepository,
+	model ai.Provider,
+	workers, queue int,
+	bot Bot.Bot,
+) INewsUseCase {
+	u := &newsUseCase{
+		summaries: summaries,
+		profiles:  profiles,
+		model:     model,
+		bot:       bot,
 		client: &http.Client{
 			Timeout: 20 * time.Second,
 		},
 	}
+	if workers > 0 {
+		u.queue = make(chan newsSummaryJob, queue)
+		for i := 0; i < workers; i++ {
+			go u.summariseQueued()
+		}
+	}
+	return u
 }
 
 // ──────────────────────────────────────────────
@@ -91,9 +118,6 @@ func (u *newsUseCase) fetchEuropePMC(query string, pageSize, offset int) ([]*dom
 	var articles []*domain.NewWithSinglePhoto
 	for _, r := range raw.ResultList.Result {
 		abstract := r.Abstract
-		if len(abstract) > 500 {
-			abstract = abstract[:497] + "..."
-		}
 		owner := r.AuthorString
 		if len(owner) > 200 {
 			owner = owner[:197] + "..."
@@ -180,9 +204,6 @@ func (u *newsUseCase) fetchWHO() ([]*domain.NewWithSinglePhoto, error) {
 			desc := item.Description
 			// strip HTML tags from description
 			desc = stripHTMLTags(desc)
-			if len(desc) > 500 {
-				desc = desc[:497] + "..."
-			}
 			cat := item.Category
 			if cat == "" {
 				cat = "WHO News"
@@ -264,9 +285,6 @@ func (u *newsUseCase) fetchMedlinePlus() ([]*domain.NewWithSinglePhoto, error) {
 				break
 			}
 			desc := stripHTMLTags(item.Description)
-			if len(desc) > 500 {
-				desc = desc[:497] + "..."
-			}
 			articles = append(articles, &domain.NewWithSinglePhoto{
 				ID:        fmt.Sprintf("mlp-rss-%d", i),
 				Title:     item.Title,
@@ -286,9 +304,6 @@ func (u *newsUseCase) fetchMedlinePlus() ([]*domain.NewWithSinglePhoto, error) {
 			break
 		}
 		summary := stripHTMLTags(entry.Summary)
-		if len(summary) > 500 {
-			summary = summary[:497] + "..."
-		}
 		articles = append(articles, &domain.NewWithSinglePhoto{
 			ID:        fmt.Sprintf("mlp-%d", i),
 			Title:     entry.Title,
@@ -317,7 +332,7 @@ var searchTopics = []string{
 
 const perPage = 10
 
-func (u *newsUseCase) GetAll(ctx context.Context, query utils.PaginationQuery) (*domain.NewsList, error) {
+func (u *newsUseCase) GetAll(ctx context.Context, caller domain.Caller, query utils.PaginationQuery) (*domain.NewsList, error) {
 	page := query.GetPage()
 	if page < 1 {
 		page = 1
@@ -406,6 +421,13 @@ func (u *newsUseCase) GetAll(ctx context.Context, query utils.PaginationQuery) (
 		allArticles = allArticles[:perPage]
 	}
 
+	// The summaries are written from the full text; the list only shows
+	// the start of it.
+	u.withSummaries(ctx, caller, allArticles)
+	for _, article := range allArticles {
+		article.Body = excerpt(article.Body, excerptRunes)
+	}
+
 	totalPages := totalHits / perPage
 	if totalHits%perPage > 0 {
 		totalPages++
@@ -424,10 +446,11 @@ func (u *newsUseCase) GetAll(ctx context.Context, query utils.PaginationQuery) (
 	}, nil
 }
 
-func (u *newsUseCase) GetOneById(ctx context.Context, id string) (*domain.NewWithSinglePhoto, error) {
+func (u *newsUseCase) GetOneById(ctx context.Context, caller domain.Caller, id string) (*domain.NewWithSinglePhoto, error) {
 	// Try Europe PMC by ID
 	articles, _, err := u.fetchEuropePMC(id, 1, 0)
 	if err == nil && len(articles) > 0 {
+		u.withSummaries(ctx, caller, articles[:1])
 		return articles[0], nil
 	}
 	return nil, fmt.Errorf("article %s not found", id)
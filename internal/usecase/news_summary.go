@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testDeployment/internal/domain"
+	"testDeployment/pkg/ai"
+)
+
+const (
+	newsSummaryMaxTokens = 768
+	newsTakeaways        = 3
+	newsMaxRelevance     = 10
+	// excerptRunes of an article's text are shown in the news list.
+	excerptRunes = 500
+
+	newsSummaryInstruction = "You explain medical news and research to the readers of a skin health app, who have no " +
+		"medical training. Write a summary of at most 120 words in plain words: what was studied or happened, what was " +
+		"found and why it matters, explaining any term a patient would not know and leaving out statistics they cannot " +
+		"use. Then give exactly 3 short takeaways. Report the findings without turning them into advice for the reader. " +
+		"Rate how relevant the article is to skin, hair and nail health from 0 (not at all) to 10 (entirely about " +
+		"them). Reply with JSON only."
+)
+
+// newsSummaryJob is an article waiting to be summarised in language. ctx is
+// the request that fetched it, kept for the usage tag.
+type newsSummaryJob struct {
+	ctx      context.Context
+	article  domain.NewWithSinglePhoto
+	language string
+	hash     string
+}
+
+// withSummaries attaches the stored summaries in the caller's language and
+// queues the articles that have none or whose text has changed. Summaries
+// are an extra: when they cannot be read the articles go out without them.
+func (u *newsUseCase) withSummaries(ctx context.Context, caller domain.Caller, articles []*domain.NewWithSinglePhoto) {
+	if len(articles) == 0 {
+		return
+	}
+	language := u.language(ctx, caller)
+	ids := make([]string, len(articles))
+	for i, article := range articles {
+		ids[i] = article.ID
+	}
+	stored, err := u.summaries.GetSummaries(ctx, language, ids)
+	if err != nil {
+		return
+	}
+	for _, article := range articles {
+		hash := articleHash(article)
+		if summary, ok := stored[article.ID]; ok && summary.SourceHash == hash {
+			article.Summary = summary
+			continue
+		}
+		u.summariseLater(ctx, *article, language, hash)
+	}
+}
+
+// language is the one the caller reads in, resolved like the assistant's.
+func (u *newsUseCase) language(ctx context.Context, caller domain.Caller) string {
+	var profile *domain.Profile
+	if caller.IsRegistered() {
+		// Without the profile the request's languages decide.
+		profile, _ = u.profiles.GetProfile(ctx, caller.UserID)
+	}
+	return resolveLanguage(caller, profile)
+}
+
+// summariseLater queues the article unless the workers are off, it is
+// already queued in this language or the queue is full. A dropped article
+// is queued again the next time it is fetched.
+func (u *newsUseCase) summariseLater(ctx context.Context, article domain.NewWithSinglePhoto, language, hash string) {
+	if u.queue == nil || strings.TrimSpace(article.Body) == "" {
+		return
+	}
+	key := article.ID + "/" + language
+	if _, queued := u.pending.LoadOrStore(key, true); queued {
+		return
+	}
+	job := newsSummaryJob{
+		// The request context ends with the response; its values still tag
+		// the model call.
+		ctx:      context.WithoutCancel(ctx),
+		article:  article,
+		language: language,
+		hash:     hash,
+	}
+	select {
+	case u.queue <- job:
+	default:
+		u.pending.Delete(key)
+	}
+}
+
+func (u *newsUseCase) summariseQueued() {
+	for job := range u.queue {
+		if err := u.summarise(job); err != nil {
+			u.bot.SendErrorNotification(fmt.Errorf("summarise article %s in %s: %w", job.article.ID, job.language, err))
+		}
+		u.pending.Delete(job.article.ID + "/" + job.language)
+	}
+}
+
+// summarise writes the summary of the job's article and stores it.
+func (u *newsUseCase) summarise(job newsSummaryJob) error {
+	res, err := u.model.Generate(job.ctx, ai.Request{
+		System: newsSummaryInstruction + "\n\n" + domain.LanguageInstruction(job.language),
+		Prompt: fmt.Sprintf("Title: %s\nSource: %s\nCategory: %s\n\n%s",
+			job.article.Title, job.article.Owner, job.article.Category, job.article.Body),
+		Schema:    newsSummarySchema(),
+		MaxTokens: newsSummaryMaxTokens,
+	})
+	if err != nil {
+		return err
+	}
+	summary, err := parseNewsSummary(res.Text)
+	if err != nil {
+		return err
+	}
+	summary.ArticleId = job.article.ID
+	summary.Language = job.language
+	summary.SourceHash = job.hash
+	summary.Model = ai.ModelOf(u.model, res)
+	return u.summaries.SaveSummary(job.ctx, summary)
+}
+
+func newsSummarySchema() *ai.Schema {
+	return &ai.Schema{
+		Type: ai.TypeObject,
+		Properties: map[string]*ai.Schema{
+			"summary":   {Type: ai.TypeString},
+			"takeaways": {Type: ai.TypeArray, Items: &ai.Schema{Type: ai.TypeString}},
+			"relevance": {Type: ai.TypeInteger},
+		},
+		Required: []string{"summary", "takeaways", "relevance"},
+	}
+}
+
+// parseNewsSummary keeps at most newsTakeaways takeaways, though one is
+// enough, and clamps the relevance to 0 to newsMaxRelevance.
+func parseNewsSummary(text string) (*domain.NewsSummary, error) {
+	var reply struct {
+		Summary   string   `json:"summary"`
+		Takeaways []string `json:"takeaways"`
+		Relevance float64  `json:"relevance"`
+	}
+	if err := json.Unmarshal([]byte(stripCodeFence(text)), &reply); err != nil {
+		return nil, err
+	}
+	summary := &domain.NewsSummary{
+		Summary:   strings.TrimSpace(reply.Summary),
+		Relevance: min(max(int(reply.Relevance+0.5), 0), newsMaxRelevance),
+	}
+	if summary.Summary == "" {
+		return nil, errors.New("model returned an empty summary")
+	}
+	for _, takeaway := range reply.Takeaways {
+		if takeaway = strings.TrimSpace(takeaway); takeaway != "" && len(summary.Takeaways) < newsTakeaways {
+			summary.Takeaways = append(summary.Takeaways, takeaway)
+		}
+	}
+	if len(summary.Takeaways) == 0 {
+		return nil, errors.New("model returned no takeaways")
+	}
+	return summary, nil
+}
+
+// articleHash identifies the text a summary was written from; the WHO and
+// MedlinePlus IDs are positions in their feeds, so the same ID can later
+// name another article.
+func articleHash(article *domain.NewWithSinglePhoto) string {
+	sum := sha256.Sum256([]byte(article.Title + "\n" + article.Body))
+	return hex.EncodeToString(sum[:])
+}
+
+// excerpt shortens text to at most limit runes, ending after the last
+// sentence in the second half of that, or else at a word.
+func excerpt(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+	cut := string(runes[:limit-1])
+	for i := len(cut) - 1; i >= len(cut)/2; i-- {
+		// A stop followed by a space, so decimals do not count.
+		if strings.ContainsRune(".!?", rune(cut[i])) && text[i+1] == ' ' {
+			return cut[:i+1]
+		}
+	}
+	if i := strings.LastIndexByte(cut, ' '); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,;:") + "…"
+}
@@ -0,0 +1,279 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/repository"
+	"testDeployment/pkg/Bot"
+	"text/template"
+	"time"
+)
+
+type promptUseCase struct {
+	repo     repository.IPromptRepository
+	profiles repository.IProfileRepository
+	defaults map[string]string
+	bot      Bot.Bot
+}
+
+// NewPromptUseCase takes the built-in body of every prompt name, used while
+// no version is published.
+func NewPromptUseCase(
+	repo repository.IPromptRepository,
+	profiles repository.IProfileRepository,
+	defaults map[string]string,
+	bot Bot.Bot,
+) IPromptUseCase {
+	return &promptUseCase{
+		repo:     repo,
+		profiles: profiles,
+		defaults: defaults,
+		bot:      bot,
+	}
+}
+
+// Render fills the active version of name for the caller's language,
+// falling back to the language without its script, the default locale and
+// then to the built-in prompt. A template that fails to render is reported
+// and replaced by the built-in prompt so a bad publish cannot take the
+// assistant down. The system prompt ends with the instruction to answer in
+// the caller's language.
+func (u *promptUseCase) Render(ctx context.Context, name string, caller domain.Caller) (*domain.RenderedPrompt, error) {
+	var profile *domain.Profile
+	if caller.IsRegistered() {
+		p, err := u.profiles.GetProfile(ctx, caller.UserID)
+		if err != nil && !errors.Is(err, domain.ErrProfileNotFound) {
+			return nil, err
+		}
+		profile = p
+	}
+	lang := resolveLanguage(caller, profile)
+
+	tmpl, err := u.active(ctx, name, lang)
+	if err != nil {
+		return nil, err
+	}
+	rendered := &domain.RenderedPrompt{Text: u.defaults[name], Language: lang}
+	if tmpl != nil {
+		text, err := render(tmpl.Body, promptData(caller, profile, lang))
+		if err != nil {
+			u.bot.SendErrorNotification(fmt.Errorf("prompt %s/%s v%d: %w", tmpl.Name, tmpl.Locale, tmpl.Version, err))
+		} else {
+			rendered.TemplateId, rendered.Text = tmpl.Id, text
+		}
+	}
+	if name == domain.PromptSystem {
+		rendered.Text = strings.TrimSpace(rendered.Text + "\n\n" + domain.LanguageInstruction(lang))
+	}
+	return rendered, nil
+}
+
+// resolveLanguage picks the language asked for in the request, then the one
+// in the user's profile, then the browser's, then the default.
+func resolveLanguage(caller domain.Caller, profile *domain.Profile) string {
+	if lang := domain.NormalizeLanguage(caller.Locale); lang != "" {
+		return lang
+	}
+	if profile != nil {
+		if lang := domain.NormalizeLanguage(profile.Language); lang != "" {
+			return lang
+		}
+	}
+	for _, l := range caller.Languages {
+		if lang := domain.NormalizeLanguage(l); lang != "" {
+			return lang
+		}
+	}
+	return domain.DefaultLocale
+}
+
+func (u *promptUseCase) active(ctx context.Context, name, locale string) (*domain.PromptTemplate, error) {
+	var locales []string
+	for _, l := range []string{locale, domain.BaseLanguage(locale), domain.DefaultLocale} {
+		if l != "" && !contains(locales, l) {
+			locales = append(locales, l)
+		}
+	}
+	for _, l := range locales {
+		tmpl, err := u.repo.GetActive(ctx, name, l)
+		if errors.Is(err, domain.ErrPromptNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		return tmpl, nil
+	}
+	return nil, nil
+}
+
+func promptData(caller domain.Caller, p *domain.Profile, lang string) domain.PromptData {
+	data := domain.PromptData{Locale: lang, Registered: caller.IsRegistered()}
+	if p == nil {
+		return data
+	}
+	data.Firstname = p.Firstname
+	data.Lastname = p.Lastname
+	data.Gender = p.Gender
+	data.SkinType = p.SkinType
+	data.SkinColor = p.SkinColor
+	data.Conditions = p.Conditions
+	data.Allergies = p.Allergies
+	data.Age = age(p.Birth, time.Now())
+	return data
+}
+
+// ProfileContext is the block about the patient prepended to requests of
+// registered users: age, gender, skin type and tone, known conditions and
+// allergies. It is empty for guests, for users without a profile and when
+// the caller opted out.
+func (u *promptUseCase) ProfileContext(ctx context.Context, caller domain.Caller) (string, error) {
+	if !caller.IsRegistered() || caller.SkipProfile {
+		return "", nil
+	}
+	p, err := u.profiles.GetProfile(ctx, caller.UserID)
+	if errors.Is(err, domain.ErrProfileNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var lines []string
+	add := func(label, value string) {
+		if value = strings.TrimSpace(value); value != "" {
+			lines = append(lines, "- "+label+": "+value)
+		}
+	}
+	if years := age(p.Birth, time.Now()); years > 0 {
+		add("Age", strconv.Itoa(years))
+	}
+	add("Gender", p.Gender)
+	add("Skin type", domain.SkinTypeName(p.SkinType))
+	add("Skin tone", domain.SkinColorName(p.SkinColor))
+	add("Known conditions", p.Conditions)
+	add("Allergies", p.Allergies)
+	if len(lines) == 0 {
+		return "", nil
+	}
+	return "Patient profile, take it into account (e.g. avoid what they are allergic to) without repeating it back:\n" +
+		strings.Join(lines, "\n"), nil
+}
+
+// withProfile prepends the profile block of ProfileContext to prompt.
+func withProfile(profile, prompt string) string {
+	if profile == "" {
+		return prompt
+	}
+	return profile + "\n\n" + prompt
+}
+
+// Create validates the body and stores it as a new, inactive version.
+func (u *promptUseCase) Create(ctx context.Context, prompt domain.NewPromptTemplate) (*domain.PromptTemplate, error) {
+	if !contains(domain.PromptNames, prompt.Name) {
+		return nil, fmt.Errorf("%w: unknown name %q, expected one of %s", domain.ErrInvalidPrompt, prompt.Name, strings.Join(domain.PromptNames, ", "))
+	}
+	if strings.TrimSpace(prompt.Body) == "" {
+		return nil, fmt.Errorf("%w: empty body", domain.ErrInvalidPrompt)
+	}
+	// Rendering against sample data catches unknown variables and syntax
+	// errors before the version can be published.
+	if _, err := render(prompt.Body, domain.PromptData{Registered: true, Firstname: "Ali", Age: 30, Locale: domain.DefaultLocale}); err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidPrompt, err)
+	}
+
+	tmpl := &domain.PromptTemplate{
+		Name:   prompt.Name,
+		Locale: normalizeLocale(prompt.Locale),
+		Body:   prompt.Body,
+	}
+	if err := u.repo.Create(ctx, tmpl); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+func (u *promptUseCase) List(ctx context.Context, name, locale string) ([]*domain.PromptTemplate, error) {
+	if locale != "" {
+		locale = normalizeLocale(locale)
+	}
+	return u.repo.List(ctx, name, locale)
+}
+
+func (u *promptUseCase) Publish(ctx context.Context, id int) (*domain.PromptTemplate, error) {
+	if err := u.repo.Activate(ctx, id, true); err != nil {
+		return nil, err
+	}
+	tmpl, err := u.repo.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	u.bot.SendNotification(fmt.Sprintf("Prompt %s/%s v%d published", tmpl.Name, tmpl.Locale, tmpl.Version))
+	return tmpl, nil
+}
+
+// Rollback re-activates the version that was published before the active
+// one.
+func (u *promptUseCase) Rollback(ctx context.Context, name, locale string) (*domain.PromptTemplate, error) {
+	previous, err := u.repo.GetPreviousPublished(ctx, name, normalizeLocale(locale))
+	if errors.Is(err, domain.ErrPromptNotFound) {
+		return nil, domain.ErrNoPreviousPrompt
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := u.repo.Activate(ctx, previous.Id, false); err != nil {
+		return nil, err
+	}
+	previous.IsActive = true
+	u.bot.SendNotification(fmt.Sprintf("Prompt %s/%s rolled back to v%d", previous.Name, previous.Locale, previous.Version))
+	return previous, nil
+}
+
+func render(body string, data domain.PromptData) (string, error) {
+	tmpl, err := template.New("prompt").Option("missingkey=error").Parse(body)
+	if err != nil {
+		return "", err
+	}
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// normalizeLocale stores the languages we answer in under the tag callers
+// resolve to, so "uz_cyrl" templates are found for uz-Cyrl callers.
+func normalizeLocale(locale string) string {
+	if lang := domain.NormalizeLanguage(locale); lang != "" {
+		return lang
+	}
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if locale == "" {
+		return domain.DefaultLocale
+	}
+	return locale
+}
+
+// age in whole years from a YYYY-MM-DD birth date, 0 when unknown.
+func age(birth string, now time.Time) int {
+	b, err := time.Parse("2006-01-02", birth)
+	if err != nil {
+		return 0
+	}
+	years := now.Year() - b.Year()
+	// Month and day, not YearDay, which shifts by one after February in
+	// leap years.
+	if now.Month() < b.Month() || (now.Month() == b.Month() && now.Day() < b.Day()) {
+		years--
+	}
+	if years < 0 {
+		return 0
+	}
+	return years
+}
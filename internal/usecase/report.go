@@ -0,0 +1,286 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/repository"
+	"testDeployment/pkg/Bot"
+	"testDeployment/pkg/imaging"
+	"testDeployment/pkg/pdf"
+	"time"
+)
+
+const (
+	reportMIMEType    = "application/pdf"
+	reportTimeLayout  = "2006-01-02 15:04"
+	reportImageHeight = 320
+	reportFooter      = "SkinAI report for your doctor. Not a diagnosis."
+)
+
+var (
+	markdownHeading = regexp.MustCompile(`(?m)^#{1,6}\s+`)
+	markdownBullet  = regexp.MustCompile(`(?m)^(\s*)[*-]\s+`)
+)
+
+type reportUseCase struct {
+	chats       repository.IChatRepository
+	records     repository.ISkinRecordRepository
+	profiles    repository.IProfileRepository
+	prompts     repository.IPromptRepository
+	jpegQuality int
+	bot         Bot.Bot
+}
+
+func NewReportUseCase(
+	chats repository.IChatRepository,
+	records repository.ISkinRecordRepository,
+	profiles repository.IProfileRepository,
+	prompts repository.IPromptRepository,
+	jpegQuality int,
+	bot Bot.Bot,
+) IReportUseCase {
+	return &reportUseCase{
+		chats:       chats,
+		records:     records,
+		profiles:    profiles,
+		prompts:     prompts,
+		jpegQuality: jpegQuality,
+		bot:         bot,
+	}
+}
+
+// ConversationReport renders one of the user's conversations as a PDF: the
+// patient profile, every message with its time, the model and prompt
+// version behind each answer and the disclaimer.
+func (u *reportUseCase) ConversationReport(ctx context.Context, userId int, id int) (*domain.Report, error) {
+	conversation, err := u.chats.GetConversation(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+	messages, err := u.chats.GetMessages(ctx, conversation.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := pdf.New(conversation.Title)
+	if err != nil {
+		return nil, err
+	}
+	doc.SetFooter(reportFooter)
+	doc.Title(conversation.Title)
+	doc.Note(fmt.Sprintf("AI conversation #%d, started %s, exported %s",
+		conversation.Id, reportTime(conversation.CreatedAt), time.Now().Format(reportTimeLayout)))
+	if err := u.writeProfile(ctx, doc, userId); err != nil {
+		return nil, err
+	}
+
+	doc.Heading("Conversation")
+	versions := make(map[int]string)
+	for _, m := range messages {
+		doc.Space(4)
+		if m.IsAi {
+			doc.Note(strings.Join(nonEmpty("Assistant", reportTime(m.CreatedAt), modelLabel(m.Model),
+				"prompt "+u.promptVersion(ctx, versions, m.PromptTemplateId)), " · "))
+		} else {
+			doc.Note("Patient · " + reportTime(m.CreatedAt))
+		}
+		doc.Text(plainText(m.Text))
+	}
+	if len(messages) == 0 {
+		doc.Text("No messages yet.")
+	}
+
+	doc.Heading("Disclaimer")
+	doc.Text(domain.DisclaimerStandard)
+	return finishReport(doc, fmt.Sprintf("conversation-%d.pdf", conversation.Id))
+}
+
+// SkinRecordReport renders a saved analysis as a PDF with its photo, the
+// verdict, the model and prompt versions behind it and its disclaimer.
+func (u *reportUseCase) SkinRecordReport(ctx context.Context, userId int, id int) (*domain.Report, error) {
+	record, err := u.records.Get(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	title := "Skin record: " + record.Location
+	doc, err := pdf.New(title)
+	if err != nil {
+		return nil, err
+	}
+	doc.SetFooter(reportFooter)
+	doc.Title(title)
+	doc.Note(fmt.Sprintf("Record #%d, photographed %s, exported %s",
+		record.Id, reportTime(record.CreatedAt), time.Now().Format(reportTimeLayout)))
+	if err := u.writeProfile(ctx, doc, userId); err != nil {
+		return nil, err
+	}
+
+	doc.Heading("Photo")
+	u.writeImage(doc, record.ImagePath)
+	if note := strings.TrimSpace(record.Note); note != "" {
+		doc.Heading("Patient note")
+		doc.Text(note)
+	}
+
+	a := record.Analysis
+	if a == nil {
+		a = &domain.SkinAnalysis{}
+	}
+	doc.Heading("Analysis")
+	if a.Summary != "" {
+		doc.Text(plainText(a.Summary))
+		doc.Space(4)
+	}
+	conditions := make([]string, len(a.Conditions))
+	for i, c := range a.Conditions {
+		conditions[i] = fmt.Sprintf("%s (%.0f%%)", c.Name, c.Confidence*100)
+	}
+	reportField(doc, "Possible conditions", strings.Join(conditions, "\n"))
+	reportField(doc, "Visible features", strings.Join(a.VisibleFeatures, "\n"))
+	reportField(doc, "Urgency", a.Urgency)
+	reportField(doc, "Specialist", a.RecommendedSpecialty)
+	reportField(doc, "Change since last", a.ChangeSincePrevious)
+	reportField(doc, "Self-care", strings.Join(a.SelfCare, "\n"))
+
+	if r := a.UrgentReferral; r != nil {
+		doc.Heading("Urgent referral")
+		doc.Text(r.Reason)
+		for _, d := range r.Doctors {
+			reportField(doc, d.Name, strings.Join(nonEmpty(d.Type, d.Workplace, d.PhoneNumber), ", "))
+		}
+	}
+
+	prompts := make([]string, 0, len(a.PromptTemplateIds))
+	versions := make(map[int]string)
+	for _, promptId := range a.PromptTemplateIds {
+		prompts = append(prompts, u.promptVersion(ctx, versions, promptId))
+	}
+	if len(prompts) == 0 {
+		prompts = append(prompts, u.promptVersion(ctx, versions, 0))
+	}
+	doc.Space(6)
+	doc.Note(strings.Join(nonEmpty(modelLabel(a.Model), "prompts "+strings.Join(prompts, ", ")), " · "))
+
+	doc.Heading("Disclaimer")
+	disclaimer := a.Disclaimer
+	if disclaimer == "" {
+		disclaimer = domain.DisclaimerStandard
+	}
+	doc.Text(disclaimer)
+	return finishReport(doc, fmt.Sprintf("skin-record-%d.pdf", record.Id))
+}
+
+// writeProfile adds what user_info holds about the patient.
+func (u *reportUseCase) writeProfile(ctx context.Context, doc *pdf.Document, userId int) error {
+	doc.Heading("Patient")
+	p, err := u.profiles.GetProfile(ctx, userId)
+	if errors.Is(err, domain.ErrProfileNotFound) {
+		doc.Text("The patient has not filled in a profile.")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	reportField(doc, "Name", strings.TrimSpace(p.Firstname+" "+p.Lastname))
+	birth := p.Birth
+	if years := age(p.Birth, time.Now()); years > 0 {
+		birth += " (" + strconv.Itoa(years) + " years)"
+	}
+	reportField(doc, "Date of birth", birth)
+	reportField(doc, "Gender", p.Gender)
+	reportField(doc, "Skin type", domain.SkinTypeName(p.SkinType))
+	reportField(doc, "Skin tone", domain.SkinColorName(p.SkinColor))
+	reportField(doc, "Known conditions", p.Conditions)
+	reportField(doc, "Allergies", p.Allergies)
+	return nil
+}
+
+// writeImage embeds the photo. A missing or unreadable file is reported and
+// noted in the document rather than failing the whole report.
+func (u *reportUseCase) writeImage(doc *pdf.Document, path string) {
+	data, err := os.ReadFile(path)
+	if err == nil {
+		data, err = imaging.JPEG(data, u.jpegQuality)
+	}
+	if err == nil {
+		err = doc.Image(data, reportImageHeight)
+	}
+	if err != nil {
+		u.bot.SendErrorNotification(fmt.Errorf("report photo %s: %w", path, err))
+		doc.Note("The photo could not be loaded.")
+	}
+}
+
+// promptVersion names a stored prompt version, e.g. "system v3 (ru)",
+// caching lookups in versions.
+func (u *reportUseCase) promptVersion(ctx context.Context, versions map[int]string, id int) string {
+	if id == 0 {
+		return "built-in"
+	}
+	if v, ok := versions[id]; ok {
+		return v
+	}
+	v := "#" + strconv.Itoa(id)
+	if tmpl, err := u.prompts.Get(ctx, id); err == nil {
+		v = fmt.Sprintf("%s v%d (%s)", tmpl.Name, tmpl.Version, tmpl.Locale)
+	}
+	versions[id] = v
+	return v
+}
+
+func finishReport(doc *pdf.Document, filename string) (*domain.Report, error) {
+	data, err := doc.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return &domain.Report{Filename: filename, MIMEType: reportMIMEType, Data: data}, nil
+}
+
+// reportField skips empty values so the report only lists what is known.
+func reportField(doc *pdf.Document, label, value string) {
+	if value = strings.TrimSpace(value); value != "" {
+		doc.Field(label, value)
+	}
+}
+
+func modelLabel(model string) string {
+	if model == "" {
+		return ""
+	}
+	return "model " + model
+}
+
+func nonEmpty(values ...string) []string {
+	var out []string
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// plainText drops the markdown the model answers in, which would otherwise
+// be printed literally.
+func plainText(text string) string {
+	text = markdownHeading.ReplaceAllString(text, "")
+	text = markdownBullet.ReplaceAllString(text, "$1• ")
+	return strings.NewReplacer("**", "", "__", "", "`", "").Replace(text)
+}
+
+// reportTime shortens the stored timestamps to minutes.
+func reportTime(value string) string {
+	for _, layout := range []string{time.RFC3339Nano, messageTimeLayout, "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.Format(reportTimeLayout)
+		}
+	}
+	return value
+}
@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/repository"
+	"testDeployment/pkg/jwt"
+	"time"
+)
+
+const revocationSweepInterval = time.Hour
+
+// revocationList keeps the revocations of the store in memory, so checking
+// a token costs no query. Revocations made here apply at once; those of
+// other instances arrive with the next sync.
+type revocationList struct {
+	repo     repository.ITokenRevocationRepository
+	interval time.Duration
+
+	mu     sync.RWMutex
+	tokens map[string]time.Time // jti -> exp
+	users  map[int]time.Time    // user -> revoked before
+	synced time.Time
+}
+
+func newRevocationList(repo repository.ITokenRevocationRepository, interval time.Duration) *revocationList {
+	l := &revocationList{
+		repo:     repo,
+		interval: interval,
+		tokens:   make(map[string]time.Time),
+		users:    make(map[int]time.Time),
+	}
+	l.sync()
+	go l.run()
+	return l
+}
+
+// revoked tells whether claims were revoked. RevokedBefore is a whole
+// second, so tokens issued in the second a user signed out everywhere are
+// still accepted, as iat has no finer resolution.
+func (l *revocationList) revoked(claims *jwt.Claims) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if claims.ID != "" {
+		if _, ok := l.tokens[claims.ID]; ok {
+			return true
+		}
+	}
+	if claims.UserID > 0 {
+		if before, ok := l.users[claims.UserID]; ok && claims.IssuedAt.Before(before) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *revocationList) revokeToken(ctx context.Context, token domain.RevokedToken) error {
+	if err := l.repo.RevokeToken(ctx, token); err != nil {
+		return err
+	}
+	l.mu.Lock()
+	l.tokens[token.Jti] = token.ExpiresAt
+	l.mu.Unlock()
+	return nil
+}
+
+func (l *revocationList) revokeUser(ctx context.Context, revocation domain.UserRevocation) error {
+	if err := l.repo.RevokeUser(ctx, revocation); err != nil {
+		return err
+	}
+	l.addUser(revocation)
+	return nil
+}
+
+func (l *revocationList) addUser(revocation domain.UserRevocation) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if revocation.RevokedBefore.After(l.users[revocation.UserId]) {
+		l.users[revocation.UserId] = revocation.RevokedBefore
+	}
+}
+
+func (l *revocationList) run() {
+	lastSweep := time.Now()
+	for {
+		time.Sleep(l.interval)
+		l.sync()
+		if time.Since(lastSweep) >= revocationSweepInterval {
+			lastSweep = time.Now()
+			l.sweep()
+		}
+	}
+}
+
+// sync loads what was revoked since the last sync. The window reaches one
+// interval further back, so a revocation committed during the previous
+// sync, or stamped by a slightly late clock, is not missed; loading one
+// twice is harmless.
+func (l *revocationList) sync() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	start := time.Now()
+	since := time.Time{}
+	if !l.synced.IsZero() {
+		since = l.synced.Add(-l.interval)
+	}
+	tokens, err := l.repo.TokensSince(ctx, since)
+	if err != nil {
+		return
+	}
+	users, err := l.repo.UsersSince(ctx, since)
+	if err != nil {
+		return
+	}
+
+	l.mu.Lock()
+	for _, token := range tokens {
+		l.tokens[token.Jti] = token.ExpiresAt
+	}
+	l.mu.Unlock()
+	for _, revocation := range users {
+		l.addUser(revocation)
+	}
+	l.synced = start
+}
+
+// sweep forgets the revoked tokens that have expired, which their exp
+// refuses anyway.
+func (l *revocationList) sweep() {
+	now := time.Now()
+	l.mu.Lock()
+	for jti, exp := range l.tokens {
+		if !exp.After(now) {
+			delete(l.tokens, jti)
+		}
+	}
+	l.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	_, _ = l.repo.DeleteExpired(ctx)
+	cancel()
+}
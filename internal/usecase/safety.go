@@ -0,0 +1,179 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"testDeployment/internal/domain"
+	"testDeployment/pkg/Bot"
+)
+
+const maxReferralDoctors = 5
+
+type redFlagRule struct {
+	flag string
+	re   *regexp.Regexp
+	// outputOnly rules describe the model's own assessment.
+	outputOnly bool
+}
+
+// redFlagRules match English, Russian and Uzbek (Latin) wording. They err on
+// the side of flagging: a needless referral costs less than a missed one.
+var redFlagRules = []redFlagRule{
+	{flag: domain.RedFlagAsymmetry, re: regexp.MustCompile(`(?i)asymmetr|uneven shape|lopsided|несимметрич|асимметр|nosimmetrik|assimetri`)},
+	{flag: domain.RedFlagBorder, re: regexp.MustCompile(`(?i)irregular (border|edge|outline)|(jagged|ragged|blurr?y|blurred|notched|uneven|fuzzy|poorly defined) (border|edge|outline)|неровн\S* (кра|границ)|размыт\S* (кра|границ)|notekis (chegara|chet)`)},
+	{flag: domain.RedFlagColour, re: regexp.MustCompile(`(?i)(multiple|several|different|uneven|mixed|many) colou?rs|colou?r (is )?(changing|uneven|varies|variation)|(black|blue|red|white),? (and|or) (brown|black|blue|red|white)|разн\S* цвет|несколько цвет|turli rang|rangi (notekis|har xil)`)},
+	{flag: domain.RedFlagDiameter, re: regexp.MustCompile(`(?i)(larger|bigger|more|wider) than (6|six) ?(mm|millimet)|\b([6-9]|[1-9][0-9])(\.[0-9])? ?mm\b|pencil eraser|больше 6 ?мм|6 ?mm dan katta`)},
+	{flag: domain.RedFlagEvolving, re: regexp.MustCompile(`(?i)(mole|spot|lesion|freckle|birthmark)s? (has |have |is |are )?(changed|changing|evolving|getting darker|darkened|turned)|changed (its )?(shape|colou?r|size)|родинк\S* (измени|меня|потемн)|xol\S* (o'zgar|qoraya)`)},
+	{flag: domain.RedFlagRapidGrowth, re: regexp.MustCompile(`(?i)(growing|grown|grew|got bigger|getting bigger|enlarg\S*|spreading) (very )?(fast|quickly|rapidly|in (a few|two|2|three|3) (days|weeks))|rapid(ly)? grow|fast[- ]growing|быстро (раст|увелич)|tez (o'sa|kattala)`)},
+	{flag: domain.RedFlagBleeding, re: regexp.MustCompile(`(?i)\bbleed|\bbled\b|oozing blood|won't heal|not healing|doesn't heal|кровоточ|кровит|не заживает|qon (ket|chiq|oqa)|bitmayapti`)},
+	{flag: domain.RedFlagInfection, re: regexp.MustCompile(`(?i)\bpus\b|pus-filled|red streak|spreading redness|(warm|hot) to (the )?touch|\bfever|\bchills\b|infect(ed|ion)|abscess|cellulitis|гно[йяи]|температур|лихорад|yiring|isitma|harorat`)},
+	{flag: domain.RedFlagMelanoma, re: regexp.MustCompile(`(?i)melanoma|меланом`)},
+	{flag: domain.RedFlagModelUrgency, outputOnly: true, re: regexp.MustCompile(`(?i)(see|visit|consult|contact) (a |your )?(doctor|dermatologist|physician|gp)\S* (urgently|immediately|as soon as possible|right away|today|promptly)|seek (immediate|urgent|emergency|prompt) (medical )?(care|attention|help|evaluation)|urgent(ly)? (care|attention|assessment|evaluation)|emergency|срочно|shoshilinch`)},
+}
+
+var (
+	sentenceSplit = regexp.MustCompile(`[.!?\n]+`)
+	// negated catches "no bleeding", "it doesn't itch or bleed", "без гноя".
+	negated = regexp.MustCompile(`(?i)(^|[^\p{L}'])(no|not|never|without|nor|denies|isn't|doesn't|don't|hasn't|haven't|didn't|нет|не|без|yo'q)[^\p{L}'][^.!?;]{0,25}$`)
+	// conditional sentences in the model's answer ("if it bleeds, ...")
+	// are advice, not findings.
+	conditional = regexp.MustCompile(`(?i)(^|[^\p{L}])(if|unless|should it|если|agar)([^\p{L}]|$)`)
+)
+
+type safetyUseCase struct {
+	doctors   IDoctorUsecase
+	specialty string
+	bot       Bot.Bot
+}
+
+// NewSafetyUseCase refers patients to doctors whose type contains specialty
+// ("dermatolog" matches Dermatolog and Dermatologist).
+func NewSafetyUseCase(doctors IDoctorUsecase, specialty string, bot Bot.Bot) ISafetyUseCase {
+	return &safetyUseCase{
+		doctors:   doctors,
+		specialty: strings.ToLower(specialty),
+		bot:       bot,
+	}
+}
+
+// Review scans what the patient wrote and what the model answered for red
+// flags. flags are red flags the caller already knows about, like a high
+// urgency in a structured analysis. Every review carries a disclaimer; one
+// with red flags also carries an urgent referral and is escalated to the
+// bot. Review never fails: the answer is more useful without doctors than
+// not at all.
+func (u *safetyUseCase) Review(ctx context.Context, caller domain.Caller, source, input, output string, flags ...string) *domain.SafetyReview {
+	inputFlags := detectRedFlags(input, false)
+	found := mergeFlags(flags, inputFlags, detectRedFlags(output, true))
+	if len(found) == 0 {
+		return &domain.SafetyReview{Disclaimer: domain.DisclaimerStandard}
+	}
+
+	referral := &domain.UrgentReferral{
+		Reason:   "Signs that need a dermatologist soon: " + strings.ReplaceAll(strings.Join(found, ", "), "_", " "),
+		RedFlags: found,
+		Doctors:  u.dermatologists(ctx),
+	}
+
+	excerpt := output
+	if len(inputFlags) > 0 {
+		excerpt = input
+	}
+	u.bot.SendEscalation(Bot.Escalation{
+		Source:   source,
+		Caller:   callerLabel(caller),
+		RedFlags: found,
+		Excerpt:  excerpt,
+		Doctors:  len(referral.Doctors),
+	})
+
+	return &domain.SafetyReview{
+		RedFlags:       found,
+		Disclaimer:     domain.DisclaimerUrgentCare,
+		UrgentReferral: referral,
+	}
+}
+
+func (u *safetyUseCase) dermatologists(ctx context.Context) []*domain.DoctorWithType {
+	doctors := []*domain.DoctorWithType{}
+	byType, err := u.doctors.GetAll(ctx)
+	if err != nil {
+		u.bot.SendErrorNotification(fmt.Errorf("urgent referral without doctors: %w", err))
+		return doctors
+	}
+	for _, t := range byType {
+		if !strings.Contains(strings.ToLower(t.Type), u.specialty) {
+			continue
+		}
+		doctors = append(doctors, t.Doctor...)
+	}
+	sort.SliceStable(doctors, func(i, j int) bool {
+		return doctors[i].Rating > doctors[j].Rating
+	})
+	if len(doctors) > maxReferralDoctors {
+		doctors = doctors[:maxReferralDoctors]
+	}
+	return doctors
+}
+
+// AnswerRedFlags returns the red flags Review finds in a model answer; the
+// offline evaluation in cmd/aieval scores answers with it.
+func AnswerRedFlags(answer string) []string {
+	return detectRedFlags(answer, true)
+}
+
+// detectRedFlags returns the red flags in text, skipping negated mentions
+// and, in the model's answer, conditional advice.
+func detectRedFlags(text string, output bool) []string {
+	var flags []string
+	for _, sentence := range sentenceSplit.Split(text, -1) {
+		if output && conditional.MatchString(sentence) {
+			continue
+		}
+		for _, rule := range redFlagRules {
+			if rule.outputOnly && !output {
+				continue
+			}
+			if contains(flags, rule.flag) {
+				continue
+			}
+			for _, loc := range rule.re.FindAllStringIndex(sentence, -1) {
+				if !negated.MatchString(sentence[:loc[0]]) {
+					flags = append(flags, rule.flag)
+					break
+				}
+			}
+		}
+	}
+	return flags
+}
+
+func mergeFlags(lists ...[]string) []string {
+	var merged []string
+	for _, list := range lists {
+		for _, flag := range list {
+			if !contains(merged, flag) {
+				merged = append(merged, flag)
+			}
+		}
+	}
+	return merged
+}
+
+// isUrgent tells whether a structured analysis urgency is a red flag.
+func isUrgent(urgency string) bool {
+	return urgency == domain.UrgencyHigh || urgency == domain.UrgencyEmergency
+}
+
+func callerLabel(caller domain.Caller) string {
+	if caller.IsRegistered() {
+		return fmt.Sprintf("user %d", caller.UserID)
+	}
+	if caller.GuestID != "" {
+		return "guest " + caller.GuestID
+	}
+	return "anonymous"
+}
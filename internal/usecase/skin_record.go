@@ -0,0 +1,149 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/repository"
+	"testDeployment/pkg/Bot"
+	"testDeployment/pkg/imaging"
+
+	"github.com/google/uuid"
+)
+
+const (
+	skinRecordsDir    = "uploads/records"
+	maxLocationLength = 120
+)
+
+type skinRecordUseCase struct {
+	repo     repository.ISkinRecordRepository
+	analysis IAnalysisUseCase
+	bot      Bot.Bot
+}
+
+func NewSkinRecordUseCase(repo repository.ISkinRecordRepository, analysis IAnalysisUseCase, bot Bot.Bot) ISkinRecordUseCase {
+	return &skinRecordUseCase{
+		repo:     repo,
+		analysis: analysis,
+		bot:      bot,
+	}
+}
+
+// Create analyses the photo, with the previous record of the same location
+// as context, then stores the photo and the verdict. record must carry
+// Location, Note and the MIME type of the already preprocessed image.
+func (u *skinRecordUseCase) Create(ctx context.Context, caller domain.Caller, record *domain.SkinRecord, image []byte) error {
+	record.UserId = caller.UserID
+	record.Location = normalizeLocation(record.Location)
+	if record.Location == "" {
+		return domain.ErrEmptyField
+	}
+
+	previous, err := u.repo.GetLatest(ctx, record.UserId, record.Location)
+	switch {
+	case errors.Is(err, domain.ErrSkinRecordNotFound):
+		previous = nil
+	case err != nil:
+		return err
+	default:
+		// A missing file only costs the model the old photo, the verdict is still useful.
+		previous.Image, _ = os.ReadFile(previous.ImagePath)
+	}
+
+	record.Analysis, err = u.analysis.Analyze(ctx, caller, image, record.MIMEType, record.Note, previous)
+	if err != nil {
+		return err
+	}
+
+	dir := filepath.Join(skinRecordsDir, strconv.Itoa(record.UserId))
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		u.bot.SendErrorNotification(err)
+		return err
+	}
+	record.ImagePath = filepath.Join(dir, uuid.NewString()+imaging.Extension(record.MIMEType))
+	if err := os.WriteFile(record.ImagePath, image, 0o644); err != nil {
+		u.bot.SendErrorNotification(err)
+		return err
+	}
+
+	if err := u.repo.Create(ctx, record); err != nil {
+		os.Remove(record.ImagePath)
+		return err
+	}
+	withImageURL(record)
+	return nil
+}
+
+func (u *skinRecordUseCase) Get(ctx context.Context, userId int, id int) (*domain.SkinRecord, error) {
+	record, err := u.repo.Get(ctx, userId, id)
+	if err != nil {
+		return nil, err
+	}
+	return withImageURL(record), nil
+}
+
+func (u *skinRecordUseCase) GetImage(ctx context.Context, userId int, id int) ([]byte, string, error) {
+	record, err := u.repo.Get(ctx, userId, id)
+	if err != nil {
+		return nil, "", err
+	}
+	data, err := os.ReadFile(record.ImagePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, "", domain.ErrSkinRecordNotFound
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return data, record.MIMEType, nil
+}
+
+// GetTimeline returns the records of one location, oldest first.
+func (u *skinRecordUseCase) GetTimeline(ctx context.Context, userId int, location string) ([]*domain.SkinRecord, error) {
+	records, err := u.repo.GetByLocation(ctx, userId, normalizeLocation(location))
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		withImageURL(record)
+	}
+	return records, nil
+}
+
+func (u *skinRecordUseCase) GetLocations(ctx context.Context, userId int) ([]*domain.SkinLocation, error) {
+	return u.repo.GetLocations(ctx, userId)
+}
+
+func (u *skinRecordUseCase) Delete(ctx context.Context, userId int, id int) error {
+	record, err := u.repo.Get(ctx, userId, id)
+	if err != nil {
+		return err
+	}
+	if err := u.repo.Delete(ctx, userId, id); err != nil {
+		return err
+	}
+	if err := os.Remove(record.ImagePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		u.bot.SendErrorNotification(err)
+	}
+	return nil
+}
+
+// normalizeLocation makes "Left  Forearm mole" and "left forearm mole" the
+// same tracked location.
+func normalizeLocation(location string) string {
+	location = strings.ToLower(strings.Join(strings.Fields(location), " "))
+	if r := []rune(location); len(r) > maxLocationLength {
+		location = string(r[:maxLocationLength])
+	}
+	return location
+}
+
+func withImageURL(record *domain.SkinRecord) *domain.SkinRecord {
+	record.ImageURL = fmt.Sprintf("/api/v1/records/%d/image", record.Id)
+	return record
+}
@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	configs "testDeployment/internal/common/config"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/repository"
+	"testDeployment/pkg/Bot"
+	"testDeployment/pkg/ai"
+	"time"
+)
+
+const (
+	recordUsageTimeout = 5 * time.Second
+	reportTopConsumers = 5
+)
+
+type usageUseCase struct {
+	repo           repository.IUsageRepository
+	promptPrice    float64
+	candidatePrice float64
+	bot            Bot.Bot
+}
+
+func NewUsageUseCase(repo repository.IUsageRepository, cfg configs.Ai, bot Bot.Bot) IUsageUseCase {
+	return &usageUseCase{
+		repo:           repo,
+		promptPrice:    cfg.PricePromptPerMTok,
+		candidatePrice: cfg.PriceCandidatePerMTok,
+		bot:            bot,
+	}
+}
+
+// Record stores one model call. It is an ai.Recorder: it outlives a
+// cancelled request so an aborted stream is still accounted for, and a
+// failure to store is only reported, never returned to the patient.
+func (u *usageUseCase) Record(ctx context.Context, call ai.Call) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), recordUsageTimeout)
+	defer cancel()
+
+	_ = u.repo.Create(ctx, &domain.AIUsage{
+		UserId:          call.Tag.UserID,
+		GuestId:         call.Tag.GuestID,
+		Endpoint:        call.Tag.Endpoint,
+		Provider:        call.Provider,
+		Model:           call.Model,
+		PromptTokens:    call.Usage.PromptTokens,
+		CandidateTokens: call.Usage.CandidateTokens,
+		TotalTokens:     call.Usage.TotalTokens,
+		LatencyMs:       call.Latency.Milliseconds(),
+		Failed:          call.Err != nil,
+	})
+}
+
+func (u *usageUseCase) GetDaily(ctx context.Context, filter domain.UsageFilter) ([]*domain.UsageDay, error) {
+	days, err := u.repo.GetDaily(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, day := range days {
+		u.withCost(&day.UsageTotals)
+	}
+	return days, nil
+}
+
+// GetSummary returns what the user or guest of filter consumed.
+func (u *usageUseCase) GetSummary(ctx context.Context, filter domain.UsageFilter) (*domain.UsageSummary, error) {
+	totals, err := u.repo.GetTotals(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	breakdown, err := u.repo.GetBreakdown(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range breakdown {
+		u.withCost(&b.UsageTotals)
+	}
+	summary := &domain.UsageSummary{
+		UsageConsumer: domain.UsageConsumer{
+			UserId:      filter.UserId,
+			GuestId:     filter.GuestId,
+			UsageTotals: *totals,
+		},
+		ByEndpoint: breakdown,
+	}
+	u.withCost(&summary.UsageTotals)
+	return summary, nil
+}
+
+func (u *usageUseCase) GetTopConsumers(ctx context.Context, filter domain.UsageFilter, limit int) ([]*domain.UsageConsumer, error) {
+	consumers, err := u.repo.GetTopConsumers(ctx, filter, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range consumers {
+		u.withCost(&c.UsageTotals)
+	}
+	return consumers, nil
+}
+
+// UsageReport is the text of the /usage bot command: today, the last 7 days
+// and the top consumers of the week.
+func (u *usageUseCase) UsageReport(ctx context.Context) (string, error) {
+	today, err := u.repo.GetTotals(ctx, domain.UsageFilter{Days: 1})
+	if err != nil {
+		return "", err
+	}
+	week, err := u.repo.GetTotals(ctx, domain.UsageFilter{Days: 7})
+	if err != nil {
+		return "", err
+	}
+	top, err := u.GetTopConsumers(ctx, domain.UsageFilter{Days: 7}, reportTopConsumers)
+	if err != nil {
+		return "", err
+	}
+	u.withCost(today)
+	u.withCost(week)
+
+	var sb strings.Builder
+	sb.WriteString("🧮 *AI Usage*\n\n")
+	sb.WriteString(reportLine("Today", today))
+	sb.WriteString(reportLine("Last 7 days", week))
+	sb.WriteString("\n🏆 *Top consumers (7 days):*\n")
+	if len(top) == 0 {
+		sb.WriteString("   _none_\n")
+	}
+	for i, c := range top {
+		who := fmt.Sprintf("user %d", c.UserId)
+		if c.UserId == 0 {
+			who = "guest " + c.GuestId
+		}
+		sb.WriteString(fmt.Sprintf("%d. `%s` — %d tokens, %d calls, $%.4f\n", i+1, who, c.TotalTokens, c.Calls, c.CostUSD))
+	}
+	return sb.String(), nil
+}
+
+func reportLine(label string, t *domain.UsageTotals) string {
+	return fmt.Sprintf("*%s:* `%d` calls (%d failed), `%d` tokens (%d in / %d out), avg `%.0f ms`, ≈ `$%.4f`\n",
+		label, t.Calls, t.Failed, t.TotalTokens, t.PromptTokens, t.CandidateTokens, t.AvgLatencyMs, t.CostUSD)
+}
+
+func (u *usageUseCase) withCost(t *domain.UsageTotals) {
+	t.CostUSD = (float64(t.PromptTokens)*u.promptPrice + float64(t.CandidateTokens)*u.candidatePrice) / 1e6
+}
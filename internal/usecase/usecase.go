@@ -6,6 +6,8 @@ import (
 	"testDeployment/internal/domain"
 	"testDeployment/internal/repository"
 	"testDeployment/pkg/Bot"
+	"testDeployment/pkg/ai"
+	"testDeployment/pkg/jwt"
 	"testDeployment/pkg/utils"
 )
 
@@ -40,8 +42,8 @@ type Usecase interface {
 	UpdateEmail(user dto.UserEmail) (id int, err error)
 }
 type INewsUseCase interface {
-	GetAll(ctx context.Context, query utils.PaginationQuery) (news *domain.NewsList, err error)
-	GetOneById(ctx context.Context, id string) (new *domain.NewWithSinglePhoto, err error)
+	GetAll(ctx context.Context, caller domain.Caller, query utils.PaginationQuery) (news *domain.NewsList, err error)
+	GetOneById(ctx context.Context, caller domain.Caller, id string) (new *domain.NewWithSinglePhoto, err error)
 }
 
 type IDoctorUsecase interface {
@@ -69,6 +71,98 @@ type IFactUseCase interface {
 	GetQuestion(ctx context.Context, id int, offset int) (dto.FactQuestions, error)
 	UpdatePoint(ctx context.Context, id int) (int, error)
 	UpdateImage(ctx context.Context, id int, path string) error
+	GenerateQuizDraft(ctx context.Context, factId int, count int) (*domain.QuizDraft, error)
+	GetQuizDraft(ctx context.Context, id int) (*domain.QuizDraft, error)
+	GetQuizDrafts(ctx context.Context, status string, factId int) ([]*domain.QuizDraft, error)
+	EditQuizDraft(ctx context.Context, id int, questions []domain.QuizQuestion) (*domain.QuizDraft, error)
+	ApproveQuizDraft(ctx context.Context, id int) (*domain.QuizDraft, error)
+	RejectQuizDraft(ctx context.Context, id int) (*domain.QuizDraft, error)
+}
+
+type IChatUseCase interface {
+	CreateConversation(ctx context.Context, userId int, title string) (*domain.Conversation, error)
+	GetConversations(ctx context.Context, userId int) ([]*domain.Conversation, error)
+	GetConversation(ctx context.Context, userId int, id int) (*domain.Conversation, error)
+	DeleteConversation(ctx context.Context, userId int, id int) error
+	GetSummary(ctx context.Context, userId int, id int) (*domain.ConversationSummary, error)
+	DeleteMessage(ctx context.Context, userId int, conversationId int, id int) error
+	SendMessage(ctx context.Context, caller domain.Caller, message domain.NewMessage) (*domain.ChatReply, error)
+	StreamMessage(ctx context.Context, caller domain.Caller, message domain.NewMessage, onChunk func(string) error) (*domain.ChatReply, error)
+}
+
+type IAnalysisUseCase interface {
+	Analyze(ctx context.Context, caller domain.Caller, image []byte, mimeType, note string, previous *domain.SkinRecord) (*domain.SkinAnalysis, error)
+	Compare(ctx context.Context, caller domain.Caller, images []domain.LabeledImage, note string) (*domain.ImageComparison, error)
+	Describe(ctx context.Context, caller domain.Caller, image []byte, mimeType, note string, onChunk func(string) error) (*domain.ChatReply, error)
+}
+
+type ISkinRecordUseCase interface {
+	Create(ctx context.Context, caller domain.Caller, record *domain.SkinRecord, image []byte) error
+	Get(ctx context.Context, userId int, id int) (*domain.SkinRecord, error)
+	GetImage(ctx context.Context, userId int, id int) ([]byte, string, error)
+	GetTimeline(ctx context.Context, userId int, location string) ([]*domain.SkinRecord, error)
+	GetLocations(ctx context.Context, userId int) ([]*domain.SkinLocation, error)
+	Delete(ctx context.Context, userId int, id int) error
+}
+
+type IAnalysisCache interface {
+	Get(ctx context.Context, key string, dest interface{}) bool
+	Put(ctx context.Context, key string, value interface{})
+}
+
+type IUsageUseCase interface {
+	Record(ctx context.Context, call ai.Call)
+	GetDaily(ctx context.Context, filter domain.UsageFilter) ([]*domain.UsageDay, error)
+	GetSummary(ctx context.Context, filter domain.UsageFilter) (*domain.UsageSummary, error)
+	GetTopConsumers(ctx context.Context, filter domain.UsageFilter, limit int) ([]*domain.UsageConsumer, error)
+	UsageReport(ctx context.Context) (string, error)
+}
+
+type ISafetyUseCase interface {
+	Review(ctx context.Context, caller domain.Caller, source, input, output string, flags ...string) *domain.SafetyReview
+}
+
+type IModerationUseCase interface {
+	CheckText(ctx context.Context, caller domain.Caller, text string) (string, error)
+	CheckImages(ctx context.Context, caller domain.Caller, images ...ai.Image) error
+	GetTopRejected(ctx context.Context, filter domain.UsageFilter, limit int) ([]*domain.RejectionConsumer, error)
+}
+
+type IDrugUseCase interface {
+	Get(ctx context.Context, id int) (*domain.Drug, error)
+	Ground(ctx context.Context, system, query string) *domain.DrugGrounding
+}
+
+type IAgentUseCase interface {
+	Run(ctx context.Context, caller domain.Caller, message string) (*domain.AgentReply, error)
+}
+
+type IReportUseCase interface {
+	ConversationReport(ctx context.Context, userId int, id int) (*domain.Report, error)
+	SkinRecordReport(ctx context.Context, userId int, id int) (*domain.Report, error)
+}
+
+type IPromptUseCase interface {
+	Render(ctx context.Context, name string, caller domain.Caller) (*domain.RenderedPrompt, error)
+	ProfileContext(ctx context.Context, caller domain.Caller) (string, error)
+	Create(ctx context.Context, prompt domain.NewPromptTemplate) (*domain.PromptTemplate, error)
+	List(ctx context.Context, name, locale string) ([]*domain.PromptTemplate, error)
+	Publish(ctx context.Context, id int) (*domain.PromptTemplate, error)
+	Rollback(ctx context.Context, name, locale string) (*domain.PromptTemplate, error)
+}
+
+type IAuthUseCase interface {
+	Issue(ctx context.Context, userId int, role string) (*dto.AuthResponse, error)
+	Refresh(ctx context.Context, refreshToken string) (*dto.AuthResponse, error)
+	Logout(ctx context.Context, claims *jwt.Claims) error
+	LogoutEverywhere(ctx context.Context, userId int) error
+	IsRevoked(claims *jwt.Claims) bool
+}
+
+type IVerificationUseCase interface {
+	SendCode(ctx context.Context, userId int) error
+	Verify(ctx context.Context, userId int, code string) error
+	IsVerified(ctx context.Context, userId int) (bool, error)
 }
 
 func NewUserUsecase(repo repository.Repo, bot Bot.Bot) Usecase {
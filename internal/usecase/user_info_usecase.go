@@ -2,11 +2,15 @@ package usecase
 
 import (
 	"errors"
+	"strings"
 	"testDeployment/internal/delivery/dto"
 	"testDeployment/internal/domain"
 )
 
 func (u usecase) FillInfo(user dto.UserInfo) (int, error) {
+	if !supportedLanguage(user.Language) {
+		return 0, domain.ErrUnsupportedLanguage
+	}
 	userInfo := u.f.ParseModelToUserInfo(user)
 	id, err := u.repo.CreateInfo(*userInfo)
 	if err != nil {
@@ -16,6 +20,9 @@ func (u usecase) FillInfo(user dto.UserInfo) (int, error) {
 	return id, nil
 }
 func (u usecase) UpdateInfo(user dto.UserInfo) (id int, err error) {
+	if !supportedLanguage(user.Language) {
+		return 0, domain.ErrUnsupportedLanguage
+	}
 	userInfo := u.f.ParseModelToUserInfo(user)
 	if !Validator(userInfo.Firstname) {
 
@@ -33,8 +40,29 @@ func (u usecase) UpdateInfo(user dto.UserInfo) (id int, err error) {
 			return 0, domain.Err("Coudn`t update Gender")
 		}
 	}
+
+	if !Validator(userInfo.Conditions) || !Validator(userInfo.Allergies) {
+		id, err = u.repo.UpdateHealth(*userInfo)
+		if err != nil {
+			u.bot.SendErrorNotification(err)
+			return 0, domain.Err("Coudn`t update conditions and allergies")
+		}
+	}
+
+	if !Validator(userInfo.Language) {
+		id, err = u.repo.UpdateLanguage(*userInfo)
+		if err != nil {
+			u.bot.SendErrorNotification(err)
+			return 0, domain.Err("Coudn`t update language")
+		}
+	}
 	return id, nil
 }
+
+// supportedLanguage accepts an empty language, which keeps the current one.
+func supportedLanguage(lang string) bool {
+	return strings.TrimSpace(lang) == "" || domain.NormalizeLanguage(lang) != ""
+}
 func (u usecase) GetUserInfo(userId int) (user dto.UserInfo, err error) {
 	exist, err := u.repo.ExistUserInfo(userId)
 	if err != nil || errors.Is(err, domain.ErrCouldNotScan) {
@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	configs "testDeployment/internal/common/config"
+	"testDeployment/internal/domain"
+	"testDeployment/internal/repository"
+	"testDeployment/pkg/Bot"
+	"testDeployment/pkg/sms"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+type verificationUseCase struct {
+	codes    repository.IEmailVerificationRepository
+	users    repository.Repo
+	mailer   sms.Mailer
+	ttl      time.Duration
+	attempts int
+	resend   time.Duration
+	bot      Bot.Bot
+}
+
+// NewVerificationUseCase verifies the email addresses of users with codes
+// sent by mailer, which expire and allow the attempts of cfg.
+func NewVerificationUseCase(codes repository.IEmailVerificationRepository, users repository.Repo, mailer sms.Mailer, cfg configs.Mail, bot Bot.Bot) IVerificationUseCase {
+	return &verificationUseCase{
+		codes:    codes,
+		users:    users,
+		mailer:   mailer,
+		ttl:      cfg.CodeTTL,
+		attempts: cfg.CodeAttempts,
+		resend:   cfg.CodeResend,
+		bot:      bot,
+	}
+}
+
+// SendCode mails a new code to the current email of the user; the code sent
+// before stops working. Asking again for the same address within the
+// resend interval fails with ErrResendTooSoon.
+func (u *verificationUseCase) SendCode(ctx context.Context, userId int) error {
+	email, verified, err := u.users.GetEmail(userId)
+	if err != nil {
+		return err
+	}
+	if verified {
+		return domain.ErrEmailAlreadyVerified
+	}
+	if email == "" {
+		return domain.ErrNoEmail
+	}
+	pending, err := u.codes.Get(ctx, userId)
+	switch {
+	case errors.Is(err, domain.ErrNoVerificationCode):
+	case err != nil:
+		return err
+	case pending.Email == email && time.Since(pending.SentAt) < u.resend:
+		return domain.ErrResendTooSoon
+	}
+
+	code, err := sms.GenerateVerificationCode()
+	if err != nil {
+		return err
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(code), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.New("could not hash verification code")
+	}
+	now := time.Now()
+	err = u.codes.Save(ctx, &domain.EmailVerification{
+		UserId:    userId,
+		Email:     email,
+		CodeHash:  string(hash),
+		ExpiresAt: now.Add(u.ttl),
+		SentAt:    now,
+	})
+	if err != nil {
+		return err
+	}
+	if err := u.mailer.SendCode(ctx, email, code); err != nil {
+		u.bot.SendErrorNotification(err)
+		// Dropped so the user can ask again right away.
+		_ = u.codes.Delete(ctx, userId)
+		return err
+	}
+	return nil
+}
+
+// Verify marks the email of the user verified when code is the one last
+// sent to it. Every guess counts towards the attempts, right or wrong, so
+// the code cannot be guessed by trying them all.
+func (u *verificationUseCase) Verify(ctx context.Context, userId int, code string) error {
+	email, verified, err := u.users.GetEmail(userId)
+	if err != nil {
+		return err
+	}
+	if verified {
+		return domain.ErrEmailAlreadyVerified
+	}
+	pending, err := u.codes.Get(ctx, userId)
+	if err != nil {
+		return err
+	}
+	// A code sent before the email was changed does not verify the new one.
+	if pending.Email != email {
+		return domain.ErrNoVerificationCode
+	}
+	if !time.Now().Before(pending.ExpiresAt) {
+		return domain.ErrVerificationCodeExpired
+	}
+	ok, err := u.codes.AddAttempt(ctx, userId, u.attempts)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return domain.ErrVerificationAttempts
+	}
+	if bcrypt.CompareHashAndPassword([]byte(pending.CodeHash), []byte(strings.TrimSpace(code))) != nil {
+		return domain.ErrInvalidVerificationCode
+	}
+
+	if err := u.users.UpdateVerified(userId); err != nil {
+		return err
+	}
+	return u.codes.Delete(ctx, userId)
+}
+
+func (u *verificationUseCase) IsVerified(ctx context.Context, userId int) (bool, error) {
+	_, verified, err := u.users.GetEmail(userId)
+	if err != nil {
+		return false, err
+	}
+	return verified, nil
+}
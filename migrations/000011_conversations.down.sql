@@ -0,0 +1,5 @@
+-- down_conversations_table.sql
+-- Drop conversations table
+DROP INDEX IF EXISTS idx_messages_conversation_id;
+ALTER TABLE messages DROP COLUMN IF EXISTS conversation_id;
+DROP TABLE IF EXISTS conversations;
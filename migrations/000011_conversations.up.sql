@@ -0,0 +1,14 @@
+-- conversations_table.sql
+-- Group chat messages into conversations so history can be replayed to the model
+CREATE TABLE IF NOT EXISTS conversations (
+                               id SERIAL PRIMARY KEY,
+                               user_id INT NOT NULL,
+                               title VARCHAR(255) NOT NULL DEFAULT '',
+                               created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+                               updated_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+                               deleted_at TIMESTAMP,
+                               CONSTRAINT fk_conversations_user FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS conversation_id INT REFERENCES conversations(id);
+CREATE INDEX IF NOT EXISTS idx_messages_conversation_id ON messages(conversation_id);
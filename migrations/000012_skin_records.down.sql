@@ -0,0 +1,4 @@
+-- down_skin_records_table.sql
+-- Drop skin records table
+DROP INDEX IF EXISTS idx_skin_records_user_location;
+DROP TABLE IF EXISTS skin_records;
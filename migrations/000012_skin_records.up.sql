@@ -0,0 +1,15 @@
+-- skin_records_table.sql
+-- Photos and AI analyses of a tracked body location, kept per user
+CREATE TABLE IF NOT EXISTS skin_records (
+                               id SERIAL PRIMARY KEY,
+                               user_id INT NOT NULL,
+                               location VARCHAR(120) NOT NULL,
+                               note TEXT NOT NULL DEFAULT '',
+                               image_path VARCHAR(255) NOT NULL,
+                               mime_type VARCHAR(30) NOT NULL,
+                               analysis JSONB NOT NULL,
+                               created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+                               CONSTRAINT fk_skin_records_user FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_skin_records_user_location ON skin_records(user_id, location, created_at);
@@ -0,0 +1,5 @@
+-- down_prompt_templates_table.sql
+-- Drop prompt templates table
+ALTER TABLE messages DROP COLUMN IF EXISTS prompt_template_id;
+DROP INDEX IF EXISTS idx_prompt_templates_active;
+DROP TABLE IF EXISTS prompt_templates;
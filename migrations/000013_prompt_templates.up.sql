@@ -0,0 +1,17 @@
+-- prompt_templates_table.sql
+-- Versioned prompts per name and locale; exactly one version is active at a time
+CREATE TABLE IF NOT EXISTS prompt_templates (
+                               id SERIAL PRIMARY KEY,
+                               name VARCHAR(30) NOT NULL,
+                               locale VARCHAR(10) NOT NULL DEFAULT 'en',
+                               version INT NOT NULL,
+                               body TEXT NOT NULL,
+                               is_active BOOLEAN NOT NULL DEFAULT false,
+                               created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+                               published_at TIMESTAMP,
+                               CONSTRAINT unique_prompt_version UNIQUE (name, locale, version)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_prompt_templates_active ON prompt_templates(name, locale) WHERE is_active;
+
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS prompt_template_id INT REFERENCES prompt_templates(id);
@@ -0,0 +1,6 @@
+-- down_ai_usage_table.sql
+-- Drop AI usage table
+DROP INDEX IF EXISTS idx_ai_usage_guest;
+DROP INDEX IF EXISTS idx_ai_usage_user;
+DROP INDEX IF EXISTS idx_ai_usage_created_at;
+DROP TABLE IF EXISTS ai_usage;
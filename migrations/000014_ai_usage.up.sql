@@ -0,0 +1,20 @@
+-- ai_usage_table.sql
+-- One row per model call: who made it, from which endpoint, with which model, and what it cost
+CREATE TABLE IF NOT EXISTS ai_usage (
+                               id BIGSERIAL PRIMARY KEY,
+                               user_id INT,
+                               guest_id VARCHAR(64),
+                               endpoint VARCHAR(120) NOT NULL DEFAULT '',
+                               provider VARCHAR(30) NOT NULL,
+                               model VARCHAR(100) NOT NULL,
+                               prompt_tokens INT NOT NULL DEFAULT 0,
+                               candidate_tokens INT NOT NULL DEFAULT 0,
+                               total_tokens INT NOT NULL DEFAULT 0,
+                               latency_ms INT NOT NULL,
+                               failed BOOLEAN NOT NULL DEFAULT FALSE,
+                               created_at TIMESTAMP NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_usage_created_at ON ai_usage(created_at);
+CREATE INDEX IF NOT EXISTS idx_ai_usage_user ON ai_usage(user_id, created_at) WHERE user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_ai_usage_guest ON ai_usage(guest_id, created_at) WHERE guest_id IS NOT NULL;
@@ -0,0 +1,4 @@
+-- down_analysis_cache_table.sql
+-- Drop analysis cache table
+DROP INDEX IF EXISTS idx_analysis_cache_expires_at;
+DROP TABLE IF EXISTS analysis_cache;
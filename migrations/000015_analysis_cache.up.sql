@@ -0,0 +1,10 @@
+-- analysis_cache_table.sql
+-- Shared tier of the AI image analysis cache, keyed by a hash of the image, prompts and model
+CREATE TABLE IF NOT EXISTS analysis_cache (
+                               key CHAR(64) PRIMARY KEY,
+                               value JSONB NOT NULL,
+                               created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+                               expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_analysis_cache_expires_at ON analysis_cache(expires_at);
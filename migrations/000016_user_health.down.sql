@@ -0,0 +1,4 @@
+-- down_user_health_table.sql
+-- Drop conditions and allergies from user_info
+ALTER TABLE user_info DROP COLUMN IF EXISTS allergies;
+ALTER TABLE user_info DROP COLUMN IF EXISTS conditions;
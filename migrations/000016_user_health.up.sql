@@ -0,0 +1,4 @@
+-- user_health_table.sql
+-- Known skin conditions and allergies the assistant takes into account
+ALTER TABLE user_info ADD COLUMN IF NOT EXISTS conditions TEXT NOT NULL DEFAULT '';
+ALTER TABLE user_info ADD COLUMN IF NOT EXISTS allergies TEXT NOT NULL DEFAULT '';
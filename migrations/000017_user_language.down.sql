@@ -0,0 +1,3 @@
+-- down_user_language_table.sql
+-- Drop language from user_info
+ALTER TABLE user_info DROP COLUMN IF EXISTS language;
@@ -0,0 +1,3 @@
+-- user_language_table.sql
+-- Language the assistant answers the user in; empty follows the app or browser
+ALTER TABLE user_info ADD COLUMN IF NOT EXISTS language TEXT NOT NULL DEFAULT '';
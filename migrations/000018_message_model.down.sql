@@ -0,0 +1,3 @@
+-- down_message_model_table.sql
+-- Drop model from messages
+ALTER TABLE messages DROP COLUMN IF EXISTS model;
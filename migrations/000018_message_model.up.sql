@@ -0,0 +1,3 @@
+-- message_model_table.sql
+-- Model that wrote an AI message, shown in exported reports
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS model VARCHAR(100) NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+-- down_conversation_summaries_table.sql
+-- Drop conversation_summaries table
+DROP TABLE IF EXISTS conversation_summaries;
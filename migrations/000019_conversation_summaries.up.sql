@@ -0,0 +1,10 @@
+-- conversation_summaries_table.sql
+-- Rolling summary of the older messages of a conversation, replayed instead of them
+CREATE TABLE IF NOT EXISTS conversation_summaries (
+                               conversation_id INT PRIMARY KEY REFERENCES conversations(id) ON DELETE CASCADE,
+                               summary TEXT NOT NULL,
+                               up_to_message_id INT NOT NULL,
+                               messages INT NOT NULL,
+                               model VARCHAR(100) NOT NULL DEFAULT '',
+                               updated_at TIMESTAMP NOT NULL DEFAULT current_timestamp
+);
@@ -0,0 +1,6 @@
+-- down_ai_rejections_table.sql
+-- Drop AI rejections table
+DROP INDEX IF EXISTS idx_ai_rejections_guest;
+DROP INDEX IF EXISTS idx_ai_rejections_user;
+DROP INDEX IF EXISTS idx_ai_rejections_created_at;
+DROP TABLE IF EXISTS ai_rejections;
@@ -0,0 +1,14 @@
+-- ai_rejections_table.sql
+-- One row per request the moderation stage turned away before it reached the model
+CREATE TABLE IF NOT EXISTS ai_rejections (
+                               id BIGSERIAL PRIMARY KEY,
+                               user_id INT,
+                               guest_id VARCHAR(64),
+                               endpoint VARCHAR(120) NOT NULL DEFAULT '',
+                               reason VARCHAR(30) NOT NULL,
+                               created_at TIMESTAMP NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_rejections_created_at ON ai_rejections(created_at);
+CREATE INDEX IF NOT EXISTS idx_ai_rejections_user ON ai_rejections(user_id, created_at) WHERE user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_ai_rejections_guest ON ai_rejections(guest_id, created_at) WHERE guest_id IS NOT NULL;
@@ -0,0 +1,4 @@
+-- down_fact_quiz_drafts_table.sql
+-- Drop fact_quiz_drafts table
+DROP INDEX IF EXISTS idx_fact_quiz_drafts_status;
+DROP TABLE IF EXISTS fact_quiz_drafts;
@@ -0,0 +1,13 @@
+-- fact_quiz_drafts_table.sql
+-- Generated quiz questions for a fact, waiting for an editor to approve them
+CREATE TABLE IF NOT EXISTS fact_quiz_drafts (
+                               id SERIAL PRIMARY KEY,
+                               fact_id INT NOT NULL,
+                               status VARCHAR(10) NOT NULL DEFAULT 'draft',
+                               questions JSONB NOT NULL,
+                               model VARCHAR(100) NOT NULL DEFAULT '',
+                               created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+                               reviewed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_fact_quiz_drafts_status ON fact_quiz_drafts(status, created_at);
@@ -0,0 +1,3 @@
+-- down_news_summaries_table.sql
+-- Drop news_summaries table
+DROP TABLE IF EXISTS news_summaries;
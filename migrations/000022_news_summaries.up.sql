@@ -0,0 +1,13 @@
+-- news_summaries_table.sql
+-- Plain-language summaries of fetched news articles, one per article and language
+CREATE TABLE IF NOT EXISTS news_summaries (
+                               article_id VARCHAR(100) NOT NULL,
+                               language VARCHAR(10) NOT NULL,
+                               summary TEXT NOT NULL,
+                               takeaways JSONB NOT NULL,
+                               relevance SMALLINT NOT NULL,
+                               source_hash CHAR(64) NOT NULL,
+                               model VARCHAR(100) NOT NULL DEFAULT '',
+                               created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+                               PRIMARY KEY (article_id, language)
+);
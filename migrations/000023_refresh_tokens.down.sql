@@ -0,0 +1,5 @@
+-- down_refresh_tokens_table.sql
+-- Drop refresh_tokens table
+DROP INDEX IF EXISTS idx_refresh_tokens_user;
+DROP INDEX IF EXISTS idx_refresh_tokens_family;
+DROP TABLE IF EXISTS refresh_tokens;
@@ -0,0 +1,18 @@
+-- refresh_tokens_table.sql
+-- Hashed refresh tokens; a family is the chain of rotations that started with one login
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+                               id BIGSERIAL PRIMARY KEY,
+                               user_id INT NOT NULL,
+                               role VARCHAR(10) NOT NULL DEFAULT 'user',
+                               family_id CHAR(32) NOT NULL,
+                               token_hash CHAR(64) NOT NULL,
+                               expires_at TIMESTAMP NOT NULL,
+                               used_at TIMESTAMP,
+                               revoked_at TIMESTAMP,
+                               created_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+                               CONSTRAINT unique_refresh_token_hash UNIQUE (token_hash),
+                               CONSTRAINT fk_refresh_tokens_user FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON refresh_tokens(family_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user ON refresh_tokens(user_id);
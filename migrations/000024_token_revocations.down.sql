@@ -0,0 +1,6 @@
+-- down_token_revocations_table.sql
+-- Drop revoked_tokens and user_token_revocations tables
+DROP INDEX IF EXISTS idx_user_token_revocations_before;
+DROP TABLE IF EXISTS user_token_revocations;
+DROP INDEX IF EXISTS idx_revoked_tokens_created;
+DROP TABLE IF EXISTS revoked_tokens;
@@ -0,0 +1,18 @@
+-- token_revocations_table.sql
+-- Access tokens signed out before they expire, by jti, and users signed out everywhere
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+                               jti CHAR(32) PRIMARY KEY,
+                               user_id INT,
+                               expires_at TIMESTAMP NOT NULL,
+                               created_at TIMESTAMP NOT NULL DEFAULT current_timestamp
+);
+
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_created ON revoked_tokens(created_at);
+
+CREATE TABLE IF NOT EXISTS user_token_revocations (
+                               user_id INT PRIMARY KEY,
+                               revoked_before TIMESTAMP NOT NULL,
+                               CONSTRAINT fk_user_token_revocations_user FOREIGN KEY (user_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_token_revocations_before ON user_token_revocations(revoked_before);
@@ -0,0 +1,3 @@
+-- down_email_verifications_table.sql
+-- Drop email_verifications table
+DROP TABLE IF EXISTS email_verifications;
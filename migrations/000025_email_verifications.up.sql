@@ -0,0 +1,11 @@
+-- email_verifications_table.sql
+-- The pending email verification code of a user, bcrypt-hashed, for the address it was sent to
+CREATE TABLE IF NOT EXISTS email_verifications (
+                               user_id INT PRIMARY KEY,
+                               email VARCHAR(255) NOT NULL,
+                               code_hash VARCHAR(60) NOT NULL,
+                               attempts INT NOT NULL DEFAULT 0,
+                               expires_at TIMESTAMP NOT NULL,
+                               sent_at TIMESTAMP NOT NULL DEFAULT current_timestamp,
+                               CONSTRAINT fk_email_verifications_user FOREIGN KEY (user_id) REFERENCES users(id)
+);
@@ -15,8 +15,6 @@ import (
 	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
-	"github.com/google/generative-ai-go/genai"
-	"google.golang.org/api/option"
 )
 
 // ──────────────────────────────────────────────
@@ -34,9 +32,10 @@ var (
 // ──────────────────────────────────────────────
 
 const (
-	chatID              = int64(-4103413678)
-	maxRecentErrors     = 10
-	healthCheckInterval = 6 * time.Hour
+	chatID               = int64(-4103413678)
+	maxRecentErrors      = 10
+	maxEscalationExcerpt = 300
+	healthCheckInterval  = 6 * time.Hour
 )
 
 // ──────────────────────────────────────────────
@@ -46,13 +45,15 @@ const (
 type bot struct {
 	*tgbotapi.BotAPI
 	db        *sql.DB
-	geminiKey string
+	ai        AIPinger
+	usage     UsageReporter
 	botToken  string
 	port      string
 	startTime time.Time
 	mu        sync.RWMutex
 	reqCount  int64
 	errCount  int64
+	escCount  int64
 	lastErrs  []errorEntry
 }
 
@@ -61,13 +62,37 @@ type errorEntry struct {
 	Message string
 }
 
+// AIPinger is the part of the AI provider the health checks need. It is
+// declared here so the bot does not depend on pkg/ai.
+type AIPinger interface {
+	Name() string
+	Model() string
+	Ping(ctx context.Context) error
+}
+
+// UsageReporter renders the AI token usage report of the /usage command.
+type UsageReporter interface {
+	UsageReport(ctx context.Context) (string, error)
+}
+
+// Escalation is a medical red flag raised by the AI safety layer.
+type Escalation struct {
+	Source   string // endpoint or feature, e.g. "chat", "upload"
+	Caller   string // "user 12" or "guest <id>"
+	RedFlags []string
+	Excerpt  string
+	Doctors  int // dermatologists offered to the patient
+}
+
 // Bot is the public interface for the Telegram monitoring bot.
 type Bot interface {
 	SendErrorNotification(err error)
 	SendNotification(mess string)
+	SendEscalation(e Escalation)
 	SendRequestLog(mess string)
 	StartCommandListener()
-	SetDependencies(db *sql.DB, geminiKey string, port string)
+	SetDependencies(db *sql.DB, ai AIPinger, port string)
+	SetUsageReporter(usage UsageReporter)
 	IncrementRequests()
 	RecordHTTPError(statusCode int, method, path string)
 }
@@ -85,15 +110,20 @@ func NewBot(botAPI *tgbotapi.BotAPI) Bot {
 }
 
 // SetDependencies injects runtime dependencies needed for health checks and stats
-func (b *bot) SetDependencies(db *sql.DB, geminiKey string, port string) {
+func (b *bot) SetDependencies(db *sql.DB, ai AIPinger, port string) {
 	b.db = db
-	b.geminiKey = geminiKey
+	b.ai = ai
 	b.port = port
 	if b.BotAPI != nil {
 		b.botToken = b.BotAPI.Token
 	}
 }
 
+// SetUsageReporter injects the source of the /usage command.
+func (b *bot) SetUsageReporter(usage UsageReporter) {
+	b.usage = usage
+}
+
 func (b *bot) IncrementRequests() {
 	b.mu.Lock()
 	defer b.mu.Unlock()
@@ -143,6 +173,23 @@ func (b *bot) SendNotification(message string) {
 	b.sendToChat(text)
 }
 
+// SendEscalation reports a red-flag answer. It is kept apart from errors and
+// plain notifications so the medical team can follow them on their own.
+func (b *bot) SendEscalation(e Escalation) {
+	b.mu.Lock()
+	b.escCount++
+	b.mu.Unlock()
+
+	excerpt := []rune(strings.ReplaceAll(e.Excerpt, "`", "'"))
+	if len(excerpt) > maxEscalationExcerpt {
+		excerpt = append(excerpt[:maxEscalationExcerpt], '…')
+	}
+	text := fmt.Sprintf("🚨 *Urgent referral* — %s\n👤 %s\n🚩 `%s`\n🩺 Dermatologists offered: %d\n```\n%s\n```\n_%s_",
+		e.Source, e.Caller, strings.Join(e.RedFlags, ", "), e.Doctors,
+		string(excerpt), time.Now().Format("2006/01/02 15:04:05"))
+	b.sendToChat(text)
+}
+
 // SendRequestLog sends a clean request log to the monitoring chat.
 // Falls back to plain text if Markdown parsing fails (e.g. special chars in paths).
 func (b *bot) SendRequestLog(message string) {
@@ -195,6 +242,7 @@ func (b *bot) registerCommands() {
 		{Command: "stats", Description: "Server statistics and metrics"},
 		{Command: "uptime", Description: "Server uptime info"},
 		{Command: "errors", Description: "Recent error log"},
+		{Command: "usage", Description: "AI token usage and cost"},
 		{Command: "dbstats", Description: "Database connection pool stats"},
 		{Command: "ping", Description: "Quick latency check"},
 		{Command: "version", Description: "Build and version info"},
@@ -261,7 +309,7 @@ func (b *bot) runScheduledHealthCheck() {
 	}{
 		{"PostgreSQL", b.checkPostgres},
 		{"Telegram API", b.checkTelegramAPI},
-		{"Gemini AI", b.checkGeminiAI},
+		{b.aiLabel(), b.checkAI},
 		{"HTTP Server", b.checkHTTPServer},
 	}
 
@@ -299,6 +347,7 @@ func (b *bot) commandHandlers() map[string]commandHandler {
 		"stats":   b.handleStats,
 		"uptime":  b.handleUptime,
 		"errors":  b.handleErrors,
+		"usage":   b.handleUsage,
 		"dbstats": b.handleDBStats,
 		"version": b.handleVersion,
 		"mem":     b.handleMem,
@@ -468,12 +517,12 @@ func (b *bot) handleHealth(targetChatID int64) {
 	checks := []checkResult{
 		{"PostgreSQL", false, "", ""},
 		{"Telegram Bot API", false, "", ""},
-		{"Gemini AI", false, "", ""},
+		{b.aiLabel(), false, "", ""},
 		{"HTTP Server", false, "", ""},
 	}
 	checks[0].up, checks[0].latency, checks[0].errMsg = b.checkPostgres()
 	checks[1].up, checks[1].latency, checks[1].errMsg = b.checkTelegramAPI()
-	checks[2].up, checks[2].latency, checks[2].errMsg = b.checkGeminiAI()
+	checks[2].up, checks[2].latency, checks[2].errMsg = b.checkAI()
 	checks[3].up, checks[3].latency, checks[3].errMsg = b.checkHTTPServer()
 
 	var sb strings.Builder
@@ -503,6 +552,7 @@ func (b *bot) handleStats(targetChatID int64) {
 	b.mu.RLock()
 	reqs := b.reqCount
 	errs := b.errCount
+	escs := b.escCount
 	b.mu.RUnlock()
 
 	uptime := time.Since(b.startTime).Round(time.Second)
@@ -530,12 +580,13 @@ func (b *bot) handleStats(targetChatID int64) {
 			"⏱ *Uptime:* `%s`\n"+
 			"🌐 *Requests:* `%d`\n"+
 			"❌ *Errors:* `%d` (%.1f%%)\n"+
+			"🚨 *Urgent referrals:* `%d`\n"+
 			"🔌 *DB Connections:*\n`%s`\n"+
 			"💾 *Memory:* `%.1f MB`\n"+
 			"🧵 *Goroutines:* `%d`\n"+
 			"🚪 *Port:* `%s`\n\n"+
 			"_Updated at %s_",
-		uptime, reqs, errs, errRate, dbStats,
+		uptime, reqs, errs, errRate, escs, dbStats,
 		float64(memStats.Alloc)/1024/1024,
 		runtime.NumGoroutine(),
 		b.port,
@@ -587,6 +638,23 @@ func (b *bot) handleErrors(targetChatID int64) {
 	b.sendReply(targetChatID, sb.String())
 }
 
+func (b *bot) handleUsage(targetChatID int64) {
+	if b.usage == nil {
+		b.sendReply(targetChatID, "❌ Usage accounting not available")
+		return
+	}
+	b.showTyping(targetChatID)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	text, err := b.usage.UsageReport(ctx)
+	if err != nil {
+		b.sendReply(targetChatID, fmt.Sprintf("❌ Could not load usage: `%s`", truncate(err.Error(), 200)))
+		return
+	}
+	b.sendReply(targetChatID, text)
+}
+
 func (b *bot) handleDBStats(targetChatID int64) {
 	if b.db == nil {
 		b.sendReply(targetChatID, "❌ Database connection not available")
@@ -691,7 +759,8 @@ func (b *bot) handleHelp(targetChatID int64) {
 		"/health — Check all service statuses\n" +
 		"/stats — Server statistics & metrics\n" +
 		"/uptime — Server uptime info\n" +
-		"/errors — Recent error log\n\n" +
+		"/errors — Recent error log\n" +
+		"/usage — AI token usage & cost\n\n" +
 		"*Diagnostics:*\n" +
 		"/dbstats — Database connection pool stats\n" +
 		"/ping — Quick latency check\n" +
@@ -734,26 +803,25 @@ func (b *bot) checkTelegramAPI() (up bool, latency string, errMsg string) {
 	return true, elapsed, ""
 }
 
-func (b *bot) checkGeminiAI() (up bool, latency string, errMsg string) {
-	if b.geminiKey == "" {
-		return false, "0ms", "API key not configured"
+func (b *bot) checkAI() (up bool, latency string, errMsg string) {
+	if b.ai == nil {
+		return false, "0ms", "AI provider not configured"
 	}
 	start := time.Now()
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	client, err := genai.NewClient(ctx, option.WithAPIKey(b.geminiKey))
-	if err != nil {
+	if err := b.ai.Ping(ctx); err != nil {
 		return false, time.Since(start).Round(time.Millisecond).String(), err.Error()
 	}
-	defer client.Close()
+	return true, time.Since(start).Round(time.Millisecond).String(), ""
+}
 
-	model := client.GenerativeModel("gemini-2.5-flash-lite")
-	_, err = model.CountTokens(ctx, genai.Text("ping"))
-	if err != nil {
-		return false, time.Since(start).Round(time.Millisecond).String(), err.Error()
+func (b *bot) aiLabel() string {
+	if b.ai == nil {
+		return "AI"
 	}
-	return true, time.Since(start).Round(time.Millisecond).String(), ""
+	return fmt.Sprintf("AI (%s, %s)", b.ai.Name(), b.ai.Model())
 }
 
 func (b *bot) checkHTTPServer() (up bool, latency string, errMsg string) {
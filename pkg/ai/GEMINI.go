@@ -3,84 +3,279 @@ package ai
 import (
 	"context"
 	"fmt"
-	"net/http"
 	"strings"
 
 	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
 
-type Dermato struct {
+const defaultGeminiModel = "gemini-2.5-flash-lite"
+
+// Gemini is the Provider backed by the Google Generative AI API.
+type Gemini struct {
 	client *genai.Client
 	model  *genai.GenerativeModel
+	name   string
 }
 
-func NewDermato(apiKey string) (*Dermato, error) {
+func NewGemini(cfg Config) (*Gemini, error) {
 	ctx := context.Background()
-	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
+	client, err := genai.NewClient(ctx, option.WithAPIKey(cfg.APIKey))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GenAI client: %w", err)
 	}
-	return &Dermato{client: client}, nil
+
+	name := cfg.Model
+	if name == "" {
+		name = defaultGeminiModel
+	}
+	m := client.GenerativeModel(name)
+	m.SetTemperature(cfg.Temperature)
+	m.SetTopP(cfg.TopP)
+	m.SetTopK(cfg.TopK)
+	m.SetMaxOutputTokens(cfg.MaxTokens)
+	if cfg.Instruction != "" {
+		m.SystemInstruction = genai.NewUserContent(genai.Text(cfg.Instruction))
+	}
+	return &Gemini{client: client, model: m, name: name}, nil
+}
+
+func (g *Gemini) Name() string {
+	return ProviderGemini
 }
 
-func (d *Dermato) Configure(instruction string, temp, topP float32, topK, maxTokens int32) {
-	m := d.client.GenerativeModel("gemini-2.5-flash-lite")
-	m.SetTemperature(temp)
-	m.SetTopP(topP)
-	m.SetTopK(topK)
-	m.SetMaxOutputTokens(maxTokens)
-	m.SystemInstruction = genai.NewUserContent(genai.Text(instruction))
-	d.model = m
+func (g *Gemini) Model() string {
+	return g.name
 }
 
-func (d *Dermato) GenerateResponse(ctx context.Context, req string) (string, error) {
-	if d.model == nil {
-		return "", fmt.Errorf("model not configured. Call Configure() first")
+// Generate answers req in the context of its history. Consecutive turns from
+// the same role are merged because the API expects user and model turns to
+// alternate.
+func (g *Gemini) Generate(ctx context.Context, req Request) (*Result, error) {
+	if err := validate(req); err != nil {
+		return nil, err
 	}
 
-	resp, err := d.model.GenerateContent(ctx, genai.Text(req))
+	cs := g.modelFor(req).StartChat()
+	cs.History = buildHistory(req.History)
+
+	resp, err := cs.SendMessage(ctx, parts(req)...)
 	if err != nil {
-		return "", fmt.Errorf("failed to generate response: %w", err)
+		return nil, fmt.Errorf("failed to generate response: %w", err)
 	}
 
+	calls := candidateCalls(resp)
 	text, err := extractFirstCandidateText(resp)
+	if err != nil && len(calls) == 0 {
+		return nil, err
+	}
+	return &Result{
+		Text:         text,
+		FinishReason: finishReason(resp),
+		Usage:        usage(resp),
+		Calls:        calls,
+	}, nil
+}
+
+func (g *Gemini) Stream(ctx context.Context, req Request, onChunk func(string) error) (*Result, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	req.Tools = nil
+
+	cs := g.modelFor(req).StartChat()
+	cs.History = buildHistory(req.History)
+
+	return stream(cs.SendMessageStream(ctx, parts(req)...), onChunk)
+}
+
+func (g *Gemini) CountTokens(ctx context.Context, text string) (int32, error) {
+	resp, err := g.model.CountTokens(ctx, genai.Text(text))
 	if err != nil {
-		return "", err
+		return 0, fmt.Errorf("failed to count tokens: %w", err)
+	}
+	return resp.TotalTokens, nil
+}
+
+// Ping counts the tokens of a fixed string: it authenticates and reaches the
+// model without paying for a generation.
+func (g *Gemini) Ping(ctx context.Context) error {
+	_, err := g.CountTokens(ctx, "ping")
+	return err
+}
+
+// modelFor returns the configured model, or a copy of it when the request
+// changes the generation settings.
+func (g *Gemini) modelFor(req Request) *genai.GenerativeModel {
+	if req.System == "" && req.Schema == nil && req.MaxTokens <= 0 && len(req.Tools) == 0 {
+		return g.model
+	}
+	m := *g.model
+	if req.System != "" {
+		m.SystemInstruction = genai.NewUserContent(genai.Text(req.System))
+	}
+	if req.Schema != nil {
+		m.ResponseMIMEType = "application/json"
+		m.ResponseSchema = toGenaiSchema(req.Schema)
+	}
+	if req.MaxTokens > 0 {
+		m.SetMaxOutputTokens(req.MaxTokens)
+	}
+	if len(req.Tools) > 0 {
+		decls := make([]*genai.FunctionDeclaration, len(req.Tools))
+		for i, t := range req.Tools {
+			decls[i] = &genai.FunctionDeclaration{
+				Name:        t.Name,
+				Description: t.Description,
+				Parameters:  toGenaiSchema(t.Parameters),
+			}
+		}
+		m.Tools = []*genai.Tool{{FunctionDeclarations: decls}}
+		if req.NoToolCalls {
+			m.ToolConfig = &genai.ToolConfig{
+				FunctionCallingConfig: &genai.FunctionCallingConfig{Mode: genai.FunctionCallingNone},
+			}
+		}
 	}
+	return &m
+}
 
-	return text, nil
+var genaiTypes = map[string]genai.Type{
+	TypeObject:  genai.TypeObject,
+	TypeArray:   genai.TypeArray,
+	TypeString:  genai.TypeString,
+	TypeNumber:  genai.TypeNumber,
+	TypeInteger: genai.TypeInteger,
+	TypeBoolean: genai.TypeBoolean,
 }
 
-func (d *Dermato) GenerateImageResponse(ctx context.Context, imageData []byte, mimeType, prompt string) (string, error) {
-	if d.model == nil {
-		return "", fmt.Errorf("model not configured. Call Configure() first")
+func toGenaiSchema(s *Schema) *genai.Schema {
+	if s == nil {
+		return nil
 	}
-	if len(imageData) == 0 {
-		return "", fmt.Errorf("no image data provided")
+	out := &genai.Schema{
+		Type:        genaiTypes[s.Type],
+		Description: s.Description,
+		Enum:        s.Enum,
+		Items:       toGenaiSchema(s.Items),
+		Required:    s.Required,
 	}
+	if len(s.Enum) > 0 {
+		out.Format = "enum"
+	}
+	if len(s.Properties) > 0 {
+		out.Properties = make(map[string]*genai.Schema, len(s.Properties))
+		for name, p := range s.Properties {
+			out.Properties[name] = toGenaiSchema(p)
+		}
+	}
+	return out
+}
 
-	// Use the provided MIME type directly (from multipart header or fallback detection)
-	if mimeType == "" || mimeType == "application/octet-stream" {
-		mimeType = http.DetectContentType(imageData)
+// parts puts the images before the prompt, which is the order the model
+// handles best. Tool results come first: they answer the last model turn.
+func parts(req Request) []genai.Part {
+	ps := make([]genai.Part, 0, len(req.ToolResults)+len(req.Images)+1)
+	ps = append(ps, resultParts(req.ToolResults)...)
+	for _, img := range req.Images {
+		ps = append(ps, genai.Blob{MIMEType: imageMIMEType(img), Data: img.Data})
+	}
+	if req.Prompt != "" {
+		ps = append(ps, genai.Text(req.Prompt))
 	}
+	return ps
+}
 
-	imagePart := genai.Blob{MIMEType: mimeType, Data: imageData}
+// stream drains iter, forwarding text chunks and collecting the final
+// finish reason and usage, which the API only sends with the last chunks.
+func stream(iter *genai.GenerateContentResponseIterator, onChunk func(string) error) (*Result, error) {
+	var (
+		b   strings.Builder
+		res Result
+	)
+	for {
+		resp, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to stream response: %w", err)
+		}
 
-	resp, err := d.model.GenerateContent(ctx, imagePart, genai.Text(prompt))
-	if err != nil {
-		return "", fmt.Errorf("failed to generate image response: %w", err)
+		if chunk := candidateText(resp); chunk != "" {
+			b.WriteString(chunk)
+			if err := onChunk(chunk); err != nil {
+				return nil, err
+			}
+		}
+		if fr := finishReason(resp); fr != "" {
+			res.FinishReason = fr
+		}
+		if resp.UsageMetadata != nil {
+			res.Usage = usage(resp)
+		}
 	}
 
-	text, err := extractFirstCandidateText(resp)
-	if err != nil {
-		return "", err
+	res.Text = strings.TrimSpace(b.String())
+	if res.Text == "" {
+		return nil, fmt.Errorf("response candidate has no text parts")
 	}
+	return &res, nil
+}
 
-	return text, nil
+// buildHistory converts stored turns to genai contents.
+func buildHistory(turns []Turn) []*genai.Content {
+	var history []*genai.Content
+	for _, t := range turns {
+		ps := resultParts(t.Results)
+		if strings.TrimSpace(t.Text) != "" {
+			ps = append(ps, genai.Text(t.Text))
+		}
+		for _, c := range t.Calls {
+			ps = append(ps, genai.FunctionCall{Name: c.Name, Args: c.Args})
+		}
+		if len(ps) == 0 {
+			continue
+		}
+		role := RoleUser
+		if t.Role == RoleModel {
+			role = RoleModel
+		}
+		if n := len(history); n > 0 && history[n-1].Role == role {
+			history[n-1].Parts = append(history[n-1].Parts, ps...)
+			continue
+		}
+		history = append(history, &genai.Content{
+			Role:  role,
+			Parts: ps,
+		})
+	}
+	return history
 }
 
+func resultParts(results []ToolResult) []genai.Part {
+	ps := make([]genai.Part, 0, len(results))
+	for _, r := range results {
+		ps = append(ps, genai.FunctionResponse{Name: r.Name, Response: r.Response})
+	}
+	return ps
+}
 
+// candidateCalls returns the function calls of the first candidate. Gemini
+// has no call ids, so the name stands in.
+func candidateCalls(resp *genai.GenerateContentResponse) []ToolCall {
+	if resp == nil || len(resp.Candidates) == 0 || resp.Candidates[0] == nil || resp.Candidates[0].Content == nil {
+		return nil
+	}
+	var calls []ToolCall
+	for _, part := range resp.Candidates[0].Content.Parts {
+		if fc, ok := part.(genai.FunctionCall); ok {
+			calls = append(calls, ToolCall{ID: fc.Name, Name: fc.Name, Args: fc.Args})
+		}
+	}
+	return calls
+}
 
 // helper to keep both methods consistent
 func extractFirstCandidateText(resp *genai.GenerateContentResponse) (string, error) {
@@ -97,19 +292,58 @@ func extractFirstCandidateText(resp *genai.GenerateContentResponse) (string, err
 		return "", fmt.Errorf("response candidate has no content")
 	}
 
+	out := strings.TrimSpace(candidateText(resp))
+	if out == "" {
+		return "", fmt.Errorf("response candidate has no text parts")
+	}
+
+	return out, nil
+}
+
+// candidateText joins the text parts of the first candidate without trimming,
+// so streamed chunks keep their spacing.
+func candidateText(resp *genai.GenerateContentResponse) string {
+	if resp == nil || len(resp.Candidates) == 0 {
+		return ""
+	}
+	cand := resp.Candidates[0]
+	if cand == nil || cand.Content == nil {
+		return ""
+	}
+
 	var b strings.Builder
 	for _, part := range cand.Content.Parts {
 		switch v := part.(type) {
 		case genai.Text:
 			b.WriteString(string(v))
-		// you can add other cases here if you want to support more types
+			// you can add other cases here if you want to support more types
 		}
 	}
+	return b.String()
+}
 
-	out := strings.TrimSpace(b.String())
-	if out == "" {
-		return "", fmt.Errorf("response candidate has no text parts")
+var finishReasons = map[genai.FinishReason]string{
+	genai.FinishReasonStop:       "stop",
+	genai.FinishReasonMaxTokens:  "max_tokens",
+	genai.FinishReasonSafety:     "safety",
+	genai.FinishReasonRecitation: "recitation",
+	genai.FinishReasonOther:      "other",
+}
+
+func finishReason(resp *genai.GenerateContentResponse) string {
+	if resp == nil || len(resp.Candidates) == 0 || resp.Candidates[0] == nil {
+		return ""
 	}
+	return finishReasons[resp.Candidates[0].FinishReason]
+}
 
-	return out, nil
+func usage(resp *genai.GenerateContentResponse) Usage {
+	if resp == nil || resp.UsageMetadata == nil {
+		return Usage{}
+	}
+	return Usage{
+		PromptTokens:    resp.UsageMetadata.PromptTokenCount,
+		CandidateTokens: resp.UsageMetadata.CandidatesTokenCount,
+		TotalTokens:     resp.UsageMetadata.TotalTokenCount,
+	}
 }
@@ -0,0 +1,176 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Provider is a generative model backend. The REST layer, use cases, health
+// checks and the monitoring bot only talk to this interface, so the vendor is
+// a configuration choice (see New).
+type Provider interface {
+	// Name is the backend kind, e.g. "gemini".
+	Name() string
+	// Model is the model identifier requests are sent to.
+	Model() string
+	// Generate answers a text or image+text request in one piece.
+	Generate(ctx context.Context, req Request) (*Result, error)
+	// Stream is Generate with every text chunk handed to onChunk as soon as
+	// it arrives. Cancelling ctx or returning an error from onChunk aborts
+	// the upstream request.
+	Stream(ctx context.Context, req Request, onChunk func(string) error) (*Result, error)
+	// CountTokens reports how many prompt tokens text costs.
+	CountTokens(ctx context.Context, text string) (int32, error)
+	// Ping checks that the backend is reachable with the configured key.
+	Ping(ctx context.Context) error
+}
+
+const (
+	ProviderGemini = "gemini"
+	ProviderOpenAI = "openai"
+	ProviderFake   = "fake"
+)
+
+const (
+	RoleUser  = "user"
+	RoleModel = "model"
+)
+
+// Turn is one message of an earlier conversation that is replayed to the
+// model as chat history. A model turn may carry the tool calls it asked
+// for, and the user turn after it their results.
+type Turn struct {
+	Role    string
+	Text    string
+	Calls   []ToolCall
+	Results []ToolResult
+}
+
+// Image is an inline image sent along with the prompt.
+type Image struct {
+	Data     []byte
+	MIMEType string
+}
+
+// Request is a single call to the model: optional history, optional images
+// and the new prompt.
+type Request struct {
+	// System overrides the configured system instruction when set.
+	System  string
+	History []Turn
+	Images  []Image
+	Prompt  string
+	// Schema, when set, asks for a single JSON value matching it instead of
+	// free text.
+	Schema *Schema
+	// MaxTokens overrides the configured output limit when positive.
+	MaxTokens int32
+	// Tools the model may call instead of answering. Only Generate offers
+	// them; the calls come back in Result.Calls.
+	Tools []Tool
+	// NoToolCalls keeps Tools declared, as a History with tool calls needs
+	// them, but has the model answer in text. It is left out of fixture
+	// keys when unset.
+	NoToolCalls bool `json:",omitempty"`
+	// ToolResults answer the calls of the last model turn of History and are
+	// sent in place of, or before, the prompt.
+	ToolResults []ToolResult
+}
+
+const (
+	TypeObject  = "object"
+	TypeArray   = "array"
+	TypeString  = "string"
+	TypeNumber  = "number"
+	TypeInteger = "integer"
+	TypeBoolean = "boolean"
+)
+
+// Schema is the subset of JSON Schema every provider understands. It
+// marshals to plain JSON Schema.
+type Schema struct {
+	Type        string             `json:"type"`
+	Description string             `json:"description,omitempty"`
+	Enum        []string           `json:"enum,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+}
+
+// Usage is the token accounting the model reports for one call.
+type Usage struct {
+	PromptTokens    int32
+	CandidateTokens int32
+	TotalTokens     int32
+}
+
+// Result is a complete answer together with why generation stopped and
+// what it cost.
+type Result struct {
+	Text         string
+	FinishReason string
+	Usage        Usage
+	// Calls are the tools the model wants called; Text may then be empty.
+	Calls []ToolCall
+	// Model is the model that answered when it may not be that of the
+	// Provider, e.g. the fallback of WithResilience; see ModelOf.
+	Model string
+}
+
+// ModelOf is the model that wrote res, answered by p.
+func ModelOf(p Provider, res *Result) string {
+	if res != nil && res.Model != "" {
+		return res.Model
+	}
+	return p.Model()
+}
+
+// Config selects and tunes a Provider.
+type Config struct {
+	Provider    string
+	Model       string
+	APIKey      string
+	BaseURL     string
+	Instruction string
+	Temperature float32
+	TopP        float32
+	TopK        int32
+	MaxTokens   int32
+}
+
+// New builds the provider named in cfg.Provider; an empty name means Gemini.
+func New(cfg Config) (Provider, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case "", ProviderGemini:
+		return NewGemini(cfg)
+	case ProviderOpenAI:
+		return NewOpenAI(cfg)
+	case ProviderFake:
+		return NewFake(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown AI provider %q", cfg.Provider)
+	}
+}
+
+func validate(req Request) error {
+	if strings.TrimSpace(req.Prompt) == "" && len(req.Images) == 0 && len(req.ToolResults) == 0 {
+		return fmt.Errorf("empty request")
+	}
+	for _, img := range req.Images {
+		if len(img.Data) == 0 {
+			return fmt.Errorf("no image data provided")
+		}
+	}
+	return nil
+}
+
+// imageMIMEType uses the provided MIME type directly (from multipart header)
+// and falls back to sniffing the bytes.
+func imageMIMEType(img Image) string {
+	if img.MIMEType == "" || img.MIMEType == "application/octet-stream" {
+		return http.DetectContentType(img.Data)
+	}
+	return img.MIMEType
+}
@@ -0,0 +1,155 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+const defaultFakeModel = "fake-1"
+
+// Fake is a deterministic offline Provider for tests and CI: the same request
+// always gets the same answer, and nothing leaves the process.
+type Fake struct {
+	model string
+}
+
+func NewFake(cfg Config) *Fake {
+	model := cfg.Model
+	if model == "" {
+		model = defaultFakeModel
+	}
+	return &Fake{model: model}
+}
+
+func (f *Fake) Name() string {
+	return ProviderFake
+}
+
+func (f *Fake) Model() string {
+	return f.model
+}
+
+func (f *Fake) Generate(ctx context.Context, req Request) (*Result, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if calls := fakeCalls(req); len(calls) > 0 {
+		return &Result{FinishReason: "stop", Calls: calls}, nil
+	}
+
+	text := f.answer(req)
+	prompt := countWords(req.Prompt)
+	for _, t := range req.History {
+		prompt += countWords(t.Text)
+	}
+	candidate := countWords(text)
+	return &Result{
+		Text:         text,
+		FinishReason: "stop",
+		Usage: Usage{
+			PromptTokens:    prompt,
+			CandidateTokens: candidate,
+			TotalTokens:     prompt + candidate,
+		},
+	}, nil
+}
+
+// Stream sends the answer one word at a time.
+func (f *Fake) Stream(ctx context.Context, req Request, onChunk func(string) error) (*Result, error) {
+	res, err := f.Generate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for i, word := range strings.Fields(res.Text) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			word = " " + word
+		}
+		if err := onChunk(word); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (f *Fake) CountTokens(ctx context.Context, text string) (int32, error) {
+	return countWords(text), nil
+}
+
+func (f *Fake) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (f *Fake) answer(req Request) string {
+	if req.Schema != nil {
+		out, _ := json.Marshal(fakeValue(req.Schema))
+		return string(out)
+	}
+	prompt := strings.Join(strings.Fields(req.Prompt), " ")
+	if len(req.ToolResults) > 0 {
+		names := make([]string, len(req.ToolResults))
+		for i, r := range req.ToolResults {
+			names[i] = r.Name
+		}
+		return fmt.Sprintf("Fake answer from %s after %d turn(s)", strings.Join(names, ", "), len(req.History))
+	}
+	if len(req.Images) > 0 {
+		return fmt.Sprintf("Fake answer for %d image(s) after %d turn(s): %s", len(req.Images), len(req.History), prompt)
+	}
+	return fmt.Sprintf("Fake answer after %d turn(s): %s", len(req.History), prompt)
+}
+
+// fakeCalls calls, without arguments, every offered tool the prompt names,
+// so tests can drive an agent loop by wording the question.
+func fakeCalls(req Request) []ToolCall {
+	if len(req.ToolResults) > 0 || req.NoToolCalls {
+		return nil
+	}
+	var calls []ToolCall
+	for _, t := range req.Tools {
+		if strings.Contains(req.Prompt, t.Name) {
+			calls = append(calls, ToolCall{ID: t.Name, Name: t.Name, Args: map[string]interface{}{}})
+		}
+	}
+	return calls
+}
+
+// fakeValue builds the smallest value that satisfies s: the first enum
+// value, one array item, every property set.
+func fakeValue(s *Schema) interface{} {
+	switch s.Type {
+	case TypeObject:
+		obj := make(map[string]interface{}, len(s.Properties))
+		for name, p := range s.Properties {
+			obj[name] = fakeValue(p)
+		}
+		return obj
+	case TypeArray:
+		if s.Items == nil {
+			return []interface{}{}
+		}
+		return []interface{}{fakeValue(s.Items)}
+	case TypeNumber:
+		return 0.5
+	case TypeInteger:
+		return 1
+	case TypeBoolean:
+		return false
+	default:
+		if len(s.Enum) > 0 {
+			return s.Enum[0]
+		}
+		return "fake"
+	}
+}
+
+func countWords(text string) int32 {
+	return int32(len(strings.Fields(text)))
+}
@@ -0,0 +1,170 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const ProviderReplay = "replay"
+
+// ErrNoFixture is returned by Replay for a request that was never recorded.
+var ErrNoFixture = errors.New("no fixture recorded for the request")
+
+// Fixture is one recorded answer, stored as <Key>.json. The prompt is kept
+// for whoever reads or reviews the fixtures; only Key is matched.
+type Fixture struct {
+	Key    string `json:"key"`
+	Model  string `json:"model"`
+	System string `json:"system,omitempty"`
+	Prompt string `json:"prompt"`
+	Images int    `json:"images,omitempty"`
+	Result Result `json:"result"`
+}
+
+// FixtureKey identifies a request by everything sent to the model but the
+// model itself, so answers recorded from one model replay for another.
+func FixtureKey(req Request) string {
+	images := make([]string, len(req.Images))
+	for i, img := range req.Images {
+		sum := sha256.Sum256(img.Data)
+		images[i] = hex.EncodeToString(sum[:])
+	}
+	data, _ := json.Marshal(struct {
+		Request
+		Images []string
+	}{req, images})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+type recording struct {
+	Provider
+	dir string
+}
+
+// WithFixtures wraps p so every answer it gives is also saved as a fixture
+// in dir, for Replay to serve offline.
+func WithFixtures(p Provider, dir string) (Provider, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &recording{Provider: p, dir: dir}, nil
+}
+
+func (r *recording) Generate(ctx context.Context, req Request) (*Result, error) {
+	res, err := r.Provider.Generate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return res, r.save(req, res)
+}
+
+func (r *recording) Stream(ctx context.Context, req Request, onChunk func(string) error) (*Result, error) {
+	res, err := r.Provider.Stream(ctx, req, onChunk)
+	if err != nil {
+		return nil, err
+	}
+	return res, r.save(req, res)
+}
+
+func (r *recording) save(req Request, res *Result) error {
+	fixture := Fixture{
+		Key:    FixtureKey(req),
+		Model:  r.Model(),
+		System: req.System,
+		Prompt: req.Prompt,
+		Images: len(req.Images),
+		Result: *res,
+	}
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(r.dir, fixture.Key+".json"), append(data, '\n'), 0o644)
+}
+
+// Replay is a fake Provider that answers from the fixtures recorded by
+// WithFixtures, so evaluations run offline and give the same answers every
+// time. A request that was not recorded fails with ErrNoFixture.
+type Replay struct {
+	dir string
+}
+
+func NewReplay(dir string) *Replay {
+	return &Replay{dir: dir}
+}
+
+func (r *Replay) Name() string {
+	return ProviderReplay
+}
+
+func (r *Replay) Model() string {
+	return ProviderReplay
+}
+
+func (r *Replay) Generate(ctx context.Context, req Request) (*Result, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	fixture, err := r.Fixture(req)
+	if err != nil {
+		return nil, err
+	}
+	res := fixture.Result
+	return &res, nil
+}
+
+// Stream sends the recorded answer one word at a time.
+func (r *Replay) Stream(ctx context.Context, req Request, onChunk func(string) error) (*Result, error) {
+	res, err := r.Generate(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	for i, word := range strings.Fields(res.Text) {
+		if i > 0 {
+			word = " " + word
+		}
+		if err := onChunk(word); err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+func (r *Replay) CountTokens(ctx context.Context, text string) (int32, error) {
+	return countWords(text), nil
+}
+
+func (r *Replay) Ping(ctx context.Context) error {
+	if _, err := os.Stat(r.dir); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// Fixture returns the recorded answer to req.
+func (r *Replay) Fixture(req Request) (*Fixture, error) {
+	key := FixtureKey(req)
+	data, err := os.ReadFile(filepath.Join(r.dir, key+".json"))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w (key %s)", ErrNoFixture, key[:12])
+	}
+	if err != nil {
+		return nil, err
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("fixture %s: %w", key[:12], err)
+	}
+	return &fixture, nil
+}
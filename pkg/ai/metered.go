@@ -0,0 +1,80 @@
+package ai
+
+import (
+	"context"
+	"time"
+)
+
+// Tag says on whose behalf and from where a model call is made. It travels in
+// the context so every call made while serving a request is attributed to
+// that request, however deep in the use cases it happens.
+type Tag struct {
+	UserID   int
+	GuestID  string
+	Endpoint string
+}
+
+type tagKey struct{}
+
+func WithTag(ctx context.Context, tag Tag) context.Context {
+	return context.WithValue(ctx, tagKey{}, tag)
+}
+
+// TagFrom returns the tag set by WithTag, the zero Tag when there is none.
+func TagFrom(ctx context.Context) Tag {
+	tag, _ := ctx.Value(tagKey{}).(Tag)
+	return tag
+}
+
+// Call describes one finished Generate or Stream call.
+type Call struct {
+	Tag      Tag
+	Provider string
+	Model    string
+	Usage    Usage
+	Latency  time.Duration
+	Err      error
+}
+
+// Recorder receives every call of a metered provider. It runs on the
+// caller's goroutine after the call returned, so it should be quick.
+type Recorder func(ctx context.Context, call Call)
+
+type metered struct {
+	Provider
+	record Recorder
+}
+
+// WithRecorder wraps p so every Generate and Stream call, failed ones
+// included, is reported to record.
+func WithRecorder(p Provider, record Recorder) Provider {
+	return &metered{Provider: p, record: record}
+}
+
+func (m *metered) Generate(ctx context.Context, req Request) (*Result, error) {
+	start := time.Now()
+	res, err := m.Provider.Generate(ctx, req)
+	m.report(ctx, start, res, err)
+	return res, err
+}
+
+func (m *metered) Stream(ctx context.Context, req Request, onChunk func(string) error) (*Result, error) {
+	start := time.Now()
+	res, err := m.Provider.Stream(ctx, req, onChunk)
+	m.report(ctx, start, res, err)
+	return res, err
+}
+
+func (m *metered) report(ctx context.Context, start time.Time, res *Result, err error) {
+	call := Call{
+		Tag:      TagFrom(ctx),
+		Provider: m.Name(),
+		Model:    m.Model(),
+		Latency:  time.Since(start),
+		Err:      err,
+	}
+	if res != nil {
+		call.Usage = res.Usage
+	}
+	m.record(ctx, call)
+}
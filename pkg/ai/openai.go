@@ -0,0 +1,442 @@
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	defaultOpenAIBaseURL = "https://api.openai.com/v1"
+	defaultOpenAIModel   = "gpt-4o-mini"
+)
+
+// OpenAI is the Provider for any server speaking the OpenAI chat completions
+// API (OpenAI itself, Azure, OpenRouter, vLLM, Ollama, ...).
+type OpenAI struct {
+	client  *http.Client
+	baseURL string
+	apiKey  string
+	model   string
+	cfg     Config
+}
+
+func NewOpenAI(cfg Config) (*OpenAI, error) {
+	baseURL := strings.TrimRight(cfg.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = defaultOpenAIBaseURL
+	}
+	model := cfg.Model
+	if model == "" {
+		model = defaultOpenAIModel
+	}
+	return &OpenAI{
+		client:  &http.Client{Timeout: 2 * time.Minute},
+		baseURL: baseURL,
+		apiKey:  cfg.APIKey,
+		model:   model,
+		cfg:     cfg,
+	}, nil
+}
+
+func (o *OpenAI) Name() string {
+	return ProviderOpenAI
+}
+
+func (o *OpenAI) Model() string {
+	return o.model
+}
+
+type openAIMessage struct {
+	Role       string           `json:"role"`
+	Content    interface{}      `json:"content"`
+	ToolCalls  []openAIToolCall `json:"tool_calls,omitempty"`
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+}
+
+type openAITool struct {
+	Type     string             `json:"type"`
+	Function openAIFunctionDecl `json:"function"`
+}
+
+type openAIFunctionDecl struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Parameters  *Schema `json:"parameters,omitempty"`
+}
+
+// openAIToolCall carries the arguments as a JSON encoded string, both ways.
+type openAIToolCall struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	Function struct {
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type openAIContentPart struct {
+	Type     string          `json:"type"`
+	Text     string          `json:"text,omitempty"`
+	ImageURL *openAIImageURL `json:"image_url,omitempty"`
+}
+
+type openAIImageURL struct {
+	URL string `json:"url"`
+}
+
+type openAIRequest struct {
+	Model          string                `json:"model"`
+	Messages       []openAIMessage       `json:"messages"`
+	Temperature    float32               `json:"temperature,omitempty"`
+	TopP           float32               `json:"top_p,omitempty"`
+	MaxTokens      int32                 `json:"max_tokens,omitempty"`
+	Stream         bool                  `json:"stream,omitempty"`
+	StreamOptions  *openAIStreamOptions  `json:"stream_options,omitempty"`
+	ResponseFormat *openAIResponseFormat `json:"response_format,omitempty"`
+	Tools          []openAITool          `json:"tools,omitempty"`
+	ToolChoice     string                `json:"tool_choice,omitempty"`
+}
+
+type openAIResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *openAIJSONSchema `json:"json_schema,omitempty"`
+}
+
+type openAIJSONSchema struct {
+	Name   string  `json:"name"`
+	Schema *Schema `json:"schema"`
+}
+
+type openAIStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type openAIUsage struct {
+	PromptTokens     int32 `json:"prompt_tokens"`
+	CompletionTokens int32 `json:"completion_tokens"`
+	TotalTokens      int32 `json:"total_tokens"`
+}
+
+// openAIResponse covers both the complete answer (message) and the streamed
+// chunks (delta).
+type openAIResponse struct {
+	Choices []struct {
+		Message struct {
+			Content   string           `json:"content"`
+			ToolCalls []openAIToolCall `json:"tool_calls"`
+		} `json:"message"`
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *openAIUsage `json:"usage"`
+}
+
+func (o *OpenAI) Generate(ctx context.Context, req Request) (*Result, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := o.do(ctx, o.chatRequest(req, false))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate response: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var out openAIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(out.Choices) == 0 {
+		return nil, fmt.Errorf("no response candidates generated")
+	}
+
+	calls, err := fromOpenAICalls(out.Choices[0].Message.ToolCalls)
+	if err != nil {
+		return nil, err
+	}
+	text := strings.TrimSpace(out.Choices[0].Message.Content)
+	if text == "" && len(calls) == 0 {
+		return nil, fmt.Errorf("response candidate has no text parts")
+	}
+	return &Result{
+		Text:         text,
+		FinishReason: openAIFinishReason(out.Choices[0].FinishReason),
+		Usage:        out.Usage.toUsage(),
+		Calls:        calls,
+	}, nil
+}
+
+// Stream reads the server-sent "data:" lines until "[DONE]".
+func (o *OpenAI) Stream(ctx context.Context, req Request, onChunk func(string) error) (*Result, error) {
+	if err := validate(req); err != nil {
+		return nil, err
+	}
+
+	req.Tools = nil
+	resp, err := o.do(ctx, o.chatRequest(req, true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to stream response: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var (
+		b   strings.Builder
+		res Result
+	)
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk openAIResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to decode stream chunk: %w", err)
+		}
+		if chunk.Usage != nil {
+			res.Usage = chunk.Usage.toUsage()
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		if text := chunk.Choices[0].Delta.Content; text != "" {
+			b.WriteString(text)
+			if err := onChunk(text); err != nil {
+				return nil, err
+			}
+		}
+		if fr := chunk.Choices[0].FinishReason; fr != "" {
+			res.FinishReason = openAIFinishReason(fr)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to stream response: %w", err)
+	}
+
+	res.Text = strings.TrimSpace(b.String())
+	if res.Text == "" {
+		return nil, fmt.Errorf("response candidate has no text parts")
+	}
+	return &res, nil
+}
+
+// CountTokens estimates the count: the chat completions API has no token
+// counting endpoint, and roughly four characters per token holds for the
+// common tokenizers.
+func (o *OpenAI) CountTokens(ctx context.Context, text string) (int32, error) {
+	return int32((len(text) + 3) / 4), nil
+}
+
+// Ping lists the models, which needs a valid key but costs nothing.
+func (o *OpenAI) Ping(ctx context.Context) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, o.baseURL+"/models", nil)
+	if err != nil {
+		return err
+	}
+	o.authorize(httpReq)
+
+	resp, err := o.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkStatus(resp)
+}
+
+func (o *OpenAI) chatRequest(req Request, stream bool) openAIRequest {
+	var messages []openAIMessage
+	system := o.cfg.Instruction
+	if req.System != "" {
+		system = req.System
+	}
+	if system != "" {
+		messages = append(messages, openAIMessage{Role: "system", Content: system})
+	}
+	for _, t := range req.History {
+		messages = append(messages, toolMessages(t.Results)...)
+		if strings.TrimSpace(t.Text) == "" && len(t.Calls) == 0 {
+			continue
+		}
+		if t.Role != RoleModel {
+			messages = append(messages, openAIMessage{Role: "user", Content: t.Text})
+			continue
+		}
+		msg := openAIMessage{Role: "assistant", Content: t.Text}
+		if len(t.Calls) > 0 {
+			msg.ToolCalls = toOpenAICalls(t.Calls)
+		}
+		messages = append(messages, msg)
+	}
+	messages = append(messages, toolMessages(req.ToolResults)...)
+	if req.Prompt != "" || len(req.Images) > 0 {
+		messages = append(messages, openAIMessage{Role: "user", Content: contentParts(req)})
+	}
+
+	body := openAIRequest{
+		Model:       o.model,
+		Messages:    messages,
+		Temperature: o.cfg.Temperature,
+		TopP:        o.cfg.TopP,
+		MaxTokens:   o.cfg.MaxTokens,
+		Stream:      stream,
+	}
+	if req.MaxTokens > 0 {
+		body.MaxTokens = req.MaxTokens
+	}
+	if req.Schema != nil {
+		body.ResponseFormat = &openAIResponseFormat{
+			Type:       "json_schema",
+			JSONSchema: &openAIJSONSchema{Name: "response", Schema: req.Schema},
+		}
+	}
+	for _, t := range req.Tools {
+		body.Tools = append(body.Tools, openAITool{
+			Type:     "function",
+			Function: openAIFunctionDecl{Name: t.Name, Description: t.Description, Parameters: t.Parameters},
+		})
+	}
+	if len(body.Tools) > 0 && req.NoToolCalls {
+		body.ToolChoice = "none"
+	}
+	if stream {
+		body.StreamOptions = &openAIStreamOptions{IncludeUsage: true}
+	}
+	return body
+}
+
+// toolMessages sends every result as a "tool" message paired with its call.
+func toolMessages(results []ToolResult) []openAIMessage {
+	messages := make([]openAIMessage, 0, len(results))
+	for _, r := range results {
+		content, _ := json.Marshal(r.Response)
+		messages = append(messages, openAIMessage{Role: "tool", Content: string(content), ToolCallID: r.ID})
+	}
+	return messages
+}
+
+func toOpenAICalls(calls []ToolCall) []openAIToolCall {
+	out := make([]openAIToolCall, len(calls))
+	for i, c := range calls {
+		args, _ := json.Marshal(c.Args)
+		out[i].ID = c.ID
+		out[i].Type = "function"
+		out[i].Function.Name = c.Name
+		out[i].Function.Arguments = string(args)
+	}
+	return out
+}
+
+func fromOpenAICalls(calls []openAIToolCall) ([]ToolCall, error) {
+	var out []ToolCall
+	for _, c := range calls {
+		call := ToolCall{ID: c.ID, Name: c.Function.Name}
+		if c.Function.Arguments != "" {
+			if err := json.Unmarshal([]byte(c.Function.Arguments), &call.Args); err != nil {
+				return nil, fmt.Errorf("failed to decode arguments of %s: %w", c.Function.Name, err)
+			}
+		}
+		out = append(out, call)
+	}
+	return out, nil
+}
+
+// contentParts sends plain text when there are no images, which is what
+// most compatible servers without vision support expect.
+func contentParts(req Request) interface{} {
+	if len(req.Images) == 0 {
+		return req.Prompt
+	}
+	parts := make([]openAIContentPart, 0, len(req.Images)+1)
+	for _, img := range req.Images {
+		url := "data:" + imageMIMEType(img) + ";base64," + base64.StdEncoding.EncodeToString(img.Data)
+		parts = append(parts, openAIContentPart{Type: "image_url", ImageURL: &openAIImageURL{URL: url}})
+	}
+	if req.Prompt != "" {
+		parts = append(parts, openAIContentPart{Type: "text", Text: req.Prompt})
+	}
+	return parts
+}
+
+func (o *OpenAI) do(ctx context.Context, body openAIRequest) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	o.authorize(httpReq)
+
+	resp, err := o.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkStatus(resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (o *OpenAI) authorize(req *http.Request) {
+	if o.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+}
+
+func checkStatus(resp *http.Response) error {
+	if resp.StatusCode < 300 {
+		return nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return &StatusError{
+		Code:       resp.StatusCode,
+		Message:    strings.TrimSpace(string(msg)),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+}
+
+func (u *openAIUsage) toUsage() Usage {
+	if u == nil {
+		return Usage{}
+	}
+	return Usage{
+		PromptTokens:    u.PromptTokens,
+		CandidateTokens: u.CompletionTokens,
+		TotalTokens:     u.TotalTokens,
+	}
+}
+
+// openAIFinishReason maps to the same names the Gemini provider reports.
+func openAIFinishReason(reason string) string {
+	switch reason {
+	case "":
+		return ""
+	case "stop":
+		return "stop"
+	case "length":
+		return "max_tokens"
+	case "content_filter":
+		return "safety"
+	default:
+		return "other"
+	}
+}
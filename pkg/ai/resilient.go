@@ -0,0 +1,404 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// StatusError is an HTTP error answered by a provider.
+type StatusError struct {
+	Code    int
+	Message string
+	// RetryAfter is the wait the provider asked for, zero when it did not.
+	RetryAfter time.Duration
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("status %d: %s", e.Code, e.Message)
+}
+
+// UnavailableError is returned by a resilient provider when neither the
+// primary nor the fallback model could answer. RetryAfter says when asking
+// again is worthwhile.
+type UnavailableError struct {
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *UnavailableError) Error() string {
+	return "AI model unavailable: " + e.Err.Error()
+}
+
+func (e *UnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// BreakerState is the state of the circuit breaker of one model.
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half-open"
+)
+
+// ResilienceOptions tunes WithResilience. Zero values take the defaults.
+type ResilienceOptions struct {
+	// Attempts per model, the first call included.
+	Attempts int
+	// BaseDelay is doubled after every retry up to MaxDelay, with jitter.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// Timeout bounds every single call.
+	Timeout time.Duration
+	// FailureThreshold consecutive failed calls open the breaker of a
+	// model for OpenFor; then one probe call decides whether it closes.
+	FailureThreshold int
+	OpenFor          time.Duration
+	// OnStateChange is told about every breaker transition.
+	OnStateChange func(model string, from, to BreakerState)
+}
+
+func (o *ResilienceOptions) defaults() {
+	if o.Attempts <= 0 {
+		o.Attempts = 3
+	}
+	if o.BaseDelay <= 0 {
+		o.BaseDelay = 500 * time.Millisecond
+	}
+	if o.MaxDelay <= 0 {
+		o.MaxDelay = 5 * time.Second
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 60 * time.Second
+	}
+	if o.FailureThreshold <= 0 {
+		o.FailureThreshold = 5
+	}
+	if o.OpenFor <= 0 {
+		o.OpenFor = 30 * time.Second
+	}
+	if o.OnStateChange == nil {
+		o.OnStateChange = func(string, BreakerState, BreakerState) {}
+	}
+}
+
+type resilient struct {
+	primary  *guarded
+	fallback *guarded
+	opts     ResilienceOptions
+}
+
+// WithResilience wraps primary so transient errors (429, 5xx, timeouts) are
+// retried with jittered backoff and every model sits behind a circuit
+// breaker. When primary gives up or its breaker is open, the call goes to
+// fallback, which may be nil. When no model answers, the error is an
+// *UnavailableError. Name, Model, CountTokens and Ping are those of primary;
+// Result.Model names the model that answered.
+func WithResilience(primary, fallback Provider, opts ResilienceOptions) Provider {
+	opts.defaults()
+	changes := make(chan transition, transitionBuffer)
+	go func() {
+		for t := range changes {
+			opts.OnStateChange(t.model, t.from, t.to)
+		}
+	}()
+
+	r := &resilient{
+		primary: &guarded{Provider: primary, breaker: newBreaker(primary.Model(), opts, changes)},
+		opts:    opts,
+	}
+	if fallback != nil {
+		r.fallback = &guarded{Provider: fallback, breaker: newBreaker(fallback.Model(), opts, changes)}
+	}
+	return r
+}
+
+func (r *resilient) Name() string {
+	return r.primary.Name()
+}
+
+func (r *resilient) Model() string {
+	return r.primary.Model()
+}
+
+func (r *resilient) CountTokens(ctx context.Context, text string) (int32, error) {
+	return r.primary.CountTokens(ctx, text)
+}
+
+func (r *resilient) Ping(ctx context.Context) error {
+	return r.primary.Ping(ctx)
+}
+
+func (r *resilient) Generate(ctx context.Context, req Request) (*Result, error) {
+	return r.call(ctx, func(ctx context.Context, p Provider) (*Result, error) {
+		return p.Generate(ctx, req)
+	}, nil)
+}
+
+// Stream is only retried or handed to the fallback while nothing has been
+// sent to onChunk; the patient must not see two answers run into each other.
+func (r *resilient) Stream(ctx context.Context, req Request, onChunk func(string) error) (*Result, error) {
+	sent := false
+	return r.call(ctx, func(ctx context.Context, p Provider) (*Result, error) {
+		return p.Stream(ctx, req, func(chunk string) error {
+			sent = true
+			return onChunk(chunk)
+		})
+	}, func() bool { return sent })
+}
+
+func (r *resilient) call(ctx context.Context, do func(context.Context, Provider) (*Result, error), started func() bool) (*Result, error) {
+	var retryAfter time.Duration
+	var lastErr error
+	for _, g := range []*guarded{r.primary, r.fallback} {
+		if g == nil {
+			continue
+		}
+		res, wait, err := r.try(ctx, g, do, started)
+		if err == nil {
+			return res, nil
+		}
+		if !transient(err) || ctx.Err() != nil || (started != nil && started()) {
+			return nil, err
+		}
+		lastErr, retryAfter = err, maxDuration(retryAfter, wait)
+	}
+	if retryAfter <= 0 {
+		retryAfter = r.opts.OpenFor
+	}
+	return nil, &UnavailableError{RetryAfter: retryAfter, Err: lastErr}
+}
+
+// try calls g up to Attempts times. wait is how long g asked to be left
+// alone when it gave up.
+func (r *resilient) try(ctx context.Context, g *guarded, do func(context.Context, Provider) (*Result, error), started func() bool) (*Result, time.Duration, error) {
+	var err error
+	for attempt := 0; attempt < r.opts.Attempts; attempt++ {
+		if wait, ok := g.breaker.allow(); !ok {
+			if err == nil {
+				err = &StatusError{Code: http.StatusServiceUnavailable, Message: "circuit breaker of " + g.Model() + " is open"}
+			}
+			return nil, wait, err
+		}
+
+		callCtx, cancel := context.WithTimeout(ctx, r.opts.Timeout)
+		var res *Result
+		res, err = do(callCtx, g.Provider)
+		cancel()
+		if err == nil {
+			g.breaker.success()
+			res.Model = g.Model()
+			return res, 0, nil
+		}
+		if ctx.Err() != nil || !transient(err) {
+			// The patient left or the request itself is at fault; neither
+			// says anything about the health of the model.
+			g.breaker.release()
+			return nil, 0, err
+		}
+		g.breaker.failure()
+		if started != nil && started() {
+			return nil, 0, err
+		}
+
+		if attempt+1 < r.opts.Attempts {
+			delay := backoff(r.opts.BaseDelay, r.opts.MaxDelay, attempt)
+			if hint := retryAfterOf(err); hint > delay {
+				if hint > r.opts.MaxDelay {
+					// Not worth keeping the patient waiting; try the
+					// fallback instead.
+					return nil, hint, err
+				}
+				delay = hint
+			}
+			select {
+			case <-ctx.Done():
+				return nil, 0, ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+	}
+	return nil, retryAfterOf(err), err
+}
+
+type guarded struct {
+	Provider
+	breaker *breaker
+}
+
+// breaker opens after threshold consecutive failures. Once openFor has
+// passed it lets a single probe through (half-open): its success closes the
+// breaker, its failure opens it again.
+type breaker struct {
+	mu        sync.Mutex
+	model     string
+	state     BreakerState
+	failures  int
+	threshold int
+	openFor   time.Duration
+	openedAt  time.Time
+	probing   bool
+	changes   chan<- transition
+}
+
+// transitionBuffer transitions may wait for OnStateChange; more are dropped
+// rather than holding up model calls.
+const transitionBuffer = 16
+
+type transition struct {
+	model    string
+	from, to BreakerState
+}
+
+func newBreaker(model string, opts ResilienceOptions, changes chan<- transition) *breaker {
+	return &breaker{
+		model:     model,
+		state:     BreakerClosed,
+		threshold: opts.FailureThreshold,
+		openFor:   opts.OpenFor,
+		changes:   changes,
+	}
+}
+
+// allow reports whether a call may go through, and if not how long until
+// the breaker lets a probe through.
+func (b *breaker) allow() (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		remaining := b.openFor - time.Since(b.openedAt)
+		if remaining > 0 {
+			return remaining, false
+		}
+		b.set(BreakerHalfOpen)
+		b.probing = true
+		return 0, true
+	case BreakerHalfOpen:
+		if b.probing {
+			return b.openFor, false
+		}
+		b.probing = true
+		return 0, true
+	}
+	return 0, true
+}
+
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures, b.probing = 0, false
+	if b.state != BreakerClosed {
+		b.set(BreakerClosed)
+	}
+}
+
+func (b *breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if b.state == BreakerHalfOpen || (b.state == BreakerClosed && b.failures >= b.threshold) {
+		b.openedAt = time.Now()
+		b.set(BreakerOpen)
+	}
+}
+
+// release ends a call that neither failed nor succeeded, e.g. a cancelled
+// probe, so the next call may probe again.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// set must be called with mu held. Transitions are reported in order by
+// the goroutine of WithResilience so a slow notifier never holds up a call.
+func (b *breaker) set(to BreakerState) {
+	t := transition{model: b.model, from: b.state, to: to}
+	b.state = to
+	select {
+	case b.changes <- t:
+	default:
+	}
+}
+
+// transient reports whether err is worth retrying: rate limits, server
+// errors, timeouts and network failures.
+func transient(err error) bool {
+	var status *StatusError
+	if errors.As(err, &status) {
+		return retryableStatus(status.Code)
+	}
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) {
+		return retryableStatus(gerr.Code)
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func retryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || code == http.StatusRequestTimeout || code >= 500
+}
+
+func retryAfterOf(err error) time.Duration {
+	var status *StatusError
+	if errors.As(err, &status) {
+		return status.RetryAfter
+	}
+	var gerr *googleapi.Error
+	if errors.As(err, &gerr) {
+		return parseRetryAfter(gerr.Header.Get("Retry-After"))
+	}
+	return 0
+}
+
+// parseRetryAfter reads the seconds or HTTP date form of Retry-After.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// backoff is the delay before retry number attempt+1: base doubled per
+// attempt, capped at upper, of which a random half is jitter.
+func backoff(base, upper time.Duration, attempt int) time.Duration {
+	d := base << attempt
+	if d <= 0 || d > upper {
+		d = upper
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2)+1))
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
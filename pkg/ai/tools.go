@@ -0,0 +1,24 @@
+package ai
+
+// Tool is a function the model may ask to have called. Parameters is the
+// object schema of its arguments.
+type Tool struct {
+	Name        string
+	Description string
+	Parameters  *Schema
+}
+
+// ToolCall is one call the model asked for. ID pairs it with its result;
+// providers without call ids use the name.
+type ToolCall struct {
+	ID   string
+	Name string
+	Args map[string]interface{}
+}
+
+// ToolResult is the outcome of a ToolCall, sent back to the model.
+type ToolResult struct {
+	ID       string
+	Name     string
+	Response map[string]interface{}
+}
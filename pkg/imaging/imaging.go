@@ -0,0 +1,194 @@
+// Package imaging validates and normalises uploaded photos before they are
+// sent to the model or stored: it rejects non-images and oversized files,
+// downscales, applies the EXIF orientation and re-encodes, which drops all
+// metadata (EXIF, GPS, ICC, XMP).
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+
+	_ "image/gif"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+var (
+	ErrTooLarge    = errors.New("image is too large")
+	ErrNotImage    = errors.New("file is not a supported image")
+	ErrUnsupported = errors.New("HEIC/HEIF images are not supported, please upload JPEG, PNG or WebP")
+)
+
+// Options are the limits applied by Process.
+type Options struct {
+	// MaxBytes rejects larger files before decoding.
+	MaxBytes int64
+	// MaxPixels rejects images whose width*height exceeds it, so a small
+	// file cannot decode into a huge bitmap.
+	MaxPixels int
+	// MaxDimension is the longest side of the result; larger images are
+	// downscaled with their aspect ratio kept.
+	MaxDimension int
+	// JPEGQuality is used when re-encoding to JPEG.
+	JPEGQuality int
+}
+
+// Image is a processed image.
+type Image struct {
+	Data     []byte
+	MIMEType string
+	Width    int
+	Height   int
+}
+
+// Process decodes data, downscales it to opts.MaxDimension and re-encodes
+// it. PNG stays PNG so transparency survives; everything else becomes JPEG.
+func Process(data []byte, opts Options) (*Image, error) {
+	if opts.MaxBytes > 0 && int64(len(data)) > opts.MaxBytes {
+		return nil, fmt.Errorf("%w: %d bytes, limit %d", ErrTooLarge, len(data), opts.MaxBytes)
+	}
+
+	format := sniff(data)
+	switch format {
+	case "":
+		return nil, ErrNotImage
+	case "heic":
+		return nil, ErrUnsupported
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotImage, err)
+	}
+	if opts.MaxPixels > 0 && cfg.Width*cfg.Height > opts.MaxPixels {
+		return nil, fmt.Errorf("%w: %dx%d pixels", ErrTooLarge, cfg.Width, cfg.Height)
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotImage, err)
+	}
+	// Downscaling first leaves orient far fewer pixels to move; both
+	// bounds are capped alike, so the result is the same.
+	img = downscale(img, opts.MaxDimension)
+	if format == "jpeg" {
+		img = orient(img, exifOrientation(data))
+	}
+
+	var (
+		buf      bytes.Buffer
+		mimeType string
+	)
+	if format == "png" {
+		mimeType = "image/png"
+		err = png.Encode(&buf, img)
+	} else {
+		mimeType = "image/jpeg"
+		err = jpeg.Encode(&buf, flatten(img), &jpeg.Options{Quality: quality(opts.JPEGQuality)})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+
+	b := img.Bounds()
+	return &Image{
+		Data:     buf.Bytes(),
+		MIMEType: mimeType,
+		Width:    b.Dx(),
+		Height:   b.Dy(),
+	}, nil
+}
+
+// JPEG returns a processed image as JPEG, putting a PNG on white; JPEGs are
+// returned as they are.
+func JPEG(data []byte, jpegQuality int) ([]byte, error) {
+	if sniff(data) == "jpeg" {
+		return data, nil
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotImage, err)
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, flatten(img), &jpeg.Options{Quality: quality(jpegQuality)}); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Extension is the file extension matching a MIME type returned by Process.
+func Extension(mimeType string) string {
+	if mimeType == "image/png" {
+		return ".png"
+	}
+	return ".jpg"
+}
+
+// sniff identifies the format from its magic bytes. The upload's
+// Content-Type header and file name are not trusted.
+func sniff(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xD8, 0xFF}):
+		return "jpeg"
+	case bytes.HasPrefix(data, []byte("\x89PNG\r\n\x1a\n")):
+		return "png"
+	case bytes.HasPrefix(data, []byte("GIF87a")), bytes.HasPrefix(data, []byte("GIF89a")):
+		return "gif"
+	case len(data) >= 12 && string(data[:4]) == "RIFF" && string(data[8:12]) == "WEBP":
+		return "webp"
+	case len(data) >= 12 && string(data[4:8]) == "ftyp" && isHEICBrand(string(data[8:12])):
+		return "heic"
+	}
+	return ""
+}
+
+func isHEICBrand(brand string) bool {
+	switch brand {
+	case "heic", "heix", "hevc", "hevx", "heim", "heis", "mif1", "msf1", "avif":
+		return true
+	}
+	return false
+}
+
+func downscale(img image.Image, maxDimension int) image.Image {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if maxDimension <= 0 || (w <= maxDimension && h <= maxDimension) {
+		return img
+	}
+	if w >= h {
+		h = max(1, h*maxDimension/w)
+		w = maxDimension
+	} else {
+		w = max(1, w*maxDimension/h)
+		h = maxDimension
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, b, draw.Src, nil)
+	return dst
+}
+
+// flatten puts the image on white, JPEG has no alpha channel.
+func flatten(img image.Image) image.Image {
+	if o, ok := img.(interface{ Opaque() bool }); ok && o.Opaque() {
+		return img
+	}
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Over)
+	return dst
+}
+
+func quality(q int) int {
+	if q <= 0 || q > 100 {
+		return jpeg.DefaultQuality
+	}
+	return q
+}
@@ -1,6 +1,8 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"os"
 	"time"
@@ -26,28 +28,49 @@ type Claims struct {
 	UserID  int
 	GuestID string
 	Role    string // "user", "guest", "doctor"
+	// ID (jti) names the token so it can be revoked on its own. SessionID
+	// (sid) is the refresh token family the token was issued for.
+	ID        string
+	SessionID string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
 }
 
 // ── Token Creation ──
 
-// CreateToken creates a JWT for an authenticated user (24h TTL)
-func CreateToken(userID int, role string) (string, error) {
+// CreateToken creates a JWT access token for an authenticated user, valid
+// for ttl. Longer sessions are kept alive with the refresh tokens of the
+// family sessionID.
+func CreateToken(userID int, role, sessionID string, ttl time.Duration) (string, error) {
 	if role == "" {
 		role = "user"
 	}
+	jti, err := newID()
+	if err != nil {
+		return "", err
+	}
 	claims := jwt.MapClaims{
+		"jti":     jti,
 		"user_id": userID,
 		"role":    role,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
+		"exp":     time.Now().Add(ttl).Unix(),
 		"iat":     time.Now().Unix(),
 	}
+	if sessionID != "" {
+		claims["sid"] = sessionID
+	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(jwtSecret)
 }
 
 // CreateGuestToken creates a short-lived JWT for guest users (2h TTL)
 func CreateGuestToken(guestID string) (string, error) {
+	jti, err := newID()
+	if err != nil {
+		return "", err
+	}
 	claims := jwt.MapClaims{
+		"jti":      jti,
 		"guest_id": guestID,
 		"role":     "guest",
 		"exp":      time.Now().Add(2 * time.Hour).Unix(),
@@ -57,6 +80,14 @@ func CreateGuestToken(guestID string) (string, error) {
 	return token.SignedString(jwtSecret)
 }
 
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.New("could not generate token id")
+	}
+	return hex.EncodeToString(b), nil
+}
+
 // ── Token Verification ──
 
 func VerifyToken(tokenStr string) (*Claims, error) {
@@ -93,6 +124,18 @@ func VerifyToken(tokenStr string) (*Claims, error) {
 	if guestID, ok := mapClaims["guest_id"].(string); ok {
 		claims.GuestID = guestID
 	}
+	if jti, ok := mapClaims["jti"].(string); ok {
+		claims.ID = jti
+	}
+	if sid, ok := mapClaims["sid"].(string); ok {
+		claims.SessionID = sid
+	}
+	if iat, ok := mapClaims["iat"].(float64); ok {
+		claims.IssuedAt = time.Unix(int64(iat), 0)
+	}
+	if exp, ok := mapClaims["exp"].(float64); ok {
+		claims.ExpiresAt = time.Unix(int64(exp), 0)
+	}
 
 	// Backward compat: old tokens used "sub" for user id
 	if sub, ok := mapClaims["sub"].(float64); ok && claims.UserID == 0 {
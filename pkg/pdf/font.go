@@ -0,0 +1,185 @@
+package pdf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/gofont/goregular"
+	"golang.org/x/image/font/sfnt"
+	"golang.org/x/image/math/fixed"
+)
+
+// substitutes are drawn in place of characters the Go fonts lack. Uzbek
+// Latin is often typed with the modifier letters ʻ and ʼ (oʻ, gʻ).
+var substitutes = map[rune]rune{
+	'ʻ':  '‘',
+	'ʼ':  '’',
+	'\t': ' ',
+}
+
+// ttf is a TrueType font embedded whole, addressed by glyph index
+// (Identity-H), so any script the font covers can be written.
+type ttf struct {
+	name  string
+	data  []byte
+	font  *sfnt.Font
+	buf   sfnt.Buffer
+	upem  fixed.Int26_6
+	units float64
+
+	glyphs map[rune]sfnt.GlyphIndex
+	widths map[sfnt.GlyphIndex]float64
+	// used maps the glyphs written to the text they stand for, for the
+	// ToUnicode map that makes the text searchable and copyable.
+	used map[sfnt.GlyphIndex]rune
+}
+
+func newRegular() (*ttf, error) { return parseFont("GoRegular", goregular.TTF) }
+func newBold() (*ttf, error)    { return parseFont("GoBold", gobold.TTF) }
+
+func parseFont(name string, data []byte) (*ttf, error) {
+	f, err := sfnt.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse font %s: %w", name, err)
+	}
+	units := float64(f.UnitsPerEm())
+	return &ttf{
+		name:   name,
+		data:   data,
+		font:   f,
+		upem:   fixed.I(int(f.UnitsPerEm())),
+		units:  units,
+		glyphs: make(map[rune]sfnt.GlyphIndex),
+		widths: make(map[sfnt.GlyphIndex]float64),
+		used:   make(map[sfnt.GlyphIndex]rune),
+	}, nil
+}
+
+func (t *ttf) glyph(r rune) sfnt.GlyphIndex {
+	if g, ok := t.glyphs[r]; ok {
+		return g
+	}
+	lookup := r
+	if s, ok := substitutes[r]; ok {
+		lookup = s
+	}
+	g, err := t.font.GlyphIndex(&t.buf, lookup)
+	if err != nil || g == 0 {
+		g, _ = t.font.GlyphIndex(&t.buf, '?')
+	}
+	t.glyphs[r] = g
+	return g
+}
+
+// width is the advance of g in thousandths of the font size.
+func (t *ttf) width(g sfnt.GlyphIndex) float64 {
+	if w, ok := t.widths[g]; ok {
+		return w
+	}
+	adv, err := t.font.GlyphAdvance(&t.buf, g, t.upem, font.HintingNone)
+	w := 0.0
+	if err == nil {
+		w = float64(adv) / 64 * 1000 / t.units
+	}
+	t.widths[g] = w
+	return w
+}
+
+// measure returns the width of s in points at size.
+func (t *ttf) measure(s string, size float64) float64 {
+	total := 0.0
+	for _, r := range s {
+		total += t.width(t.glyph(r))
+	}
+	return total * size / 1000
+}
+
+// encode returns s as a hex string of glyph indexes and remembers them.
+func (t *ttf) encode(s string) string {
+	var b strings.Builder
+	b.WriteByte('<')
+	for _, r := range s {
+		g := t.glyph(r)
+		if _, ok := t.used[g]; !ok {
+			t.used[g] = r
+		}
+		fmt.Fprintf(&b, "%04X", uint16(g))
+	}
+	b.WriteByte('>')
+	return b.String()
+}
+
+func (t *ttf) metrics() (ascent, descent float64, bbox [4]float64) {
+	scale := 1000 / t.units
+	if m, err := t.font.Metrics(&t.buf, t.upem, font.HintingNone); err == nil {
+		ascent = float64(m.Ascent) / 64 * scale
+		descent = -float64(m.Descent) / 64 * scale
+	}
+	if b, err := t.font.Bounds(&t.buf, t.upem, font.HintingNone); err == nil {
+		// sfnt bounds grow downwards, PDF ones upwards.
+		bbox = [4]float64{
+			float64(b.Min.X) / 64 * scale,
+			-float64(b.Max.Y) / 64 * scale,
+			float64(b.Max.X) / 64 * scale,
+			-float64(b.Min.Y) / 64 * scale,
+		}
+	}
+	return ascent, descent, bbox
+}
+
+// usedGlyphs lists the written glyphs in index order.
+func (t *ttf) usedGlyphs() []sfnt.GlyphIndex {
+	glyphs := make([]sfnt.GlyphIndex, 0, len(t.used))
+	for g := range t.used {
+		glyphs = append(glyphs, g)
+	}
+	sort.Slice(glyphs, func(i, j int) bool { return glyphs[i] < glyphs[j] })
+	return glyphs
+}
+
+// widthArray is the W entry of the CIDFont for the written glyphs.
+func (t *ttf) widthArray() string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for _, g := range t.usedGlyphs() {
+		fmt.Fprintf(&b, "%d [%.0f] ", g, t.width(g))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+// toUnicode is the CMap from the written glyphs back to their text.
+func (t *ttf) toUnicode() string {
+	glyphs := t.usedGlyphs()
+	var b strings.Builder
+	b.WriteString("/CIDInit /ProcSet findresource begin\n12 dict begin\nbegincmap\n" +
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n" +
+		"/CMapName /Adobe-Identity-UCS def\n/CMapType 2 def\n" +
+		"1 begincodespacerange\n<0000> <FFFF>\nendcodespacerange\n")
+	for len(glyphs) > 0 {
+		n := min(len(glyphs), 100)
+		fmt.Fprintf(&b, "%d beginbfchar\n", n)
+		for _, g := range glyphs[:n] {
+			fmt.Fprintf(&b, "<%04X> <", uint16(g))
+			for _, u := range utf16(t.used[g]) {
+				fmt.Fprintf(&b, "%04X", u)
+			}
+			b.WriteString(">\n")
+		}
+		b.WriteString("endbfchar\n")
+		glyphs = glyphs[n:]
+	}
+	b.WriteString("endcmap\nCMapName currentdict /CMap defineresource pop\nend\nend\n")
+	return b.String()
+}
+
+func utf16(r rune) []uint16 {
+	if r < 0x10000 {
+		return []uint16{uint16(r)}
+	}
+	r -= 0x10000
+	return []uint16{uint16(0xD800 + (r >> 10)), uint16(0xDC00 + (r & 0x3FF))}
+}
@@ -0,0 +1,365 @@
+// Package pdf writes simple A4 documents — headings, wrapped text, label and
+// value rows and JPEG photos — in pure Go, without cgo or system fonts. Text
+// is set in the Go fonts, which cover Latin and Cyrillic, so Uzbek and
+// Russian print as written.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/jpeg"
+	"strings"
+	"time"
+)
+
+const (
+	pageWidth  = 595.28
+	pageHeight = 841.89
+	margin     = 50.0
+	// footerSpace is kept free at the bottom of every page for the footer.
+	footerSpace = 20.0
+	labelWidth  = 130.0
+	lineSpacing = 1.35
+
+	sizeTitle   = 18.0
+	sizeHeading = 13.0
+	sizeText    = 10.5
+	sizeNote    = 8.5
+)
+
+var ErrNotJPEG = errors.New("pdf: only JPEG images can be embedded")
+
+// Document is a PDF being laid out top to bottom; pages are added as the
+// content needs them. The zero value is not usable, see New.
+type Document struct {
+	title   string
+	footer  string
+	regular *ttf
+	bold    *ttf
+	pages   []*bytes.Buffer
+	// y is the top of the free space on the current page, in PDF
+	// coordinates (from the bottom).
+	y      float64
+	images []*jpegImage
+}
+
+type jpegImage struct {
+	data          []byte
+	width, height int
+	colorSpace    string
+}
+
+// New starts a document whose metadata title is title.
+func New(title string) (*Document, error) {
+	regular, err := newRegular()
+	if err != nil {
+		return nil, err
+	}
+	bold, err := newBold()
+	if err != nil {
+		return nil, err
+	}
+	d := &Document{title: title, regular: regular, bold: bold}
+	d.newPage()
+	return d, nil
+}
+
+// SetFooter prints text with the page number at the bottom of every page.
+func (d *Document) SetFooter(text string) {
+	d.footer = text
+}
+
+func (d *Document) Title(text string) {
+	d.paragraph(d.bold, sizeTitle, 0, text)
+	d.Space(6)
+}
+
+func (d *Document) Heading(text string) {
+	d.Space(10)
+	// Keep a heading with at least two lines of what follows.
+	d.ensure(sizeHeading*lineSpacing + 2*sizeText*lineSpacing)
+	d.paragraph(d.bold, sizeHeading, 0, text)
+	d.Space(2)
+}
+
+// Text writes wrapped body text; newlines start new lines.
+func (d *Document) Text(text string) {
+	d.paragraph(d.regular, sizeText, 0, text)
+}
+
+// Note writes small grey text, for timestamps and other details.
+func (d *Document) Note(text string) {
+	d.paragraph(d.regular, sizeNote, 0.4, text)
+}
+
+// Field writes a bold label with its value wrapped beside it.
+func (d *Document) Field(label, value string) {
+	lines := d.wrap(d.regular, sizeText, pageWidth-2*margin-labelWidth, value)
+	lead := sizeText * lineSpacing
+	for i, line := range lines {
+		d.ensure(lead)
+		d.y -= lead
+		if i == 0 {
+			d.show(d.bold, sizeText, 0, margin, d.y+lead-sizeText, label)
+		}
+		d.show(d.regular, sizeText, 0, margin+labelWidth, d.y+lead-sizeText, line)
+	}
+}
+
+// Space leaves h points empty.
+func (d *Document) Space(h float64) {
+	if d.y-h < margin+footerSpace {
+		d.newPage()
+		return
+	}
+	d.y -= h
+}
+
+// Image embeds a JPEG scaled to the text width and at most maxHeight
+// points high, starting a new page when it does not fit on this one.
+func (d *Document) Image(data []byte, maxHeight float64) error {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || format != "jpeg" {
+		return ErrNotJPEG
+	}
+	img := &jpegImage{data: data, width: cfg.Width, height: cfg.Height, colorSpace: "/DeviceRGB"}
+	switch cfg.ColorModel {
+	case color.GrayModel:
+		img.colorSpace = "/DeviceGray"
+	case color.CMYKModel:
+		img.colorSpace = "/DeviceCMYK"
+	}
+	d.images = append(d.images, img)
+
+	maxWidth := pageWidth - 2*margin
+	maxHeight = min(maxHeight, pageHeight-2*margin-footerSpace)
+	scale := min(maxWidth/float64(cfg.Width), maxHeight/float64(cfg.Height), 1)
+	w, h := float64(cfg.Width)*scale, float64(cfg.Height)*scale
+
+	d.ensure(h)
+	d.y -= h
+	fmt.Fprintf(d.page(), "q %.2f 0 0 %.2f %.2f %.2f cm /Im%d Do Q\n", w, h, margin, d.y, len(d.images))
+	d.Space(6)
+	return nil
+}
+
+func (d *Document) page() *bytes.Buffer {
+	return d.pages[len(d.pages)-1]
+}
+
+func (d *Document) newPage() {
+	d.pages = append(d.pages, new(bytes.Buffer))
+	d.y = pageHeight - margin
+}
+
+// ensure starts a new page unless h points fit on this one.
+func (d *Document) ensure(h float64) {
+	if d.y-h < margin+footerSpace {
+		d.newPage()
+	}
+}
+
+func (d *Document) paragraph(f *ttf, size, gray float64, text string) {
+	lead := size * lineSpacing
+	for _, line := range d.wrap(f, size, pageWidth-2*margin, text) {
+		d.ensure(lead)
+		d.y -= lead
+		d.show(f, size, gray, margin, d.y+lead-size, line)
+	}
+}
+
+func (d *Document) show(f *ttf, size, gray, x, y float64, text string) {
+	if text == "" {
+		return
+	}
+	fmt.Fprintf(d.page(), "BT %.2f g /%s %.1f Tf %.2f %.2f Td %s Tj ET\n", gray, f.name, size, x, y, f.encode(text))
+}
+
+// wrap breaks text into lines no wider than width, at spaces where it can
+// and inside words that are longer than a line.
+func (d *Document) wrap(f *ttf, size, width float64, text string) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		var line string
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if f.measure(candidate, size) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			for f.measure(word, size) > width {
+				head, tail := split(f, size, width, word)
+				lines = append(lines, head)
+				word = tail
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// split cuts the longest prefix of word that fits width, at least one rune.
+func split(f *ttf, size, width float64, word string) (string, string) {
+	runes := []rune(word)
+	n := 1
+	for n < len(runes) && f.measure(string(runes[:n+1]), size) <= width {
+		n++
+	}
+	return string(runes[:n]), string(runes[n:])
+}
+
+// Bytes finishes the document. It must be called once, after all content
+// has been added.
+func (d *Document) Bytes() ([]byte, error) {
+	if d.footer != "" || len(d.pages) > 1 {
+		for i := range d.pages {
+			text := fmt.Sprintf("%d / %d", i+1, len(d.pages))
+			if d.footer != "" {
+				text += "   ·   " + d.footer
+			}
+			// The footer is a single line; anything longer is cut.
+			line := d.wrap(d.regular, sizeNote, pageWidth-2*margin, text)[0]
+			fmt.Fprintf(d.pages[i], "BT 0.40 g /%s %.1f Tf %.2f %.2f Td %s Tj ET\n",
+				d.regular.name, sizeNote, margin, margin, d.regular.encode(line))
+		}
+	}
+
+	w := &writer{}
+	catalog, pages, info := w.alloc(), w.alloc(), w.alloc()
+	regular, bold := w.alloc(), w.alloc()
+	images := make([]int, len(d.images))
+	for i, img := range d.images {
+		images[i] = w.alloc()
+		w.stream(images[i], fmt.Sprintf("/Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace %s /BitsPerComponent 8 /Filter /DCTDecode",
+			img.width, img.height, img.colorSpace), img.data)
+	}
+
+	var xobjects strings.Builder
+	for i, n := range images {
+		fmt.Fprintf(&xobjects, "/Im%d %d 0 R ", i+1, n)
+	}
+	resources := w.alloc()
+	w.object(resources, fmt.Sprintf("<< /Font << /%s %d 0 R /%s %d 0 R >> /XObject << %s>> >>",
+		d.regular.name, regular, d.bold.name, bold, xobjects.String()))
+
+	kids := make([]string, len(d.pages))
+	for i, content := range d.pages {
+		page, stream := w.alloc(), w.alloc()
+		kids[i] = fmt.Sprintf("%d 0 R", page)
+		w.object(page, fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %.2f %.2f] /Resources %d 0 R /Contents %d 0 R >>",
+			pages, pageWidth, pageHeight, resources, stream))
+		if err := w.deflated(stream, "", content.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	w.object(pages, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	w.object(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pages))
+	w.object(info, fmt.Sprintf("<< /Title %s /Producer (SkinAI) /CreationDate (D:%s) >>",
+		textString(d.title), time.Now().UTC().Format("20060102150405Z")))
+
+	if err := d.writeFonts(w, regular, bold); err != nil {
+		return nil, err
+	}
+	return w.finish(catalog, info), nil
+}
+
+func (d *Document) writeFonts(w *writer, regular, bold int) error {
+	for _, f := range []struct {
+		font *ttf
+		id   int
+	}{{d.regular, regular}, {d.bold, bold}} {
+		cid, descriptor, file, cmap := w.alloc(), w.alloc(), w.alloc(), w.alloc()
+		w.object(f.id, fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+			f.font.name, cid, cmap))
+		w.object(cid, fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s "+
+			"/CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> "+
+			"/FontDescriptor %d 0 R /CIDToGIDMap /Identity /DW 1000 /W %s >>",
+			f.font.name, descriptor, f.font.widthArray()))
+		ascent, descent, bbox := f.font.metrics()
+		w.object(descriptor, fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 32 /FontBBox [%.0f %.0f %.0f %.0f] "+
+			"/ItalicAngle 0 /Ascent %.0f /Descent %.0f /CapHeight %.0f /StemV 80 /FontFile2 %d 0 R >>",
+			f.font.name, bbox[0], bbox[1], bbox[2], bbox[3], ascent, descent, ascent, file))
+		if err := w.deflated(file, fmt.Sprintf("/Length1 %d", len(f.font.data)), f.font.data); err != nil {
+			return err
+		}
+		if err := w.deflated(cmap, "", []byte(f.font.toUnicode())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writer lays out numbered objects and the cross-reference table.
+type writer struct {
+	buf     bytes.Buffer
+	offsets map[int]int
+	last    int
+}
+
+func (w *writer) alloc() int {
+	if w.offsets == nil {
+		w.offsets = make(map[int]int)
+		w.buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	}
+	w.last++
+	return w.last
+}
+
+func (w *writer) object(n int, body string) {
+	w.offsets[n] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", n, body)
+}
+
+func (w *writer) stream(n int, dict string, data []byte) {
+	w.offsets[n] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n<< %s /Length %d >>\nstream\n", n, dict, len(data))
+	w.buf.Write(data)
+	w.buf.WriteString("\nendstream\nendobj\n")
+}
+
+func (w *writer) deflated(n int, dict string, data []byte) error {
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	if _, err := zw.Write(data); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	w.stream(n, strings.TrimSpace(dict+" /Filter /FlateDecode"), z.Bytes())
+	return nil
+}
+
+func (w *writer) finish(root, info int) []byte {
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", w.last+1)
+	for n := 1; n <= w.last; n++ {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", w.offsets[n])
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %d 0 R /Info %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", w.last+1, root, info, xref)
+	return w.buf.Bytes()
+}
+
+// textString encodes s as a UTF-16 PDF string, for the document info.
+func textString(s string) string {
+	var b strings.Builder
+	b.WriteString("<FEFF")
+	for _, r := range s {
+		for _, u := range utf16(r) {
+			fmt.Fprintf(&b, "%04X", u)
+		}
+	}
+	b.WriteByte('>')
+	return b.String()
+}
@@ -1,10 +1,14 @@
 package sms
 
 import (
-	"math/rand"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"log"
+	"math/big"
 	"net/smtp"
 	"strconv"
-	"time"
+	"strings"
 )
 
 const smtpServer = "smtp.gmail.com"
@@ -14,25 +18,101 @@ const smtpPassword = "wuyk nvub juvi ojzk"
 const subject = "Curify Verification "
 const body = "Your verification code is: "
 
-func GenerateVerificationCode() string {
-	rand.Seed(time.Now().UnixNano())
-	return strconv.Itoa(rand.Intn(9999))
+const (
+	MailerSMTP = "smtp"
+	MailerLog  = "log"
+)
+
+// codeDigits is the length of a verification code.
+const codeDigits = 6
+
+// Mailer sends verification codes by email.
+type Mailer interface {
+	SendCode(ctx context.Context, to, code string) error
+}
+
+// MailConfig selects the mailer. The SMTP settings left empty are those of
+// the Curify account.
+type MailConfig struct {
+	Provider string
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// NewMailer returns the mailer of cfg.Provider: smtp, or log, which only
+// writes the codes to the log for development.
+func NewMailer(cfg MailConfig) (Mailer, error) {
+	switch strings.ToLower(cfg.Provider) {
+	case "", MailerSMTP:
+		return NewSMTPMailer(cfg), nil
+	case MailerLog:
+		return logMailer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown mail provider %q", cfg.Provider)
+	}
 }
 
+// GenerateVerificationCode returns a random code of codeDigits digits,
+// leading zeros included.
+func GenerateVerificationCode() (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < codeDigits; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", codeDigits, n), nil
+}
+
+// SendEmail sends code to to from the Curify account.
 func SendEmail(to, code string) error {
-	from := smtpUsername
-	password := smtpPassword
+	return NewSMTPMailer(MailConfig{}).SendCode(context.Background(), to, code)
+}
 
-	auth := smtp.PlainAuth("", from, password, smtpServer)
+type smtpMailer struct {
+	cfg MailConfig
+}
 
-	message := []byte("Subject: " + subject + "\r\n" +
-		"\r\n" +
-		body + code)
+func NewSMTPMailer(cfg MailConfig) Mailer {
+	if cfg.Host == "" {
+		cfg.Host = smtpServer
+	}
+	if cfg.Port == 0 {
+		cfg.Port = smtpPort
+	}
+	if cfg.Username == "" {
+		cfg.Username, cfg.Password = smtpUsername, smtpPassword
+	}
+	if cfg.From == "" {
+		cfg.From = cfg.Username
+	}
+	return &smtpMailer{cfg: cfg}
+}
 
-	err := smtp.SendMail(smtpServer+":"+strconv.Itoa(smtpPort), auth, from, []string{to}, message)
-	if err != nil {
+// SendCode sends the mail with net/smtp, which cannot be cancelled once
+// started; ctx is only checked before.
+func (m *smtpMailer) SendCode(ctx context.Context, to, code string) error {
+	if err := ctx.Err(); err != nil {
 		return err
 	}
+	auth := smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+
+	message := []byte("From: " + m.cfg.From + "\r\n" +
+		"Subject: " + subject + "\r\n" +
+		"\r\n" +
+		body + code)
+
+	return smtp.SendMail(m.cfg.Host+":"+strconv.Itoa(m.cfg.Port), auth, m.cfg.From, []string{to}, message)
+}
+
+type logMailer struct{}
 
+func (logMailer) SendCode(ctx context.Context, to, code string) error {
+	log.Printf("verification code for %s: %s", to, code)
 	return nil
 }